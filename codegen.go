@@ -2,11 +2,18 @@ package main
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 )
 
 var argreg1 = []string{"dil", "sil", "dl", "cl", "r8b", "r9b"}
+var argreg4 = []string{"edi", "esi", "edx", "ecx", "r8d", "r9d"}
 var argreg8 = []string{"rdi", "rsi", "rdx", "rcx", "r8", "r9"}
 var funcname string
+var retOffset uint   // Offset of the pointer to the result of a function returning an aggregate
+var frameSize uint   // Size of the frame of the current function
+var namedResult *Var // The named result of the current function
 var label int
 
 func seq() int {
@@ -17,7 +24,9 @@ func seq() int {
 func genAddr(node *Node) {
 	switch node.Kind {
 	case ND_VAR:
-		if node.Var.IsLocal {
+		if node.Var.OnHeap {
+			fmt.Printf("  push qword ptr [rbp-%d]\n", node.Var.Offset)
+		} else if node.Var.IsLocal {
 			fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
 			fmt.Printf("  push rax\n")
 		} else {
@@ -25,24 +34,67 @@ func genAddr(node *Node) {
 		}
 	case ND_DEREF:
 		gen(node.Lhs)
+		genNilCheck("qword ptr [rsp]")
+	case ND_COMPLIT:
+		gen(node)
 	case ND_INDEX:
-		genAddr(node.Lhs)
+		// The element of a map is inserted if the key is not in it.
+		if node.Lhs.Type.isMap() {
+			gen(node.Lhs)
+			genMapKey(node.Rhs, node.Var)
+			fmt.Printf("  pop rsi\n")
+			fmt.Printf("  pop rdi\n")
+			call("runtime.mapassign")
+			fmt.Printf("  push rax\n")
+			return
+		}
+
+		// The index is checked against the length of the array or
+		// the slice.
+		gen(node.Lhs)
 		gen(node.Rhs)
 		fmt.Printf("  pop rdi\n")
 		fmt.Printf("  pop rax\n")
-		fmt.Printf("  imul rdi, %d\n", node.Lhs.Type.Ref.size())
+		if arr := node.Lhs.Type.arrayType(); arr != nil {
+			fmt.Printf("  mov rsi, %d\n", arr.ArrayLen)
+		} else {
+			fmt.Printf("  mov rsi, [rax+8]\n")
+			fmt.Printf("  mov rax, [rax]\n")
+		}
+		s := seq()
+		fmt.Printf("  cmp rdi, rsi\n")
+		fmt.Printf("  jb .L.inbounds.%d\n", s)
+		fmt.Printf("  mov rdx, rsi\n")
+		fmt.Printf("  mov rsi, rdi\n")
+		fmt.Printf("  mov rdi, offset %s\n", cstring("runtime error: index out of range [%ld] with length %ld"))
+		call("runtime.panicf")
+		fmt.Printf(".L.inbounds.%d:\n", s)
+		fmt.Printf("  imul rdi, %d\n", node.Type.size())
 		fmt.Printf("  add rax, rdi\n")
 		fmt.Printf("  push rax\n")
+	case ND_MEMBER:
+		gen(node.Lhs)
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  add rax, %d\n", node.Member.Offset)
+		fmt.Printf("  push rax\n")
 	default:
 		panic("Not valiable")
 	}
 }
 
 func load(ty *Type) {
+	// An aggregate is not loaded into a register; its address stands for
+	// its value.
+	if ty.isAggregate() {
+		return
+	}
 	fmt.Printf("  pop rax\n")
-	if ty.size() == 1 {
-		fmt.Printf("  movsx rax, byte ptr [rax]\n")
-	} else {
+	switch ty.size() {
+	case 1:
+		fmt.Printf("  movzx rax, byte ptr [rax]\n")
+	case 4:
+		fmt.Printf("  movsxd rax, dword ptr [rax]\n")
+	default:
 		fmt.Printf("  mov rax, [rax]\n")
 	}
 	fmt.Printf("  push rax\n")
@@ -51,57 +103,541 @@ func load(ty *Type) {
 func store(ty *Type) {
 	fmt.Printf("  pop rdi\n")
 	fmt.Printf("  pop rax\n")
-	if ty.size() == 1 {
+	if ty.isAggregate() {
+		fmt.Printf("  mov rsi, rdi\n")
+		fmt.Printf("  mov rdi, rax\n")
+		fmt.Printf("  mov rcx, %d\n", ty.size())
+		fmt.Printf("  rep movsb\n")
+		fmt.Printf("  push rax\n")
+		return
+	}
+	switch ty.size() {
+	case 1:
 		fmt.Printf("  mov [rax], dil\n")
-	} else {
+	case 4:
+		fmt.Printf("  mov [rax], edi\n")
+	default:
 		fmt.Printf("  mov [rax], rdi\n")
 	}
 	fmt.Printf("  push rdi\n")
 }
 
 func codegen(code []*Node) {
+	if funcs["main.main"] == nil {
+		panic("function main is undeclared in the main package")
+	}
 	fmt.Printf(".intel_syntax noprefix\n")
+	emitText(append(code, initFunc))
 	emitData(code)
-	emitText(code)
+	emitWrappers()
+	emitRuntime()
+}
+
+var cstrings []string
+var cstringLabels = make(map[string]string)
+
+// cstring returns the label of a NUL-terminated string in the data section,
+// which is emitted once however many times it is used.
+func cstring(s string) string {
+	if l, ok := cstringLabels[s]; ok {
+		return l
+	}
+	cstrings = append(cstrings, s)
+	l := fmt.Sprintf(".L.cstr.%d", len(cstrings)-1)
+	cstringLabels[s] = l
+	return l
+}
+
+var ifaceTables []*Type
+
+// ifaceTable returns the label of the table mapping the type descriptors to
+// the itables of the interface type iface, which is looked up at run time
+// to convert an interface value to iface.
+func ifaceTable(iface *Type) string {
+	for i, t := range ifaceTables {
+		if identical(t, iface) {
+			return fmt.Sprintf(".L.itabs.%d", i)
+		}
+	}
+	ifaceTables = append(ifaceTables, iface)
+	return fmt.Sprintf(".L.itabs.%d", len(ifaceTables)-1)
+}
+
+var closures []string
+
+// closureLabel returns the label of the static closure of the function fn.
+func closureLabel(fn string) string {
+	for _, f := range closures {
+		if f == fn {
+			return ".L.closure." + fn
+		}
+	}
+	closures = append(closures, fn)
+	return ".L.closure." + fn
+}
+
+var mapDescs []*Type
+var zeroSize uint // Size of the largest zero value of a map element read
+
+// mapDesc returns the label of the descriptor of the map type ty, which
+// tells the runtime the layout of the entries of its table. An entry is a
+// word of its state followed by the key and the element, both aligned to 8
+// bytes. The keys are hashed and compared by the spans listed in it.
+func mapDesc(ty *Type) string {
+	for i, t := range mapDescs {
+		if identical(t.Key, ty.Key) && t.Ref.size() == ty.Ref.size() {
+			return fmt.Sprintf(".L.map.%d", i)
+		}
+	}
+	mapDescs = append(mapDescs, ty)
+	return fmt.Sprintf(".L.map.%d", len(mapDescs)-1)
+}
+
+// A gcLayout tells the collector the offsets of the words which may hold
+// pointers in an element of the size. An object on the heap is an array of
+// such elements.
+type gcLayout struct {
+	Size uint
+	Offs []uint
+}
+
+var gcLayouts []gcLayout
+
+// gcDesc returns the label of the descriptor of the layout of an element of
+// size bytes whose pointers are at the offsets offs, or 0 if it has none.
+func gcDesc(size uint, offs []uint) string {
+	if len(offs) == 0 {
+		return "0"
+	}
+	for i, l := range gcLayouts {
+		if l.Size == size && fmt.Sprint(l.Offs) == fmt.Sprint(offs) {
+			return fmt.Sprintf(".L.gc.%d", i)
+		}
+	}
+	gcLayouts = append(gcLayouts, gcLayout{size, offs})
+	return fmt.Sprintf(".L.gc.%d", len(gcLayouts)-1)
+}
+
+// typeGCDesc returns the label of the descriptor of the layout of ty.
+func typeGCDesc(ty *Type) string {
+	return gcDesc(ty.size(), pointerWords(nil, ty, 0))
+}
+
+// pointerWords appends the offsets of the words of a value of type ty at
+// the offset off which may hold pointers. The data word of an interface
+// value may hold a scalar, which the collector tells from a pointer by
+// looking it up on the heap.
+func pointerWords(offs []uint, ty *Type, off uint) []uint {
+	switch ty.Kind {
+	case TY_POINTER, TY_MAP, TY_SLICE, TY_STRING, TY_FUNC, TY_CHAN:
+		return append(offs, off)
+	case TY_INTERFACE:
+		return append(offs, off+8)
+	case TY_ARRAY:
+		for i := uint(0); i < ty.ArrayLen; i++ {
+			offs = pointerWords(offs, ty.Ref, off+i*ty.Ref.size())
+		}
+	case TY_STRUCT:
+		for _, f := range ty.Fields {
+			offs = pointerWords(offs, f.Type, off+f.Offset)
+		}
+	}
+	return offs
+}
+
+// mapValueOffset returns the offset of the element in an entry of a map
+// with keys of type key.
+func mapValueOffset(key *Type) uint {
+	return 8 + alignTo(key.size(), 8)
+}
+
+// keySpan is a span of a key hashed and compared by the runtime, either as
+// bytes or as a string header of the bytes it refers to.
+type keySpan struct {
+	Offset uint
+	Size   uint
+	String bool
+}
+
+// keySpans appends the spans of a key of type ty at the offset off.
+func keySpans(spans []keySpan, ty *Type, off uint) []keySpan {
+	switch ty.Kind {
+	case TY_STRING:
+		return append(spans, keySpan{off, ty.size(), true})
+	case TY_ARRAY:
+		for i := uint(0); i < ty.ArrayLen; i++ {
+			spans = keySpans(spans, ty.Ref, off+i*ty.Ref.size())
+		}
+		return spans
+	case TY_STRUCT:
+		for _, f := range ty.Fields {
+			spans = keySpans(spans, f.Type, off+f.Offset)
+		}
+		return spans
+	}
+	return append(spans, keySpan{off, ty.size(), false})
+}
+
+// emitSpans emits the number of the spans of a key followed by the spans.
+func emitSpans(spans []keySpan) {
+	fmt.Printf("  .quad %d\n", len(spans))
+	for _, sp := range spans {
+		str := 0
+		if sp.String {
+			str = 1
+		}
+		fmt.Printf("  .quad %d, %d, %d\n", sp.Offset, sp.Size, str)
+	}
+}
+
+// call emits a call to fn.
+func call(fn string) {
+	// We need to align RSP to a 16 byte boundary before
+	// calling a function because it is an ABI requirement.
+	// RAX is set to 0 for variadic function.
+	s := seq()
+	fmt.Printf("  mov rax, rsp\n")
+	fmt.Printf("  and rax, 15\n")
+	fmt.Printf("  jnz .L.call.%d\n", s)
+	fmt.Printf("  mov rax, 0\n")
+	fmt.Printf("  call %s\n", fn)
+	fmt.Printf("  jmp .L.end.%d\n", s)
+	fmt.Printf(".L.call.%d:\n", s)
+	fmt.Printf("  sub rsp, 8\n")
+	fmt.Printf("  mov rax, 0\n")
+	fmt.Printf("  call %s\n", fn)
+	fmt.Printf("  add rsp, 8\n")
+	fmt.Printf(".L.end.%d:\n", s)
+}
+
+// sortedGlobals returns the global variables sorted by name, so that they
+// are emitted in the same order every time.
+func sortedGlobals() []*Var {
+	var vars []*Var
+	for _, v := range globals {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
 }
 
 func emitData(code []*Node) {
 	fmt.Printf(".data\n")
 
-	for _, v := range globals {
+	// The data are aligned to at least 8 bytes, so that the tables of
+	// quads after a global variable are aligned as well.
+	vars := sortedGlobals()
+	for _, v := range vars {
+		align := v.Type.align()
+		if align < 8 {
+			align = 8
+		}
+		fmt.Printf("  .align %d\n", align)
 		fmt.Printf("%s:\n", v.Name)
+		emitValue(v.Type, v.Init)
+	}
+	// The objects the static values refer to follow the variables, and
+	// may refer to more.
+	for i := 0; i < len(statics); i++ {
+		lit := statics[i]
+		fmt.Printf("  .align 8\n")
+		fmt.Printf(".L.static.%d:\n", i)
+		emitValue(lit.Type, lit)
+	}
+	fmt.Printf("  .align 8\n")
 
-		// Not string literals
-		if v.Len == 0 {
-			fmt.Printf("  .zero %d\n", v.Type.size())
-			continue
+	// Any type converted to an interface may be the dynamic type of an
+	// interface value, so the tables of interface types hold the itables
+	// of all such types implementing them.
+	for _, it := range itabs {
+		typeDescOf(it.Type)
+	}
+	for _, iface := range ifaceTables {
+		for _, d := range typeDescs {
+			if !d.Type.isInterface() && missingMethod(d.Type, iface) == "" {
+				itabOf(d.Type, iface)
+			}
+		}
+	}
+
+	for _, it := range itabs {
+		fmt.Printf("%s:\n", it.Label)
+		fmt.Printf("  .quad %s\n", typeDescOf(it.Type).Label)
+		for _, m := range it.Iface.IMethods {
+			fmt.Printf("  .quad %s\n", itabFunc(it.Type, m.Name))
+		}
+	}
+	for _, d := range typeDescs {
+		fmt.Printf("%s:\n", d.Label)
+		fmt.Printf("  .quad %s\n", cstring(d.Type.String()))
+		fmt.Printf("  .quad %d\n", d.Type.size())
+		fmt.Printf("  .quad %d\n", printKind(d.Type))
+		// The spans of a boxed value are laid out as those of a key in
+		// a map descriptor, so that the runtime compares them alike.
+		var spans []keySpan
+		if eqKind(d.Type) == eqBoxed {
+			spans = keySpans(nil, d.Type, 0)
+		}
+		fmt.Printf("  .quad %d\n", eqKind(d.Type))
+		emitSpans(spans)
+	}
+	for i, iface := range ifaceTables {
+		fmt.Printf(".L.itabs.%d:\n", i)
+		for _, it := range itabs {
+			if identical(it.Iface, iface) {
+				fmt.Printf("  .quad %s, %s\n", typeDescOf(it.Type).Label, it.Label)
+			}
+		}
+		fmt.Printf("  .quad 0\n")
+	}
+
+	for i, ty := range mapDescs {
+		spans := keySpans(nil, ty.Key, 0)
+		fmt.Printf(".L.map.%d:\n", i)
+		fmt.Printf("  .quad %d\n", ty.Key.size())
+		fmt.Printf("  .quad %d\n", mapValueOffset(ty.Key))
+		size := mapValueOffset(ty.Key) + alignTo(ty.Ref.size(), 8)
+		fmt.Printf("  .quad %d\n", size)
+		fmt.Printf("  .quad %s\n", gcDesc(size, pointerWords(pointerWords(nil, ty.Key, 8), ty.Ref, mapValueOffset(ty.Key))))
+		emitSpans(spans)
+	}
+	// The collector marks the objects referred to by the global
+	// variables listed in the roots.
+	fmt.Printf("runtime.roots:\n")
+	for _, v := range vars {
+		if d := typeGCDesc(v.Type); d != "0" {
+			fmt.Printf("  .quad %s, %s\n", v.Name, d)
+		}
+	}
+	for i, lit := range statics {
+		if d := typeGCDesc(lit.Type); d != "0" {
+			fmt.Printf("  .quad .L.static.%d, %s\n", i, d)
 		}
-		
-		for _, s := range v.Content {
+	}
+	fmt.Printf("  .quad 0\n")
+	for i, l := range gcLayouts {
+		fmt.Printf(".L.gc.%d:\n", i)
+		fmt.Printf("  .quad %d, %d\n", l.Size, len(l.Offs))
+		for _, off := range l.Offs {
+			fmt.Printf("  .quad %d\n", off)
+		}
+	}
+	for _, fn := range closures {
+		fmt.Printf(".L.closure.%s:\n", fn)
+		fmt.Printf("  .quad %s\n", fn)
+	}
+	fmt.Printf("runtime.zero:\n")
+	fmt.Printf("  .zero %d\n", alignTo(zeroSize+1, 8))
+
+	// A string literal is a string header followed by its bytes.
+	fmt.Printf(".section .rodata\n")
+	for _, v := range literals {
+		fmt.Printf("  .align 8\n")
+		fmt.Printf("%s:\n", v.Name)
+		fmt.Printf("  .quad %s+16\n", v.Name)
+		fmt.Printf("  .quad %d\n", v.Len)
+		for _, s := range []byte(v.Content) {
 			fmt.Printf("  .byte %d\n", s)
 		}
 	}
+	for i, s := range cstrings {
+		fmt.Printf(".L.cstr.%d:\n", i)
+		fmt.Printf("  .string %q\n", s)
+	}
+}
+
+// statics holds the literals laid out in the data section, which the static
+// values of the global variables refer to.
+var statics []*Node
+
+// emitValue emits the static value n of type ty, which is a constant, an
+// array or a struct literal of constants, a slice literal or a pointer to a
+// literal of constants, or nil for the zero value.
+func emitValue(ty *Type, n *Node) {
+	switch {
+	case n == nil || n.Kind == ND_NIL:
+		fmt.Printf("  .zero %d\n", ty.size())
+	case n.Kind == ND_SLICE:
+		lit := n.Lhs.Lhs
+		fmt.Printf("  .quad .L.static.%d\n", len(statics))
+		fmt.Printf("  .quad %d\n", lit.Type.ArrayLen)
+		fmt.Printf("  .quad %d\n", lit.Type.ArrayLen)
+		statics = append(statics, lit)
+	case n.Kind == ND_ADDR:
+		fmt.Printf("  .quad .L.static.%d\n", len(statics))
+		statics = append(statics, n.Lhs)
+	case n.Kind == ND_COMPLIT:
+		// The elements are laid out in the order of their offsets, and
+		// the rest is zeroed.
+		elems := make(map[uint]*Node)
+		staticElems(n, 0, elems)
+		var offsets []uint
+		for off := range elems {
+			offsets = append(offsets, off)
+		}
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+		var pos uint
+		for _, off := range offsets {
+			if off > pos {
+				fmt.Printf("  .zero %d\n", off-pos)
+			}
+			a := elems[off]
+			emitValue(a.Lhs.Type, a.Rhs)
+			pos = off + a.Lhs.Type.size()
+		}
+		if ty.size() > pos {
+			fmt.Printf("  .zero %d\n", ty.size()-pos)
+		}
+	case n.Kind == ND_STR:
+		fmt.Printf("  .quad %s+16\n", n.Var.Name)
+		fmt.Printf("  .quad %d\n", n.Var.Len)
+	case ty.size() == 1:
+		fmt.Printf("  .byte %d\n", n.Val)
+	case ty.size() == 4:
+		fmt.Printf("  .long %d\n", n.Val)
+	default:
+		fmt.Printf("  .quad %d\n", n.Val)
+	}
+}
+
+// staticElems collects the assignments of the constant elements of the
+// literal n, which is at the offset off, by the offsets of the elements.
+func staticElems(n *Node, off uint, elems map[uint]*Node) {
+	for _, a := range n.Body {
+		o := off
+		switch l := a.Lhs; l.Kind {
+		case ND_INDEX:
+			o += uint(l.Rhs.Val) * l.Type.size()
+		case ND_MEMBER:
+			o += l.Member.Offset
+		}
+		if a.Rhs.Kind == ND_COMPLIT {
+			staticElems(a.Rhs, o, elems)
+		} else {
+			elems[o] = a
+		}
+	}
+}
+
+// A wrapper calls a method with a receiver of type T through a pointer to
+// T. It is needed when T is not an aggregate, as the method takes the
+// value itself rather than a pointer to it, and when the method is
+// promoted, as the receiver is then found through the embedded fields.
+type wrapper struct {
+	Label string
+	Sel   *selection
+}
+
+var wrappers []wrapper
+
+// itabFunc returns the function implementing the method of ty in an itable.
+func itabFunc(ty *Type, name string) string {
+	sel := ty.methodSet(name)
+	if len(sel.Path) == 0 {
+		recv := sel.Method.Args[0].Type
+		if !ty.isPointer() || recv.isPointer() || recv.isAggregate() {
+			return sel.Method.FunctionName
+		}
+	}
+	w := wrapper{fmt.Sprintf(".L.wrap.%d", len(wrappers)), sel}
+	wrappers = append(wrappers, w)
+	return w.Label
+}
+
+func emitWrappers() {
+	fmt.Printf(".text\n")
+	for _, w := range wrappers {
+		fmt.Printf("%s:\n", w.Label)
+		ty := w.Sel.methodType()
+		reg := argreg8[0]
+		if ty.Result != nil && ty.Result.isAggregate() {
+			reg = argreg8[1]
+		}
+		// The data word of a struct is a pointer to it, from which the
+		// embedded fields are reached. A pointer is only checked for nil
+		// when it is dereferenced, so that a method with a pointer
+		// receiver may be called with nil.
+		for _, f := range w.Sel.Path {
+			genNilCheck(reg)
+			if f.Offset > 0 {
+				fmt.Printf("  add %s, %d\n", reg, f.Offset)
+			}
+			if f.Type.isPointer() {
+				fmt.Printf("  mov %s, [%s]\n", reg, reg)
+			}
+		}
+		if w.Sel.Method == nil {
+			// The method of an embedded interface is called with
+			// its data word.
+			genNilCheck(reg)
+			fmt.Printf("  mov r11, [%s]\n", reg)
+			genNilCheck("r11")
+			fmt.Printf("  mov %s, [%s+8]\n", reg, reg)
+			fmt.Printf("  jmp [r11+%d]\n", (w.Sel.IMethod+1)*8)
+			continue
+		}
+		recv := w.Sel.Method.Args[0].Type
+		if !recv.isPointer() {
+			genNilCheck(reg)
+		}
+		switch {
+		case recv.isPointer(), recv.isAggregate():
+		case recv.size() == 1:
+			fmt.Printf("  movzx %s, byte ptr [%s]\n", reg, reg)
+		case recv.size() == 4:
+			fmt.Printf("  movsxd %s, dword ptr [%s]\n", reg, reg)
+		default:
+			fmt.Printf("  mov %s, [%s]\n", reg, reg)
+		}
+		fmt.Printf("  jmp %s\n", w.Sel.Method.FunctionName)
+	}
 }
 
-func loadArgs(args []*Node) {
+// loadArgs stores the arguments passed in registers, starting from the
+// reg-th register, to their local variables. An aggregate is passed as a
+// pointer to a copy owned by the caller.
+func loadArgs(args []*Node, reg int) {
+	var aggregates []*Node
 	for i, a := range args {
 		v := a.Var
+		if a.Type.isAggregate() {
+			fmt.Printf("  push %s\n", argreg8[reg+i])
+			aggregates = append(aggregates, a)
+			continue
+		}
+		dst := fmt.Sprintf("[rbp-%d]", v.Offset)
+		if v.OnHeap {
+			fmt.Printf("  mov rax, [rbp-%d]\n", v.Offset)
+			dst = "[rax]"
+		}
 		sz := a.Type.size()
 		switch sz {
 		case 1:
-			fmt.Printf("  mov [rbp-%d], %s\n", v.Offset, argreg1[i])
+			fmt.Printf("  mov %s, %s\n", dst, argreg1[reg+i])
+		case 4:
+			fmt.Printf("  mov %s, %s\n", dst, argreg4[reg+i])
 		case 8:
-			fmt.Printf("  mov [rbp-%d], %s\n", v.Offset, argreg8[i])
+			fmt.Printf("  mov %s, %s\n", dst, argreg8[reg+i])
 		default:
 			panic(fmt.Sprintf("invalid size: %d", sz))
 		}
 	}
+	for i := len(aggregates) - 1; i >= 0; i-- {
+		a := aggregates[i]
+		fmt.Printf("  pop rsi\n")
+		if a.Var.OnHeap {
+			fmt.Printf("  mov rdi, [rbp-%d]\n", a.Var.Offset)
+		} else {
+			fmt.Printf("  lea rdi, [rbp-%d]\n", a.Var.Offset)
+		}
+		fmt.Printf("  mov rcx, %d\n", a.Type.size())
+		fmt.Printf("  rep movsb\n")
+	}
 }
 
 func emitText(code []*Node) {
 	fmt.Printf(".text\n")
 
-	var offset uint
 	for _, n := range code {
 		switch n.Kind {
 		case ND_FUNC:
@@ -109,25 +645,142 @@ func emitText(code []*Node) {
 			fmt.Printf("%s:\n", n.FunctionName)
 			funcname = n.FunctionName
 
-			for _, a := range n.Args {
-				offset += a.Type.size()
-				a.Var.Offset = offset
+			// The goroutine yields to the others here if it has
+			// run for its time slice.
+			s := seq()
+			fmt.Printf("  cmp qword ptr [rip+runtime.preempt], 0\n")
+			fmt.Printf("  je .L.run.%d\n", s)
+			fmt.Printf("  call runtime.gopreempt\n")
+			fmt.Printf(".L.run.%d:\n", s)
+			namedResult = n.Result
+
+			// A function returning an aggregate takes a pointer to
+			// the caller's result as a hidden first argument.
+			var offset uint
+			reg := 0
+			retOffset = 0
+			if n.Type != nil && n.Type.isAggregate() {
+				offset += 8
+				retOffset = offset
+				reg++
+			}
+			if len(n.Args)+reg > len(argreg8) {
+				panic(fmt.Sprintf("too many arguments in %s", declName(n.FunctionName)))
 			}
 			for l := n.Locals; l != nil; l = l.Next {
-				offset += l.Var.Type.size()
+				size, align := l.Var.Type.size(), l.Var.Type.align()
+				if l.Var.OnHeap {
+					size, align = 8, 8
+				}
+				offset = alignTo(offset+size, align)
 				l.Var.Offset = offset
 			}
+			offset = alignTo(offset, 16)
+			frameSize = offset
+
+			// The function continues on a new segment of the
+			// stack if its frame does not fit above the guard.
+			s = seq()
+			fmt.Printf("  lea r11, [rsp-%d]\n", frameSize)
+			fmt.Printf("  cmp r11, [rip+runtime.stackguard]\n")
+			fmt.Printf("  jae .L.stack.%d\n", s)
+			fmt.Printf("  mov r11, %d\n", frameSize)
+			fmt.Printf("  call runtime.morestack\n")
+			fmt.Printf(".L.stack.%d:\n", s)
 			fmt.Printf("  push rbp\n")
 			fmt.Printf("  mov rbp, rsp\n")
 			fmt.Printf("  sub rsp, %d\n", offset)
-			loadArgs(n.Args)
+
+			// Local variables are initialized to their zero values.
+			s = seq()
+			fmt.Printf("  mov rax, rsp\n")
+			fmt.Printf(".L.zero.%d:\n", s)
+			fmt.Printf("  cmp rax, rbp\n")
+			fmt.Printf("  je .L.zeroed.%d\n", s)
+			fmt.Printf("  mov qword ptr [rax], 0\n")
+			fmt.Printf("  add rax, 8\n")
+			fmt.Printf("  jmp .L.zero.%d\n", s)
+			fmt.Printf(".L.zeroed.%d:\n", s)
+
+			// A function literal takes its closure in R10, from
+			// which the pointers to the captured variables are
+			// loaded.
+			for i, c := range n.Vars {
+				fmt.Printf("  mov rax, [r10+%d]\n", (i+1)*8)
+				fmt.Printf("  mov [rbp-%d], rax\n", c.Offset)
+			}
+
+			// The parameters moved to the heap are allocated before
+			// the arguments are stored to them.
+			var heapArgs []*Var
+			for _, a := range n.Args {
+				if a.Var.OnHeap {
+					heapArgs = append(heapArgs, a.Var)
+				}
+			}
+			if n.Result != nil && n.Result.OnHeap {
+				heapArgs = append(heapArgs, n.Result)
+			}
+			if heapArgs != nil {
+				for i := 0; i < len(n.Args)+reg; i++ {
+					fmt.Printf("  push %s\n", argreg8[i])
+				}
+				genNewVars(heapArgs)
+				for i := len(n.Args) + reg - 1; i >= 0; i-- {
+					fmt.Printf("  pop %s\n", argreg8[i])
+				}
+			}
+			if retOffset != 0 {
+				fmt.Printf("  mov [rbp-%d], rdi\n", retOffset)
+			}
+			loadArgs(n.Args, reg)
 
 			gen(n.Block)
 
 			fmt.Printf(".L.return.%s:\n", funcname)
+			if n.HasDefer {
+				// The deferred calls are made with the result
+				// saved on the stack.
+				fmt.Printf("  lea rsp, [rbp-%d]\n", frameSize)
+				fmt.Printf("  push rax\n")
+				fmt.Printf("  push rax\n")
+				fmt.Printf("  mov rdi, rbp\n")
+				fmt.Printf("  call runtime.deferreturn\n")
+				fmt.Printf("  pop rax\n")
+				fmt.Printf("  pop rax\n")
+			}
+			if n.Result != nil {
+				// The named result is returned as it is after the
+				// deferred calls.
+				gen(newVarNode(n.Result))
+				fmt.Printf("  pop rax\n")
+				if retOffset != 0 {
+					fmt.Printf("  mov rsi, rax\n")
+					fmt.Printf("  mov rdi, [rbp-%d]\n", retOffset)
+					fmt.Printf("  mov rcx, %d\n", n.Result.Type.size())
+					fmt.Printf("  rep movsb\n")
+					fmt.Printf("  mov rax, [rbp-%d]\n", retOffset)
+				}
+			}
 			fmt.Printf("  mov rsp, rbp\n")
 			fmt.Printf("  pop rbp\n")
 			fmt.Printf("  ret\n")
+
+			if n.HasDefer {
+				// A function whose deferred call recovers from
+				// a panic returns the zero value, unless the
+				// result is named, from its recovery point.
+				fmt.Printf(".L.recover.%s:\n", funcname)
+				fmt.Printf("  lea rsp, [rbp-%d]\n", frameSize)
+				fmt.Printf("  mov rax, 0\n")
+				if retOffset != 0 && n.Result == nil {
+					fmt.Printf("  mov rdi, [rbp-%d]\n", retOffset)
+					fmt.Printf("  mov rcx, %d\n", n.Type.size())
+					fmt.Printf("  rep stosb\n")
+					fmt.Printf("  mov rax, [rbp-%d]\n", retOffset)
+				}
+				fmt.Printf("  jmp .L.return.%s\n", funcname)
+			}
 		default:
 			panic("expected declaration")
 		}
@@ -140,35 +793,73 @@ func gen(node *Node) {
 	}
 	switch node.Kind {
 	case ND_NUM:
+		if node.Const != nil {
+			panic(fmt.Sprintf("constant %s overflows int", node.Const))
+		}
+		if node.Val < math.MinInt32 || node.Val > math.MaxInt32 {
+			fmt.Printf("  mov rax, %d\n", node.Val)
+			fmt.Printf("  push rax\n")
+			return
+		}
 		fmt.Printf("  push %d\n", node.Val)
 	case ND_VAR:
 		genAddr(node)
 		load(node.Type)
 	case ND_ASSIGN:
-		genAddr(node.Lhs)
-		gen(node.Rhs)
+		if isMapIndex(node.Lhs) {
+			// The value is evaluated before the element is inserted.
+			gen(node.Rhs)
+			genAddr(node.Lhs)
+			fmt.Printf("  pop rax\n")
+			fmt.Printf("  pop rdi\n")
+			fmt.Printf("  push rax\n")
+			fmt.Printf("  push rdi\n")
+		} else {
+			genAddr(node.Lhs)
+			gen(node.Rhs)
+		}
 		store(node.Lhs.Type)
 	case ND_RETURN:
+		if namedResult != nil {
+			// The value is assigned to the named result, which is
+			// returned by the epilogue.
+			if node.Lhs != nil {
+				genAddr(newVarNode(namedResult))
+				gen(node.Lhs)
+				store(namedResult.Type)
+			}
+			fmt.Printf("  jmp .L.return.%s\n", funcname)
+			return
+		}
 		if node.Lhs != nil {
 			gen(node.Lhs)
 			fmt.Printf("  pop rax\n")
 		}
+		if retOffset != 0 {
+			fmt.Printf("  mov rsi, rax\n")
+			fmt.Printf("  mov rdi, [rbp-%d]\n", retOffset)
+			fmt.Printf("  mov rcx, %d\n", node.Lhs.Type.size())
+			fmt.Printf("  rep movsb\n")
+			fmt.Printf("  mov rax, [rbp-%d]\n", retOffset)
+		}
 		fmt.Printf("  jmp .L.return.%s\n", funcname)
 	case ND_ADD, ND_SUB, ND_MUL, ND_DIV, ND_EQ, ND_NE, ND_LT, ND_LE:
 		genBinary(node)
-	case ND_INC:
+	case ND_INC, ND_DEC:
 		genAddr(node.Lhs)
+		fmt.Printf("  push qword ptr [rsp]\n")
+		load(node.Lhs.Type)
 		fmt.Printf("  pop rax\n")
-		fmt.Printf("  mov rdi, [rax]\n")
-		fmt.Printf("  add rdi, 1\n")
-		fmt.Printf("  mov [rax], rdi\n")
-	case ND_DEC:
-		genAddr((node.Lhs))
-		fmt.Printf("  pop rax\n")
-		fmt.Printf("  mov rdi, [rax]\n")
-		fmt.Printf("  sub rdi, 1\n")
-		fmt.Printf("  mov [rax], rdi\n")
+		if node.Kind == ND_INC {
+			fmt.Printf("  add rax, 1\n")
+		} else {
+			fmt.Printf("  sub rax, 1\n")
+		}
+		fmt.Printf("  push rax\n")
+		store(node.Lhs.Type)
+		fmt.Printf("  add rsp, 8\n")
 	case ND_IF:
+		genNewVars(node.Vars)
 		gen(node.Init)
 		gen(node.Cond)
 		s := seq()
@@ -177,6 +868,7 @@ func gen(node *Node) {
 		if node.Els != nil {
 			fmt.Printf("  je .L.else.%d\n", s)
 			gen(node.Then)
+			fmt.Printf("  jmp .L.end.%d\n", s)
 			fmt.Printf(".L.else.%d:\n", s)
 			gen(node.Els)
 		} else {
@@ -185,81 +877,1255 @@ func gen(node *Node) {
 		}
 		fmt.Printf(".L.end.%d:\n", s)
 	case ND_FOR:
+		genNewVars(node.Vars)
 		gen(node.Init)
 		s := seq()
+		node.BrkLabel = fmt.Sprintf(".L.end.%d", s)
+		node.ContLabel = fmt.Sprintf(".L.continue.%d", s)
 		fmt.Printf(".L.begin.%d:\n", s)
-		gen(node.Cond)
-		fmt.Printf("  pop rax\n")
-		fmt.Printf("  cmp rax, 0\n")
-		fmt.Printf("  je .L.end.%d\n", s)
+		if node.Cond != nil {
+			gen(node.Cond)
+			fmt.Printf("  pop rax\n")
+			fmt.Printf("  cmp rax, 0\n")
+			fmt.Printf("  je .L.end.%d\n", s)
+		}
 		gen(node.Then)
+		fmt.Printf("%s:\n", node.ContLabel)
+		// Each iteration has its own instances of the variables
+		// declared in the header, which are copied from the previous
+		// one before the post statement.
+		for _, v := range node.Vars {
+			if v.OnHeap {
+				fmt.Printf("  push qword ptr [rbp-%d]\n", v.Offset)
+				genNew(v)
+				fmt.Printf("  pop rsi\n")
+				fmt.Printf("  mov rdi, [rbp-%d]\n", v.Offset)
+				fmt.Printf("  mov rcx, %d\n", v.Type.size())
+				fmt.Printf("  rep movsb\n")
+			}
+		}
 		gen(node.Inc)
+		fmt.Printf("  lea rsp, [rbp-%d]\n", frameSize)
 		fmt.Printf("  jmp .L.begin.%d\n", s)
 		fmt.Printf(".L.end.%d:\n", s)
+	case ND_RANGE:
+		genRange(node)
+	case ND_BREAK:
+		fmt.Printf("  jmp %s\n", node.Target.BrkLabel)
+	case ND_CONTINUE:
+		fmt.Printf("  jmp %s\n", node.Target.ContLabel)
+	case ND_GOTO:
+		fmt.Printf("  jmp %s\n", node.Target.Label)
+	case ND_LABEL:
+		fmt.Printf("%s:\n", node.Label)
+		gen(node.Lhs)
 	case ND_BLOCK:
+		genNewVars(node.Vars)
 		for _, n := range node.Body {
 			gen(n)
+			// The value left by an expression statement is
+			// discarded, so that no stale pointers are left on the
+			// stack for the collector to find, and the stack does
+			// not grow in loops.
+			fmt.Printf("  lea rsp, [rbp-%d]\n", frameSize)
 		}
 	case ND_FUNCALL:
+		if node.Lhs == nil && strings.HasPrefix(node.FunctionName, "atomic.") {
+			genAtomic(node)
+			return
+		}
 		var nargs int
+		if node.Lhs != nil {
+			// The closure of the function value
+			gen(node.Lhs)
+		}
+		if node.Var != nil {
+			// The pointer to the temporary receiving the result
+			fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
+			fmt.Printf("  push rax\n")
+			nargs++
+		}
 		for _, a := range node.Args {
 			gen(a)
 			nargs++
 		}
+		if nargs > len(argreg8) {
+			panic(fmt.Sprintf("too many arguments in call to %s", declName(node.FunctionName)))
+		}
 		for i := nargs - 1; i >= 0; i-- {
 			fmt.Printf("  pop %s\n", argreg8[i])
 		}
-		// We need to align RSP to a 16 byte boundary before
-		// calling a function because it is an ABI requirement.
-		// RAX is set to 0 for variadic function.
-		s := seq()
-		fmt.Printf("  mov rax, rsp\n")
-		fmt.Printf("  and rax, 15\n")
-		fmt.Printf("  jnz .L.call.%d\n", s)
-		fmt.Printf("  mov rax, 0\n")
-		fmt.Printf("  call %s\n", node.FunctionName)
-		fmt.Printf("  jmp .L.end.%d\n", s)
-		fmt.Printf(".L.call.%d:\n", s)
-		fmt.Printf("  sub rsp, 8\n")
-		fmt.Printf("  mov rax, 0\n")
-		fmt.Printf("  call %s\n", node.FunctionName)
-		fmt.Printf("  add rsp, 8\n")
-		fmt.Printf(".L.end.%d:\n", s)
+		if node.Lhs != nil {
+			fmt.Printf("  pop r10\n")
+			genNilCheck("r10")
+			fmt.Printf("  mov r11, [r10]\n")
+			call("r11")
+		} else {
+			call(node.FunctionName)
+		}
+		fmt.Printf("  push rax\n")
+	case ND_ICALL:
+		// The method is looked up in the itable of the interface
+		// value, and called with the data word as its receiver.
+		gen(node.Lhs)
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  push qword ptr [rax]\n")
+		genNilCheck("qword ptr [rsp]")
+		nargs := 1
+		if node.Var != nil {
+			fmt.Printf("  lea rdi, [rbp-%d]\n", node.Var.Offset)
+			fmt.Printf("  push rdi\n")
+			nargs++
+		}
+		fmt.Printf("  push qword ptr [rax+8]\n")
+		for _, a := range node.Args {
+			gen(a)
+			nargs++
+		}
+		if nargs > len(argreg8) {
+			panic(fmt.Sprintf("too many arguments in call to %s", declName(node.FunctionName)))
+		}
+		for i := nargs - 1; i >= 0; i-- {
+			fmt.Printf("  pop %s\n", argreg8[i])
+		}
+		fmt.Printf("  pop r11\n")
+		fmt.Printf("  mov r11, [r11+%d]\n", (node.Val+1)*8)
+		call("r11")
 		fmt.Printf("  push rax\n")
+	case ND_NIL:
+		fmt.Printf("  push 0\n")
+	case ND_STR:
+		fmt.Printf("  push offset %s\n", node.Var.Name)
+	case ND_ASSERT:
+		genAssert(node)
+	case ND_TYPESWITCH:
+		genNewVars(node.Vars)
+		genTypeSwitch(node)
+	case ND_SWITCH:
+		genNewVars(node.Vars)
+		genSwitch(node)
+	case ND_FALLTHROUGH:
+		fmt.Printf("  jmp %s\n", node.Target.Label)
 	case ND_ADDR:
 		genAddr(node.Lhs)
 	case ND_DEREF:
-		if v := node.Lhs.Var; !v.Type.isPointer() {
-			panic(fmt.Sprintf("invalid indirect of %s (type %v)", v.Name, v.Type.Kind))
+		if ty := node.Lhs.Type; !ty.isPointer() {
+			panic(fmt.Sprintf("invalid indirect of type %s", ty))
 		}
 		gen(node.Lhs)
+		genNilCheck("qword ptr [rsp]")
+		load(node.Type)
+	case ND_MEMBER:
+		genAddr(node)
 		load(node.Type)
 	case ND_INDEX:
-		if ty := node.Lhs.Type; !ty.isArray() {
-			errorIndexing(ty.Kind)
+		if node.Lhs.Type.isMap() {
+			genMapIndex(node)
+			return
 		}
 		genAddr(node)
 		load(node.Type)
-	}
-}
-
-func genBinary(node *Node) {
-	gen(node.Lhs)
-	gen(node.Rhs)
-
-	fmt.Printf("  pop rdi\n")
-	fmt.Printf("  pop rax\n")
-
-	switch node.Kind {
-	case ND_ADD:
-		fmt.Printf("  add rax, rdi\n")
-	case ND_SUB:
-		fmt.Printf("  sub rax, rdi\n")
-	case ND_MUL:
-		fmt.Printf("  imul rax, rdi\n")
-	case ND_DIV:
-		fmt.Printf("  cqo\n")
-		fmt.Printf("  idiv rdi\n")
+	case ND_SLICE:
+		genSlice(node)
+	case ND_LEN, ND_CAP:
+		if arr := node.Lhs.Type.arrayType(); arr != nil {
+			fmt.Printf("  push %d\n", arr.ArrayLen)
+			return
+		}
+		gen(node.Lhs)
+		fmt.Printf("  pop rax\n")
+		if ty := node.Lhs.Type; ty.isMap() || ty.isChan() {
+			// The length of a map is the first word of its table,
+			// and the number of the elements in the buffer of a
+			// channel and its size are the fourth and the third.
+			off := 0
+			switch {
+			case ty.isChan() && node.Kind == ND_LEN:
+				off = 24
+			case ty.isChan():
+				off = 16
+			}
+			s := seq()
+			fmt.Printf("  cmp rax, 0\n")
+			fmt.Printf("  je .L.len.%d\n", s)
+			fmt.Printf("  mov rax, [rax+%d]\n", off)
+			fmt.Printf(".L.len.%d:\n", s)
+			fmt.Printf("  push rax\n")
+		} else if node.Kind == ND_LEN {
+			fmt.Printf("  push qword ptr [rax+8]\n")
+		} else {
+			fmt.Printf("  push qword ptr [rax+16]\n")
+		}
+	case ND_APPEND:
+		genAppend(node)
+	case ND_COPY:
+		gen(node.Lhs)
+		gen(node.Rhs)
+		fmt.Printf("  pop rsi\n")
+		fmt.Printf("  pop rdi\n")
+		fmt.Printf("  mov rdx, %d\n", node.Lhs.Type.Ref.size())
+		call("runtime.copy")
+		fmt.Printf("  push rax\n")
+	case ND_MAKE:
+		if node.Type.isSlice() {
+			genMakeSlice(node)
+			return
+		}
+		if node.Lhs != nil {
+			gen(node.Lhs)
+		} else {
+			fmt.Printf("  push 0\n")
+		}
+		fmt.Printf("  pop rsi\n")
+		if ty := node.Type; ty.isChan() {
+			fmt.Printf("  mov rdi, %d\n", ty.Ref.size())
+			fmt.Printf("  mov rdx, offset %s\n", typeGCDesc(ty.Ref))
+			call("runtime.makechan")
+		} else {
+			fmt.Printf("  mov rdi, offset %s\n", mapDesc(ty))
+			call("runtime.makemap")
+		}
+		fmt.Printf("  push rax\n")
+	case ND_MAPLIT:
+		fmt.Printf("  mov rdi, offset %s\n", mapDesc(node.Type))
+		fmt.Printf("  mov rsi, %d\n", len(node.Body))
+		call("runtime.makemap")
+		fmt.Printf("  mov [rbp-%d], rax\n", node.Var.Offset)
+		for _, n := range node.Body {
+			gen(n)
+			fmt.Printf("  add rsp, 8\n")
+		}
+		fmt.Printf("  push qword ptr [rbp-%d]\n", node.Var.Offset)
+	case ND_PANIC:
+		gen(node.Lhs)
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  mov rdi, [rax]\n")
+		fmt.Printf("  mov rsi, [rax+8]\n")
+		call("runtime.gopanic")
+	case ND_RECOVER:
+		// Only the function of a deferred call, which is called by
+		// the closure made for it, recovers.
+		fmt.Printf("  mov rdi, [rbp]\n")
+		fmt.Printf("  lea rsi, [rbp-%d]\n", node.Var.Offset)
+		call("runtime.gorecover")
+		fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
+		fmt.Printf("  push rax\n")
+	case ND_GO:
+		genNewVars(node.Vars)
+		for _, n := range node.Body {
+			gen(n)
+			fmt.Printf("  add rsp, 8\n")
+		}
+		gen(node.Lhs)
+		fmt.Printf("  pop rdi\n")
+		call("runtime.newproc")
+	case ND_DEFER:
+		genNewVars(node.Vars)
+		for _, n := range node.Body {
+			gen(n)
+			fmt.Printf("  add rsp, 8\n")
+		}
+		gen(node.Lhs)
+		fmt.Printf("  pop rdi\n")
+		fmt.Printf("  mov rsi, rbp\n")
+		fmt.Printf("  mov rdx, offset .L.recover.%s\n", funcname)
+		call("runtime.deferproc")
+	case ND_SEND:
+		gen(node.Lhs)
+		genAddr(newVarNode(node.Var))
+		gen(node.Rhs)
+		store(node.Var.Type)
+		fmt.Printf("  add rsp, 8\n")
+		fmt.Printf("  pop rdi\n")
+		fmt.Printf("  lea rsi, [rbp-%d]\n", node.Var.Offset)
+		call("runtime.chansend")
+		fmt.Printf("  push rax\n")
+	case ND_RECV:
+		// Whether the value was received is stored to node.Ok in the
+		// comma-ok form.
+		if node.Ok != nil {
+			genAddr(node.Ok)
+		}
+		gen(node.Lhs)
+		fmt.Printf("  pop rdi\n")
+		fmt.Printf("  lea rsi, [rbp-%d]\n", node.Var.Offset)
+		call("runtime.chanrecv")
+		if node.Ok != nil {
+			fmt.Printf("  push rax\n")
+			store(node.Ok.Type)
+			fmt.Printf("  add rsp, 8\n")
+		}
+		genAddr(newVarNode(node.Var))
+		load(node.Type)
+	case ND_CLOSE:
+		gen(node.Lhs)
+		fmt.Printf("  pop rdi\n")
+		call("runtime.closechan")
+		fmt.Printf("  push rax\n")
+	case ND_SELECT:
+		genSelect(node)
+	case ND_CLOSURE:
+		genClosure(node)
+	case ND_NEW:
+		if node.Var.OnHeap {
+			genNew(node.Var)
+		} else {
+			genZero(node.Var)
+		}
+		genAddr(newVarNode(node.Var))
+	case ND_COMPLIT:
+		// The literal is built in its temporary, which is a new instance
+		// on the heap if its address is taken.
+		if node.Var.OnHeap {
+			genNew(node.Var)
+		} else {
+			genZero(node.Var)
+		}
+		for _, n := range node.Body {
+			gen(n)
+			fmt.Printf("  add rsp, 8\n")
+		}
+		genAddr(newVarNode(node.Var))
+	case ND_DELETE:
+		gen(node.Lhs)
+		genMapKey(node.Rhs, node.Var)
+		fmt.Printf("  pop rsi\n")
+		fmt.Printf("  pop rdi\n")
+		call("runtime.mapdelete")
+		fmt.Printf("  push rax\n")
+	case ND_CONV:
+		if node.Lhs.Type.isNil() && node.Var != nil {
+			genZero(node.Var)
+			fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
+			fmt.Printf("  push rax\n")
+			return
+		}
+		if node.Type.isInterface() && node.Var != nil {
+			if node.Lhs.Type.isInterface() {
+				genIfaceConv(node)
+			} else {
+				genBox(node)
+			}
+			return
+		}
+		if node.Var != nil {
+			genStringConv(node)
+			return
+		}
+		gen(node.Lhs)
+		switch node.Type.size() {
+		case 1:
+			fmt.Printf("  pop rax\n")
+			fmt.Printf("  movzx eax, al\n")
+			fmt.Printf("  push rax\n")
+		case 4:
+			fmt.Printf("  pop rax\n")
+			fmt.Printf("  movsxd rax, eax\n")
+			fmt.Printf("  push rax\n")
+		}
+	}
+}
+
+// genMapKey generates the key k of a map, and pushes its address. A key
+// that is not an aggregate is stored to the temporary v.
+func genMapKey(k *Node, v *Var) {
+	if v == nil {
+		gen(k)
+		return
+	}
+	fmt.Printf("  lea rax, [rbp-%d]\n", v.Offset)
+	fmt.Printf("  push rax\n")
+	gen(k)
+	store(v.Type)
+	fmt.Printf("  lea rax, [rbp-%d]\n", v.Offset)
+	fmt.Printf("  mov [rsp], rax\n")
+}
+
+// genMapIndex generates the map index expression m[k], which results in
+// the zero value if the key is not in the map. In the comma-ok form,
+// whether it is in the map is stored to node.Ok.
+func genMapIndex(node *Node) {
+	if node.Ok != nil {
+		genAddr(node.Ok)
+	}
+	gen(node.Lhs)
+	genMapKey(node.Rhs, node.Var)
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  pop rdi\n")
+	call("runtime.mapaccess")
+	if node.Ok != nil {
+		fmt.Printf("  mov rdx, 0\n")
+		fmt.Printf("  cmp rax, 0\n")
+		fmt.Printf("  setne dl\n")
+		fmt.Printf("  pop rdi\n")
+		fmt.Printf("  push rax\n")
+		fmt.Printf("  push rdi\n")
+		fmt.Printf("  push rdx\n")
+		store(node.Ok.Type)
+		fmt.Printf("  add rsp, 8\n")
+		fmt.Printf("  pop rax\n")
+	}
+	s := seq()
+	fmt.Printf("  cmp rax, 0\n")
+	fmt.Printf("  jne .L.found.%d\n", s)
+	fmt.Printf("  mov rax, offset runtime.zero\n")
+	fmt.Printf(".L.found.%d:\n", s)
+	fmt.Printf("  push rax\n")
+	load(node.Type)
+	if sz := node.Type.size(); sz > zeroSize {
+		zeroSize = sz
+	}
+}
+
+// genRange generates a for statement with a range clause. The key and the
+// element of each iteration are copied to the temporaries node.Lhs and
+// node.Rhs, from which the iteration variables are assigned. The state of
+// the iteration is the pointer to the elements or the map, the number of
+// iterations, the current index and the next index. The elements of a
+// channel are received into node.Lhs until it is closed.
+func genRange(node *Node) {
+	s := seq()
+	node.BrkLabel = fmt.Sprintf(".L.end.%d", s)
+	node.ContLabel = fmt.Sprintf(".L.continue.%d", s)
+	off := node.Var.Offset
+	word := func(i uint) string {
+		return fmt.Sprintf("qword ptr [rbp-%d]", off-i*8)
+	}
+	ty := node.Cond.Type
+	arr := ty.arrayType()
+	if node.Init != nil {
+		gen(node.Init)
+		fmt.Printf("  add rsp, 8\n")
+	}
+	gen(node.Cond)
+	fmt.Printf("  pop rax\n")
+	switch {
+	case ty.isInteger():
+		fmt.Printf("  mov %s, rax\n", word(1))
+	case arr != nil:
+		fmt.Printf("  mov %s, rax\n", word(0))
+		fmt.Printf("  mov %s, %d\n", word(1), arr.ArrayLen)
+	case ty.isSlice(), ty.isString():
+		fmt.Printf("  mov rdi, [rax]\n")
+		fmt.Printf("  mov %s, rdi\n", word(0))
+		fmt.Printf("  mov rdi, [rax+8]\n")
+		fmt.Printf("  mov %s, rdi\n", word(1))
+	default:
+		fmt.Printf("  mov %s, rax\n", word(0))
+	}
+	fmt.Printf("  mov %s, 0\n", word(2))
+
+	// The value of the current iteration is pushed: the entry of a map,
+	// the rune of a string, or the address of an element.
+	fmt.Printf(".L.begin.%d:\n", s)
+	if ty.isChan() {
+		fmt.Printf("  mov rdi, %s\n", word(0))
+		fmt.Printf("  lea rsi, [rbp-%d]\n", node.Lhs.Var.Offset)
+		call("runtime.chanrecv")
+		fmt.Printf("  cmp rax, 0\n")
+		fmt.Printf("  je .L.end.%d\n", s)
+	} else if ty.isMap() {
+		fmt.Printf("  mov rdi, %s\n", word(0))
+		fmt.Printf("  mov rsi, %s\n", word(2))
+		call("runtime.mapiter")
+		fmt.Printf("  cmp rax, 0\n")
+		fmt.Printf("  je .L.end.%d\n", s)
+		fmt.Printf("  mov %s, rdx\n", word(3))
+	} else {
+		fmt.Printf("  mov rax, %s\n", word(2))
+		fmt.Printf("  cmp rax, %s\n", word(1))
+		fmt.Printf("  jge .L.end.%d\n", s)
+		switch {
+		case ty.isString():
+			fmt.Printf("  mov rdi, %s\n", word(0))
+			fmt.Printf("  add rdi, rax\n")
+			fmt.Printf("  mov rsi, %s\n", word(1))
+			fmt.Printf("  sub rsi, rax\n")
+			call("runtime.decoderune")
+			fmt.Printf("  add rdx, %s\n", word(2))
+			fmt.Printf("  mov %s, rdx\n", word(3))
+		case node.Rhs != nil:
+			fmt.Printf("  imul rax, %d\n", node.Rhs.Type.size())
+			fmt.Printf("  add rax, %s\n", word(0))
+		}
+	}
+	fmt.Printf("  push rax\n")
+
+	if !ty.isChan() {
+		genAddr(node.Lhs)
+		if ty.isMap() {
+			fmt.Printf("  mov rax, [rsp+8]\n")
+			fmt.Printf("  add rax, 8\n")
+			fmt.Printf("  push rax\n")
+			load(node.Lhs.Type)
+		} else {
+			fmt.Printf("  push %s\n", word(2))
+		}
+		store(node.Lhs.Type)
+		fmt.Printf("  add rsp, 8\n")
+	}
+	if node.Rhs != nil {
+		genAddr(node.Rhs)
+		fmt.Printf("  mov rax, [rsp+8]\n")
+		if ty.isMap() {
+			fmt.Printf("  add rax, %d\n", mapValueOffset(ty.Key))
+		}
+		fmt.Printf("  push rax\n")
+		if !ty.isString() {
+			load(node.Rhs.Type)
+		}
+		store(node.Rhs.Type)
+		fmt.Printf("  add rsp, 8\n")
+	}
+	fmt.Printf("  add rsp, 8\n")
+
+	for _, n := range node.Body {
+		if v := n.Lhs.Var; v != nil && v.OnHeap {
+			genNew(v)
+		}
+		gen(n)
+		fmt.Printf("  add rsp, 8\n")
+	}
+	gen(node.Then)
+	fmt.Printf("%s:\n", node.ContLabel)
+	if ty.isMap() || ty.isString() {
+		fmt.Printf("  mov rax, %s\n", word(3))
+		fmt.Printf("  mov %s, rax\n", word(2))
+	} else {
+		fmt.Printf("  inc %s\n", word(2))
+	}
+	fmt.Printf("  jmp .L.begin.%d\n", s)
+	fmt.Printf(".L.end.%d:\n", s)
+}
+
+// genSelect generates a select statement. The cases are passed to the
+// runtime, which returns the index of the case done, or -1 for the default
+// clause, and whether the value of a receive was received.
+func genSelect(node *Node) {
+	s := seq()
+	node.BrkLabel = fmt.Sprintf(".L.end.%d", s)
+	off := node.Var.Offset
+	var comms []*Node
+	hasDefault := false
+	for _, c := range node.Body {
+		comm := c.Lhs
+		if comm == nil {
+			hasDefault = true
+			continue
+		}
+		gen(comm.Lhs)
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  mov [rbp-%d], rax\n", off-uint(len(comms))*24)
+		if comm.Kind == ND_SEND {
+			genAddr(newVarNode(comm.Var))
+			gen(comm.Rhs)
+			store(comm.Var.Type)
+			fmt.Printf("  add rsp, 8\n")
+		}
+		fmt.Printf("  lea rax, [rbp-%d]\n", comm.Var.Offset)
+		fmt.Printf("  mov [rbp-%d], rax\n", off-uint(len(comms))*24-8)
+		if comm.Kind == ND_RECV {
+			fmt.Printf("  mov qword ptr [rbp-%d], 1\n", off-uint(len(comms))*24-16)
+		} else {
+			fmt.Printf("  mov qword ptr [rbp-%d], 0\n", off-uint(len(comms))*24-16)
+		}
+		comms = append(comms, comm)
+	}
+	fmt.Printf("  lea rdi, [rbp-%d]\n", off)
+	fmt.Printf("  mov rsi, %d\n", len(comms))
+	if hasDefault {
+		fmt.Printf("  mov rdx, 0\n")
+	} else {
+		fmt.Printf("  mov rdx, 1\n")
+	}
+	call("runtime.selectgo")
+	i := 0
+	for j, c := range node.Body {
+		if c.Lhs == nil {
+			fmt.Printf("  cmp rax, -1\n")
+		} else {
+			fmt.Printf("  cmp rax, %d\n", i)
+			i++
+		}
+		fmt.Printf("  je .L.case.%d.%d\n", s, j)
+	}
+	fmt.Printf("  jmp .L.end.%d\n", s)
+	for j, c := range node.Body {
+		fmt.Printf(".L.case.%d.%d:\n", s, j)
+		if c.Lhs != nil && c.Lhs.Ok != nil {
+			fmt.Printf("  mov [rbp-%d], rdx\n", c.Lhs.Ok.Var.Offset)
+		}
+		gen(c.Then)
+		fmt.Printf("  jmp .L.end.%d\n", s)
+	}
+	fmt.Printf(".L.end.%d:\n", s)
+}
+
+// genMakeSlice generates make([]T, len, cap), which results in the header
+// of a new slice in a temporary.
+func genMakeSlice(node *Node) {
+	gen(node.Lhs)
+	if node.Rhs != nil {
+		gen(node.Rhs)
+	} else {
+		fmt.Printf("  push qword ptr [rsp]\n")
+	}
+	fmt.Printf("  pop rdx\n")
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  lea rdi, [rbp-%d]\n", node.Var.Offset)
+	fmt.Printf("  mov rcx, %d\n", node.Type.Ref.size())
+	fmt.Printf("  mov r8, offset %s\n", typeGCDesc(node.Type.Ref))
+	call("runtime.makeslice")
+	fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
+	fmt.Printf("  push rax\n")
+}
+
+// genClosure generates a function value, which is the pointer to the
+// closure of the function. The closure of a function literal capturing
+// variables is built in its temporary, which is on the heap if the function
+// value escapes, and any other is static.
+func genClosure(node *Node) {
+	if node.Var == nil {
+		fmt.Printf("  push offset %s\n", closureLabel(node.FunctionName))
+		return
+	}
+	if node.Var.OnHeap {
+		genNew(node.Var)
+	}
+	genAddr(newVarNode(node.Var))
+	fmt.Printf("  pop rax\n")
+	fmt.Printf("  mov rdi, offset %s\n", node.FunctionName)
+	fmt.Printf("  mov [rax], rdi\n")
+	for _, n := range node.Body {
+		gen(n)
+		fmt.Printf("  add rsp, 8\n")
+	}
+	genAddr(newVarNode(node.Var))
+}
+
+// genNewVars allocates new instances of the variables among vars which are
+// on the heap. The variables declared in a block or in the header of a
+// statement are allocated each time it is entered.
+func genNewVars(vars []*Var) {
+	for _, v := range vars {
+		if v.OnHeap {
+			genNew(v)
+		}
+	}
+}
+
+// genNew allocates a new instance of the local variable v on the heap.
+func genNew(v *Var) {
+	fmt.Printf("  mov rdi, %d\n", v.Type.size())
+	fmt.Printf("  mov rsi, offset %s\n", typeGCDesc(v.Type))
+	call("runtime.alloc")
+	fmt.Printf("  mov [rbp-%d], rax\n", v.Offset)
+}
+
+// genStringConv generates a conversion from or to a string, which results
+// in a string or a slice header in a temporary referring to a new copy of
+// the bytes or runes of the operand.
+func genStringConv(node *Node) {
+	from, to := node.Lhs.Type, node.Type
+	gen(node.Lhs)
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  lea rdi, [rbp-%d]\n", node.Var.Offset)
+	switch {
+	case from.isInteger():
+		call("runtime.intstring")
+	case from.isSlice() && from.Ref.isByte(), to.isSlice() && to.Ref.isByte():
+		call("runtime.dupbytes")
+		if to.isSlice() {
+			fmt.Printf("  mov rax, [rbp-%d]\n", node.Var.Offset-8)
+			fmt.Printf("  mov [rbp-%d], rax\n", node.Var.Offset-16)
+		}
+	case to.isSlice():
+		call("runtime.stringtoslicerune")
+	default:
+		call("runtime.slicerunetostring")
+	}
+	fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
+	fmt.Printf("  push rax\n")
+}
+
+// genBox converts a concrete value to an interface value, which is the pair
+// of the itable and the data word. The data word holds the value itself if
+// it fits in a register, or a pointer to a copy of it on the heap.
+func genBox(node *Node) {
+	off := node.Var.Offset
+	ty := node.Lhs.Type
+	gen(node.Lhs)
+	if ty.isAggregate() {
+		fmt.Printf("  mov rdi, %d\n", ty.size())
+		fmt.Printf("  mov rsi, offset %s\n", typeGCDesc(ty))
+		call("runtime.alloc")
+		fmt.Printf("  pop rsi\n")
+		fmt.Printf("  mov rdi, rax\n")
+		fmt.Printf("  mov rcx, %d\n", ty.size())
+		fmt.Printf("  rep movsb\n")
+	} else {
+		fmt.Printf("  pop rax\n")
+	}
+	fmt.Printf("  mov [rbp-%d], rax\n", off-8)
+	fmt.Printf("  mov rax, offset %s\n", node.Itab.Label)
+	fmt.Printf("  mov [rbp-%d], rax\n", off)
+	fmt.Printf("  lea rax, [rbp-%d]\n", off)
+	fmt.Printf("  push rax\n")
+}
+
+// genNilCheck panics if the pointer in the operand is nil.
+func genNilCheck(operand string) {
+	s := seq()
+	fmt.Printf("  cmp %s, 0\n", operand)
+	fmt.Printf("  jne .L.nonnil.%d\n", s)
+	fmt.Printf("  call runtime.panicmem\n")
+	fmt.Printf(".L.nonnil.%d:\n", s)
+}
+
+// genAtomic generates a call of a function of sync/atomic inline, as a
+// locked instruction on the integer its first argument points to.
+func genAtomic(node *Node) {
+	for _, a := range node.Args {
+		gen(a)
+	}
+	size := node.Args[0].Type.Ref.size()
+	ax, dx := "rax", "rdx"
+	if size == 4 {
+		ax, dx = "eax", "edx"
+	}
+	name := strings.TrimPrefix(node.FunctionName, "atomic.")
+	switch {
+	case strings.HasPrefix(name, "Add"):
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  pop rdi\n")
+		genNilCheck("rdi")
+		fmt.Printf("  mov rdx, rax\n")
+		fmt.Printf("  lock xadd [rdi], %s\n", ax)
+		fmt.Printf("  add %s, %s\n", ax, dx)
+	case strings.HasPrefix(name, "Load"):
+		fmt.Printf("  pop rdi\n")
+		genNilCheck("rdi")
+		fmt.Printf("  mov %s, [rdi]\n", ax)
+	case strings.HasPrefix(name, "Store"):
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  pop rdi\n")
+		genNilCheck("rdi")
+		fmt.Printf("  xchg [rdi], %s\n", ax)
+		fmt.Printf("  push rax\n")
+		return
+	case strings.HasPrefix(name, "CompareAndSwap"):
+		fmt.Printf("  pop rdx\n")
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  pop rdi\n")
+		genNilCheck("rdi")
+		fmt.Printf("  lock cmpxchg [rdi], %s\n", dx)
+		fmt.Printf("  sete al\n")
+		fmt.Printf("  movzx rax, al\n")
+		fmt.Printf("  push rax\n")
+		return
+	}
+	if size == 4 {
+		fmt.Printf("  movsxd rax, eax\n")
+	}
+	fmt.Printf("  push rax\n")
+}
+
+// genZero sets the local variable v to the zero value.
+func genZero(v *Var) {
+	fmt.Printf("  lea rdi, [rbp-%d]\n", v.Offset)
+	fmt.Printf("  mov rcx, %d\n", v.Type.size())
+	fmt.Printf("  mov al, 0\n")
+	fmt.Printf("  rep stosb\n")
+}
+
+// genSlice generates the slice expression a[lo:hi:max], which results in
+// a slice header in a temporary. The omitted indices default to 0, the
+// length and the capacity of a.
+func genSlice(node *Node) {
+	ty := node.Lhs.Type
+	gen(node.Lhs)
+	fmt.Printf("  pop rax\n")
+	capName := "capacity"
+	if arr := ty.arrayType(); arr != nil {
+		if ty.isPointer() {
+			genNilCheck("rax")
+		}
+		fmt.Printf("  push rax\n")
+		fmt.Printf("  push %d\n", arr.ArrayLen)
+		fmt.Printf("  push %d\n", arr.ArrayLen)
+		capName = "length"
+		ty = arr
+	} else if ty.isString() {
+		fmt.Printf("  push qword ptr [rax]\n")
+		fmt.Printf("  push qword ptr [rax+8]\n")
+		fmt.Printf("  push qword ptr [rax+8]\n")
+		capName = "length"
+	} else {
+		fmt.Printf("  push qword ptr [rax]\n")
+		fmt.Printf("  push qword ptr [rax+8]\n")
+		fmt.Printf("  push qword ptr [rax+16]\n")
+	}
+	lo, hi, max := node.Args[0], node.Args[1], node.Args[2]
+	if lo != nil {
+		gen(lo)
+	} else {
+		fmt.Printf("  push 0\n")
+	}
+	if hi != nil {
+		gen(hi)
+	} else {
+		fmt.Printf("  push qword ptr [rsp+16]\n")
+	}
+	if max != nil {
+		gen(max)
+	} else {
+		fmt.Printf("  push qword ptr [rsp+16]\n")
+	}
+	fmt.Printf("  pop r10\n")
+	fmt.Printf("  pop rdx\n")
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  pop r9\n")
+	fmt.Printf("  pop r8\n")
+	fmt.Printf("  pop rax\n")
+
+	// 0 <= lo <= hi <= max <= cap
+	s := seq()
+	var msgs [3]string
+	if max != nil {
+		msgs = [3]string{"[::%ld] with " + capName + " %ld", "[:%ld:%ld]", "[%ld:%ld:]"}
+	} else {
+		msgs = [3]string{"", "[:%ld] with " + capName + " %ld", "[%ld:%ld]"}
+	}
+	fmt.Printf("  cmp r10, r9\n")
+	fmt.Printf("  ja .L.slice.%d.0\n", s)
+	fmt.Printf("  cmp rdx, r10\n")
+	fmt.Printf("  ja .L.slice.%d.1\n", s)
+	fmt.Printf("  cmp rsi, rdx\n")
+	fmt.Printf("  ja .L.slice.%d.2\n", s)
+	fmt.Printf("  jmp .L.sliced.%d\n", s)
+	args := [3][2]string{{"r10", "r9"}, {"rdx", "r10"}, {"rsi", "rdx"}}
+	for i, msg := range msgs {
+		fmt.Printf(".L.slice.%d.%d:\n", s, i)
+		fmt.Printf("  mov rdi, %s\n", args[i][0])
+		fmt.Printf("  mov rdx, %s\n", args[i][1])
+		fmt.Printf("  mov rsi, rdi\n")
+		fmt.Printf("  mov rdi, offset %s\n", cstring("runtime error: slice bounds out of range "+msg))
+		call("runtime.panicf")
+	}
+	fmt.Printf(".L.sliced.%d:\n", s)
+
+	off := node.Var.Offset
+	fmt.Printf("  mov rdi, rsi\n")
+	if !ty.isString() {
+		fmt.Printf("  imul rdi, %d\n", ty.Ref.size())
+	}
+	fmt.Printf("  add rax, rdi\n")
+	fmt.Printf("  sub rdx, rsi\n")
+	fmt.Printf("  sub r10, rsi\n")
+	fmt.Printf("  mov [rbp-%d], rax\n", off)
+	fmt.Printf("  mov [rbp-%d], rdx\n", off-8)
+	if node.Type.isSlice() {
+		fmt.Printf("  mov [rbp-%d], r10\n", off-16)
+	}
+	fmt.Printf("  lea rax, [rbp-%d]\n", off)
+	fmt.Printf("  push rax\n")
+}
+
+// genAppend generates append(s, x...), which results in a slice header in a
+// temporary. The elements are stored after the runtime has grown the
+// underlying array of the header if the capacity does not suffice.
+func genAppend(node *Node) {
+	off := node.Var.Offset
+	elem := node.Type.Ref
+	gen(node.Lhs)
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  lea rdi, [rbp-%d]\n", off)
+	fmt.Printf("  mov rcx, 24\n")
+	fmt.Printf("  rep movsb\n")
+
+	if node.Rhs != nil {
+		gen(node.Rhs)
+		fmt.Printf("  mov rax, [rsp]\n")
+		fmt.Printf("  lea rdi, [rbp-%d]\n", off)
+		fmt.Printf("  mov rsi, [rax+8]\n")
+		fmt.Printf("  mov rdx, %d\n", elem.size())
+		fmt.Printf("  mov rcx, offset %s\n", typeGCDesc(elem))
+		call("runtime.growslice")
+		fmt.Printf("  pop rsi\n")
+		fmt.Printf("  mov rdx, [rsi+8]\n")
+		fmt.Printf("  mov rsi, [rsi]\n")
+		fmt.Printf("  mov rdi, [rbp-%d]\n", off-8)
+		fmt.Printf("  sub rdi, rdx\n")
+		fmt.Printf("  imul rdi, %d\n", elem.size())
+		fmt.Printf("  add rdi, [rbp-%d]\n", off)
+		fmt.Printf("  imul rdx, %d\n", elem.size())
+		call("memmove")
+	} else {
+		for _, a := range node.Args {
+			gen(a)
+		}
+		n := len(node.Args)
+		fmt.Printf("  lea rdi, [rbp-%d]\n", off)
+		fmt.Printf("  mov rsi, %d\n", n)
+		fmt.Printf("  mov rdx, %d\n", elem.size())
+		fmt.Printf("  mov rcx, offset %s\n", typeGCDesc(elem))
+		call("runtime.growslice")
+		for i := n - 1; i >= 0; i-- {
+			fmt.Printf("  mov rax, [rbp-%d]\n", off-8)
+			fmt.Printf("  sub rax, %d\n", n-i)
+			fmt.Printf("  imul rax, %d\n", elem.size())
+			fmt.Printf("  add rax, [rbp-%d]\n", off)
+			fmt.Printf("  push rax\n")
+			fmt.Printf("  push qword ptr [rsp+8]\n")
+			store(elem)
+			fmt.Printf("  add rsp, 16\n")
+		}
+	}
+	fmt.Printf("  lea rax, [rbp-%d]\n", off)
+	fmt.Printf("  push rax\n")
+}
+
+// genIfaceConv converts an interface value to another interface type by
+// looking up the itable of its dynamic type.
+func genIfaceConv(node *Node) {
+	s := seq()
+	off := node.Var.Offset
+	gen(node.Lhs)
+	fmt.Printf("  pop rsi\n")
+	genTypeTest(node.Type, fmt.Sprintf(".L.nil.%d", s))
+	genAssertValue(node)
+	fmt.Printf("  jmp .L.end.%d\n", s)
+	fmt.Printf(".L.nil.%d:\n", s)
+	fmt.Printf("  mov qword ptr [rbp-%d], 0\n", off)
+	fmt.Printf("  mov qword ptr [rbp-%d], 0\n", off-8)
+	fmt.Printf("  lea rax, [rbp-%d]\n", off)
+	fmt.Printf(".L.end.%d:\n", s)
+	fmt.Printf("  push rax\n")
+}
+
+// genTypeTest jumps to fail unless the dynamic type of the interface value
+// pointed to by RSI is ty, or implements ty if it is an interface type, in
+// which case RAX is set to the itable for ty. RSI is preserved.
+func genTypeTest(ty *Type, fail string) {
+	fmt.Printf("  mov rdi, [rsi]\n")
+	fmt.Printf("  cmp rdi, 0\n")
+	fmt.Printf("  je %s\n", fail)
+	fmt.Printf("  mov rdi, [rdi]\n")
+	if ty.isInterface() {
+		fmt.Printf("  push rsi\n")
+		fmt.Printf("  mov rsi, offset %s\n", ifaceTable(ty))
+		call("runtime.findItab")
+		fmt.Printf("  pop rsi\n")
+		fmt.Printf("  cmp rax, 0\n")
+		fmt.Printf("  je %s\n", fail)
+		return
+	}
+	fmt.Printf("  mov rax, offset %s\n", typeDescOf(ty).Label)
+	fmt.Printf("  cmp rdi, rax\n")
+	fmt.Printf("  jne %s\n", fail)
+}
+
+// genAssertValue sets RAX to the value of the interface pointed to by RSI
+// as node.Type, after genTypeTest has succeeded.
+func genAssertValue(node *Node) {
+	if !node.Type.isInterface() {
+		fmt.Printf("  mov rax, [rsi+8]\n")
+		return
+	}
+	off := node.Var.Offset
+	fmt.Printf("  mov [rbp-%d], rax\n", off)
+	fmt.Printf("  mov rax, [rsi+8]\n")
+	fmt.Printf("  mov [rbp-%d], rax\n", off-8)
+	fmt.Printf("  lea rax, [rbp-%d]\n", off)
+}
+
+// genAssert generates the type assertion x.(T), which panics if it fails.
+// In the comma-ok form, it results in the zero value instead, and whether
+// it succeeded is stored to node.Ok.
+func genAssert(node *Node) {
+	s := seq()
+	if node.Ok != nil {
+		genAddr(node.Ok)
+	}
+	gen(node.Lhs)
+	fmt.Printf("  pop rsi\n")
+	genTypeTest(node.Type, fmt.Sprintf(".L.fail.%d", s))
+	genAssertValue(node)
+	fmt.Printf("  mov rdx, 1\n")
+	fmt.Printf("  jmp .L.end.%d\n", s)
+	fmt.Printf(".L.fail.%d:\n", s)
+	if node.Ok == nil {
+		msg := fmt.Sprintf("interface conversion: %s is %%s, not %s", node.Lhs.Type, node.Type)
+		fmt.Printf("  mov rdi, [rsi]\n")
+		fmt.Printf("  mov rsi, offset %s\n", cstring(msg))
+		call("runtime.panicAssert")
+	} else if node.Var != nil {
+		genZero(node.Var)
+		fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
+	} else {
+		fmt.Printf("  mov rax, 0\n")
+	}
+	fmt.Printf("  mov rdx, 0\n")
+	fmt.Printf(".L.end.%d:\n", s)
+	if node.Ok != nil {
+		fmt.Printf("  pop rdi\n")
+		fmt.Printf("  push rax\n")
+		fmt.Printf("  push rdi\n")
+		fmt.Printf("  push rdx\n")
+		store(node.Ok.Type)
+		fmt.Printf("  add rsp, 8\n")
+		return
+	}
+	fmt.Printf("  push rax\n")
+}
+
+// genTypeSwitch generates a type switch, which runs the first clause
+// listing the dynamic type of the interface value.
+func genTypeSwitch(node *Node) {
+	s := seq()
+	node.BrkLabel = fmt.Sprintf(".L.end.%d", s)
+	gen(node.Init)
+	gen(node.Lhs)
+	fmt.Printf("  pop rax\n")
+	dflt := fmt.Sprintf(".L.end.%d", s)
+	for i, c := range node.Body {
+		if c.Args == nil {
+			dflt = fmt.Sprintf(".L.case.%d.%d", s, i)
+		}
+		for _, a := range c.Args {
+			gen(a.Lhs)
+			fmt.Printf("  pop rsi\n")
+			if a.Type.isNil() {
+				fmt.Printf("  cmp qword ptr [rsi], 0\n")
+				fmt.Printf("  je .L.case.%d.%d\n", s, i)
+				continue
+			}
+			next := seq()
+			genTypeTest(a.Type, fmt.Sprintf(".L.next.%d", next))
+			fmt.Printf("  jmp .L.case.%d.%d\n", s, i)
+			fmt.Printf(".L.next.%d:\n", next)
+		}
+	}
+	fmt.Printf("  jmp %s\n", dflt)
+	for i, c := range node.Body {
+		fmt.Printf(".L.case.%d.%d:\n", s, i)
+		gen(c.Then)
+		fmt.Printf("  jmp .L.end.%d\n", s)
+	}
+	fmt.Printf(".L.end.%d:\n", s)
+}
+
+// genSwitch generates an expression switch. A switch on dense integer
+// literals jumps through a table indexed by the tag, and any other switch
+// tests the cases in order.
+func genSwitch(node *Node) {
+	s := seq()
+	node.BrkLabel = fmt.Sprintf(".L.end.%d", s)
+	dflt := node.BrkLabel
+	for i, c := range node.Body {
+		c.Label = fmt.Sprintf(".L.case.%d.%d", s, i)
+		if c.Args == nil {
+			dflt = c.Label
+		}
+	}
+	gen(node.Init)
+	if min, max, ok := denseCases(node); ok {
+		gen(node.Lhs)
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  sub rax, %d\n", min)
+		fmt.Printf("  cmp rax, %d\n", max-min)
+		fmt.Printf("  ja %s\n", dflt)
+		fmt.Printf("  mov rdi, offset .L.table.%d\n", s)
+		fmt.Printf("  jmp qword ptr [rdi+rax*8]\n")
+		fmt.Printf(".L.table.%d:\n", s)
+		labels := make(map[int]string)
+		for _, c := range node.Body {
+			for _, a := range c.Args {
+				labels[a.Rhs.Val] = c.Label
+			}
+		}
+		for v := min; v <= max; v++ {
+			if l, ok := labels[v]; ok {
+				fmt.Printf("  .quad %s\n", l)
+			} else {
+				fmt.Printf("  .quad %s\n", dflt)
+			}
+		}
+	} else {
+		if node.Lhs != nil {
+			gen(node.Lhs)
+			fmt.Printf("  add rsp, 8\n")
+		}
+		for _, c := range node.Body {
+			for _, a := range c.Args {
+				gen(a)
+				fmt.Printf("  pop rax\n")
+				fmt.Printf("  cmp rax, 0\n")
+				fmt.Printf("  jne %s\n", c.Label)
+			}
+		}
+		fmt.Printf("  jmp %s\n", dflt)
+	}
+	for _, c := range node.Body {
+		fmt.Printf("%s:\n", c.Label)
+		gen(c.Then)
+		fmt.Printf("  jmp %s\n", node.BrkLabel)
+	}
+	fmt.Printf("%s:\n", node.BrkLabel)
+}
+
+// denseCases returns the smallest and the largest cases of a switch on an
+// integer, if all the cases are integer literals filling at least half of
+// the range between them.
+func denseCases(node *Node) (int, int, bool) {
+	if node.Lhs == nil || !node.Lhs.Lhs.Type.isInteger() {
+		return 0, 0, false
+	}
+	n := 0
+	min, max := math.MaxInt32, math.MinInt32
+	for _, c := range node.Body {
+		for _, a := range c.Args {
+			v := a.Rhs
+			if v.Kind != ND_NUM || v.Val < math.MinInt32 || v.Val > math.MaxInt32 {
+				return 0, 0, false
+			}
+			if v.Val < min {
+				min = v.Val
+			}
+			if v.Val > max {
+				max = v.Val
+			}
+			n++
+		}
+	}
+	return min, max, n >= 4 && max-min < 2*n
+}
+
+// genOperand generates an operand of a comparison. An interface or a slice
+// is compared by its first word, which is nil only for the nil value.
+func genOperand(node *Node) {
+	gen(node)
+	if node.Type.isInterface() || node.Type.isSlice() {
+		fmt.Printf("  pop rax\n")
+		fmt.Printf("  push qword ptr [rax]\n")
+	}
+}
+
+// genString generates a binary operation on strings, which are
+// concatenated or compared by the runtime.
+func genString(node *Node) {
+	gen(node.Lhs)
+	gen(node.Rhs)
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  pop rdi\n")
+	if node.Kind == ND_ADD {
+		fmt.Printf("  mov rdx, rsi\n")
+		fmt.Printf("  mov rsi, rdi\n")
+		fmt.Printf("  lea rdi, [rbp-%d]\n", node.Var.Offset)
+		call("runtime.concatstring")
+		fmt.Printf("  lea rax, [rbp-%d]\n", node.Var.Offset)
+		fmt.Printf("  push rax\n")
+		return
+	}
+	call("runtime.cmpstring")
+	fmt.Printf("  cmp rax, 0\n")
+	switch node.Kind {
+	case ND_EQ:
+		fmt.Printf("  sete al\n")
+	case ND_NE:
+		fmt.Printf("  setne al\n")
+	case ND_LT:
+		fmt.Printf("  setl al\n")
+	case ND_LE:
+		fmt.Printf("  setle al\n")
+	}
+	fmt.Printf("  movzb rax, al\n")
+	fmt.Printf("  push rax\n")
+}
+
+// genIface generates a comparison of interface values, whose dynamic types
+// and values are compared by the runtime.
+func genIface(node *Node) {
+	gen(node.Lhs)
+	gen(node.Rhs)
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  pop rdi\n")
+	call("runtime.ifaceeq")
+	if node.Kind == ND_NE {
+		fmt.Printf("  xor rax, 1\n")
+	}
+	fmt.Printf("  push rax\n")
+}
+
+// genEqual generates a comparison of struct or array values, whose spans
+// are compared by the runtime as those of the boxed values of interfaces.
+func genEqual(node *Node) {
+	gen(node.Lhs)
+	gen(node.Rhs)
+	fmt.Printf("  pop rdx\n")
+	fmt.Printf("  pop rsi\n")
+	fmt.Printf("  mov rdi, offset %s\n", typeDescOf(node.Lhs.Type).Label)
+	call("runtime.equalkey")
+	if node.Kind == ND_NE {
+		fmt.Printf("  xor rax, 1\n")
+	}
+	fmt.Printf("  push rax\n")
+}
+
+func genBinary(node *Node) {
+	if node.Lhs.Type.isString() {
+		genString(node)
+		return
+	}
+	if node.Lhs.Type.isInterface() && node.Rhs.Type.isInterface() {
+		genIface(node)
+		return
+	}
+	if node.Lhs.Type.isStruct() || node.Lhs.Type.isArray() {
+		genEqual(node)
+		return
+	}
+	genOperand(node.Lhs)
+	genOperand(node.Rhs)
+
+	fmt.Printf("  pop rdi\n")
+	fmt.Printf("  pop rax\n")
+
+	switch node.Kind {
+	case ND_ADD:
+		fmt.Printf("  add rax, rdi\n")
+	case ND_SUB:
+		fmt.Printf("  sub rax, rdi\n")
+	case ND_MUL:
+		fmt.Printf("  imul rax, rdi\n")
+	case ND_DIV:
+		// Dividing the most negative integer by -1 overflows to
+		// itself rather than trapping.
+		s := seq()
+		fmt.Printf("  cmp rdi, 0\n")
+		fmt.Printf("  jne .L.divisor.%d\n", s)
+		fmt.Printf("  call runtime.panicdivide\n")
+		fmt.Printf(".L.divisor.%d:\n", s)
+		fmt.Printf("  cmp rdi, -1\n")
+		fmt.Printf("  jne .L.div.%d\n", s)
+		fmt.Printf("  neg rax\n")
+		fmt.Printf("  jmp .L.quotient.%d\n", s)
+		fmt.Printf(".L.div.%d:\n", s)
+		fmt.Printf("  cqo\n")
+		fmt.Printf("  idiv rdi\n")
+		fmt.Printf(".L.quotient.%d:\n", s)
+	}
+
+	// The result of narrow integers wraps around as it is computed in
+	// 64 bits.
+	switch node.Kind {
+	case ND_ADD, ND_SUB, ND_MUL, ND_DIV:
+		switch node.Type.size() {
+		case 1:
+			fmt.Printf("  movzx eax, al\n")
+		case 4:
+			fmt.Printf("  movsxd rax, eax\n")
+		}
 	case ND_EQ:
 		fmt.Printf("  cmp rax, rdi\n")
 		fmt.Printf("  sete al\n")
@@ -0,0 +1,265 @@
+package main
+
+// The escape analysis decides which local variables are allocated on the
+// heap. The address of a variable escapes if it may be used after the
+// function returns, or after the iteration of the loop in which the
+// variable is declared, as a new instance of it is created in each one.
+// The body of a function literal is analyzed along with the function it is
+// in, as if it were the body of a loop, and the variables it captures are
+// those of the enclosing function.
+
+// A flow is an assignment of a value to a local variable, or to a part of it.
+type flow struct {
+	Dst *Var
+	Src *Node
+}
+
+type escapeState struct {
+	depth   map[*Var]int // The loop depth of the declaration of each variable
+	flows   []flow
+	leaks   []*Node // The values stored where they outlive the function
+	escaped map[*Var]bool
+}
+
+// escape moves the local variables of the function fn to the heap if their
+// addresses escape. The addresses escape if they are returned, passed to a
+// function, stored anywhere but in a local variable, or stored in a local
+// variable declared out of the loop of the variable or whose value escapes.
+func escape(fn *Node) {
+	e := &escapeState{
+		depth:   make(map[*Var]int),
+		escaped: make(map[*Var]bool),
+	}
+	e.scan(fn.Block, 0)
+	if fn.Result != nil {
+		e.leaks = append(e.leaks, newVarNode(fn.Result))
+	}
+	for _, f := range e.flows {
+		d := e.depth[f.Dst]
+		sources(f.Src, func(v *Var, addr bool) {
+			if e.depth[v] <= d {
+				return
+			}
+			if addr {
+				e.escapeAddr(v)
+			} else {
+				e.escapeValue(v)
+			}
+		})
+	}
+	for _, n := range e.leaks {
+		e.leak(n)
+	}
+}
+
+// scan records the loop depths of the declarations, the flows and the
+// leaks in n, which is at the loop depth.
+func (e *escapeState) scan(n *Node, depth int) {
+	if n == nil {
+		return
+	}
+	inner := depth
+	if n.Kind == ND_FOR || n.Kind == ND_RANGE || n.Kind == ND_CLOSURE {
+		inner++
+	}
+	for _, v := range n.Vars {
+		// The variables declared in the header of a loop are new in
+		// each iteration.
+		if n.Kind == ND_RANGE || n.Kind == ND_FOR {
+			e.depth[v] = inner
+		} else {
+			e.depth[v] = depth
+		}
+	}
+
+	switch n.Kind {
+	case ND_COMPLIT, ND_NEW:
+		e.depth[n.Var] = depth
+	case ND_CLOSURE:
+		if n.Var != nil {
+			e.depth[n.Var] = depth
+		}
+		if fn := n.Func; fn != nil {
+			for _, a := range fn.Args {
+				e.depth[a.Var] = inner
+			}
+			if fn.Result != nil {
+				e.depth[fn.Result] = inner
+				e.leaks = append(e.leaks, newVarNode(fn.Result))
+			}
+			e.scan(fn.Block, inner)
+		}
+	case ND_RETURN:
+		e.leaks = append(e.leaks, n.Lhs)
+	case ND_PANIC:
+		e.leaks = append(e.leaks, n.Lhs)
+	case ND_SEND:
+		// The value is copied to the channel.
+		e.leaks = append(e.leaks, n.Rhs)
+	case ND_DEFER, ND_GO:
+		// The closure outlives the statement.
+		e.leaks = append(e.leaks, n.Lhs)
+	case ND_ASSIGN:
+		if v := localBase(n.Lhs); v != nil {
+			e.flows = append(e.flows, flow{v, n.Rhs})
+		} else {
+			e.leaks = append(e.leaks, n.Rhs)
+		}
+	case ND_RANGE:
+		// The key and the element are taken from the range expression.
+		e.flows = append(e.flows, flow{n.Lhs.Var, n.Cond})
+		if n.Rhs != nil {
+			e.flows = append(e.flows, flow{n.Rhs.Var, n.Cond})
+		}
+	case ND_FUNCALL, ND_APPEND:
+		e.leaks = append(e.leaks, n.Args...)
+		e.leaks = append(e.leaks, n.Rhs)
+	case ND_ICALL:
+		e.leaks = append(e.leaks, n.Args...)
+		e.leaks = append(e.leaks, n.Lhs)
+	case ND_COPY:
+		e.leaks = append(e.leaks, n.Rhs)
+	}
+
+	switch n.Kind {
+	case ND_FOR:
+		e.scan(n.Init, depth)
+		e.scan(n.Cond, inner)
+		e.scan(n.Then, inner)
+		e.scan(n.Inc, inner)
+		return
+	case ND_RANGE:
+		e.scan(n.Init, depth)
+		e.scan(n.Cond, depth)
+		for _, c := range n.Body {
+			e.scan(c, inner)
+		}
+		e.scan(n.Then, inner)
+		return
+	}
+	for _, c := range []*Node{n.Lhs, n.Rhs, n.Cond, n.Then, n.Els, n.Init, n.Inc, n.Ok} {
+		e.scan(c, depth)
+	}
+	for _, c := range n.Body {
+		e.scan(c, depth)
+	}
+	for _, c := range n.Args {
+		e.scan(c, depth)
+	}
+}
+
+// leak makes the variables used by the value of n escape.
+func (e *escapeState) leak(n *Node) {
+	sources(n, func(v *Var, addr bool) {
+		if addr {
+			e.escapeAddr(v)
+		} else {
+			e.escapeValue(v)
+		}
+	})
+}
+
+// escapeAddr moves the variable v to the heap. Its value then escapes as
+// well, as it can be referred to through the address.
+func (e *escapeState) escapeAddr(v *Var) {
+	if v.OnHeap {
+		return
+	}
+	v.OnHeap = true
+	e.escapeValue(v)
+}
+
+// escapeValue makes the values assigned to the variable v escape.
+func (e *escapeState) escapeValue(v *Var) {
+	if e.escaped[v] {
+		return
+	}
+	e.escaped[v] = true
+	for _, f := range e.flows {
+		if f.Dst == v {
+			e.leak(f.Src)
+		}
+	}
+}
+
+// sources calls f for each local variable whose address, if addr is true,
+// or value is used by the value of n. The values passed to functions are
+// not followed, as they escape anyway.
+func sources(n *Node, f func(v *Var, addr bool)) {
+	if n == nil {
+		return
+	}
+	switch n.Kind {
+	case ND_VAR:
+		if n.Var.IsLocal {
+			f(outermost(n.Var), false)
+		}
+		return
+	case ND_COMPLIT:
+		f(n.Var, false)
+		return
+	case ND_CLOSURE:
+		if n.Var != nil {
+			f(n.Var, true)
+		}
+		return
+	case ND_NEW:
+		f(n.Var, true)
+		return
+	case ND_ADDR:
+		if v := localBase(n.Lhs); v != nil {
+			f(v, true)
+			return
+		}
+	case ND_SLICE:
+		if v := localBase(n.Lhs); v != nil && n.Lhs.Type.isArray() {
+			f(v, true)
+			return
+		}
+	case ND_FUNCALL, ND_ICALL:
+		return
+	}
+	for _, c := range []*Node{n.Lhs, n.Rhs, n.Cond, n.Then, n.Els, n.Init, n.Inc, n.Ok} {
+		sources(c, f)
+	}
+	for _, c := range n.Body {
+		sources(c, f)
+	}
+	for _, c := range n.Args {
+		sources(c, f)
+	}
+}
+
+// localBase returns the local variable of which n is a part, or nil if n
+// is not a part of a local variable.
+func localBase(n *Node) *Var {
+	for {
+		switch n.Kind {
+		case ND_VAR:
+			if !n.Var.IsLocal {
+				return nil
+			}
+			return outermost(n.Var)
+		case ND_COMPLIT:
+			return n.Var
+		case ND_MEMBER:
+			n = n.Lhs
+		case ND_INDEX:
+			if !n.Lhs.Type.isArray() {
+				return nil
+			}
+			n = n.Lhs
+		default:
+			return nil
+		}
+	}
+}
+
+// outermost returns the variable which v refers to, which is v itself unless
+// it is captured by a function literal.
+func outermost(v *Var) *Var {
+	for v.Outer != nil {
+		v = v.Outer
+	}
+	return v
+}
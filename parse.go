@@ -1,58 +1,131 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
 
 // NodeKind is a type for the kind of Node
 type NodeKind int
 
 const (
-	ND_ADD     NodeKind = iota // +
-	ND_SUB                     // -
-	ND_MUL                     // *
-	ND_DIV                     // /
-	ND_ASSIGN                  // =
-	ND_VAR                     // variable
-	ND_EQ                      // ==
-	ND_NE                      // !=
-	ND_LT                      // <
-	ND_LE                      // <=
-	ND_INC                     // ++
-	ND_DEC                     // --
-	ND_NUM                     // number
-	ND_RETURN                  // return
-	ND_IF                      // if
-	ND_FOR                     // for
-	ND_BLOCK                   // { ... }
-	ND_FUNCALL                 // Function call
-	ND_FUNC                    // Function
-	ND_ADDR                    // &
-	ND_DEREF                   // *
-	ND_INDEX                   // x[y]
+	ND_ADD         NodeKind = iota // +
+	ND_SUB                         // -
+	ND_MUL                         // *
+	ND_DIV                         // /
+	ND_ASSIGN                      // =
+	ND_VAR                         // variable
+	ND_EQ                          // ==
+	ND_NE                          // !=
+	ND_LT                          // <
+	ND_LE                          // <=
+	ND_INC                         // ++
+	ND_DEC                         // --
+	ND_NUM                         // number
+	ND_RETURN                      // return
+	ND_IF                          // if
+	ND_FOR                         // for
+	ND_BLOCK                       // { ... }
+	ND_FUNCALL                     // Function call
+	ND_FUNC                        // Function
+	ND_ADDR                        // &
+	ND_DEREF                       // *
+	ND_INDEX                       // x[y]
+	ND_CONV                        // T(x)
+	ND_MEMBER                      // x.y
+	ND_NIL                         // nil
+	ND_ICALL                       // Interface method call
+	ND_ASSERT                      // x.(T)
+	ND_TYPESWITCH                  // switch x.(type)
+	ND_CASE                        // case
+	ND_SLICE                       // x[lo:hi:max]
+	ND_LEN                         // len(x)
+	ND_CAP                         // cap(x)
+	ND_APPEND                      // append(s, x...)
+	ND_COPY                        // copy(dst, src)
+	ND_STR                         // string literal
+	ND_MAKE                        // make(T, n)
+	ND_DELETE                      // delete(m, k)
+	ND_MAPLIT                      // map literal
+	ND_RANGE                       // for range
+	ND_BREAK                       // break
+	ND_CONTINUE                    // continue
+	ND_GOTO                        // goto
+	ND_LABEL                       // labeled statement
+	ND_SWITCH                      // switch
+	ND_FALLTHROUGH                 // fallthrough
+	ND_COMPLIT                     // composite literal
+	ND_NEW                         // new(T)
+	ND_CLOSURE                     // function value
+	ND_DEFER                       // defer
+	ND_PANIC                       // panic(v)
+	ND_RECOVER                     // recover()
+	ND_GO                          // go
+	ND_SEND                        // ch <- v
+	ND_RECV                        // <-ch
+	ND_CLOSE                       // close(ch)
+	ND_SELECT                      // select
 )
 
 var nodeKindName = map[NodeKind]string{
-	ND_ADD:     "ND_ADD",
-	ND_SUB:     "ND_SUB",
-	ND_MUL:     "ND_MUL",
-	ND_DIV:     "ND_DIV",
-	ND_ASSIGN:  "ND_ASSIGN",
-	ND_VAR:     "ND_LVAR",
-	ND_EQ:      "ND_EQ",
-	ND_NE:      "ND_NE",
-	ND_LT:      "ND_LT",
-	ND_LE:      "ND_LE",
-	ND_INC:     "ND_INC",
-	ND_DEC:     "ND_DEC",
-	ND_NUM:     "ND_NUM",
-	ND_RETURN:  "ND_RETURN",
-	ND_IF:      "ND_IF",
-	ND_FOR:     "ND_FOR",
-	ND_BLOCK:   "ND_BLOCK",
-	ND_FUNCALL: "ND_FUNCALL",
-	ND_FUNC:    "ND_FUNC",
-	ND_ADDR:    "ND_ADDR",
-	ND_DEREF:   "ND_DEREF",
-	ND_INDEX:   "ND_INDEX",
+	ND_ADD:         "ND_ADD",
+	ND_SUB:         "ND_SUB",
+	ND_MUL:         "ND_MUL",
+	ND_DIV:         "ND_DIV",
+	ND_ASSIGN:      "ND_ASSIGN",
+	ND_VAR:         "ND_LVAR",
+	ND_EQ:          "ND_EQ",
+	ND_NE:          "ND_NE",
+	ND_LT:          "ND_LT",
+	ND_LE:          "ND_LE",
+	ND_INC:         "ND_INC",
+	ND_DEC:         "ND_DEC",
+	ND_NUM:         "ND_NUM",
+	ND_RETURN:      "ND_RETURN",
+	ND_IF:          "ND_IF",
+	ND_FOR:         "ND_FOR",
+	ND_BLOCK:       "ND_BLOCK",
+	ND_FUNCALL:     "ND_FUNCALL",
+	ND_FUNC:        "ND_FUNC",
+	ND_ADDR:        "ND_ADDR",
+	ND_DEREF:       "ND_DEREF",
+	ND_INDEX:       "ND_INDEX",
+	ND_CONV:        "ND_CONV",
+	ND_MEMBER:      "ND_MEMBER",
+	ND_NIL:         "ND_NIL",
+	ND_ICALL:       "ND_ICALL",
+	ND_ASSERT:      "ND_ASSERT",
+	ND_TYPESWITCH:  "ND_TYPESWITCH",
+	ND_CASE:        "ND_CASE",
+	ND_SLICE:       "ND_SLICE",
+	ND_LEN:         "ND_LEN",
+	ND_CAP:         "ND_CAP",
+	ND_APPEND:      "ND_APPEND",
+	ND_COPY:        "ND_COPY",
+	ND_STR:         "ND_STR",
+	ND_MAKE:        "ND_MAKE",
+	ND_DELETE:      "ND_DELETE",
+	ND_MAPLIT:      "ND_MAPLIT",
+	ND_RANGE:       "ND_RANGE",
+	ND_BREAK:       "ND_BREAK",
+	ND_CONTINUE:    "ND_CONTINUE",
+	ND_GOTO:        "ND_GOTO",
+	ND_LABEL:       "ND_LABEL",
+	ND_SWITCH:      "ND_SWITCH",
+	ND_FALLTHROUGH: "ND_FALLTHROUGH",
+	ND_COMPLIT:     "ND_COMPLIT",
+	ND_NEW:         "ND_NEW",
+	ND_CLOSURE:     "ND_CLOSURE",
+	ND_DEFER:       "ND_DEFER",
+	ND_PANIC:       "ND_PANIC",
+	ND_RECOVER:     "ND_RECOVER",
+	ND_GO:          "ND_GO",
+	ND_SEND:        "ND_SEND",
+	ND_RECV:        "ND_RECV",
+	ND_CLOSE:       "ND_CLOSE",
+	ND_SELECT:      "ND_SELECT",
 }
 
 func (nk NodeKind) String() string {
@@ -67,6 +140,11 @@ type Node struct {
 	Rhs  *Node    // right-hand side
 	Val  int      // The value of ND_NUM
 
+	// Constants. The value of an integer constant which does not fit in
+	// Val is held in Const, and a typed constant keeps its type.
+	Const *big.Int
+	Typed bool
+
 	// "if" and "for"
 	Cond *Node
 	Then *Node
@@ -77,14 +155,44 @@ type Node struct {
 	// block
 	Body []*Node
 
+	// The local variables declared in a block, or in the header of an
+	// "if", "for" or "switch" statement, or those captured by a function
+	// literal
+	Vars []*Var
+
+	// "break", "continue", "goto" and "fallthrough" jump to the assembly
+	// labels of their target. Those of a loop, a switch or a case clause
+	// are set when it is generated.
+	Target    *Node
+	Label     string // The label of a labeled statement or a case clause
+	BrkLabel  string
+	ContLabel string
+
 	// function
 	FunctionName string
 	Args         []*Node
+	TypeArgs     []*Type // The explicit type arguments of a call of a generic function
 	Locals       *VarList
 	Block        *Node
+	Result       *Var // The named result
+	HasDefer     bool // Whether the function has defer statements
+
+	// The function of a function literal
+	Func *Node
 
 	// var
 	Var *Var
+
+	// struct member
+	Member *Field
+
+	// Conversion to an interface
+	Itab *Itab
+
+	// The variable receiving whether the value was obtained, in the
+	// comma-ok form of a type assertion, a map index expression or a
+	// receive
+	Ok *Node
 }
 
 func newNode(kind NodeKind, lhs *Node, rhs *Node) *Node {
@@ -104,8 +212,54 @@ func newNodeNum(val int) *Node {
 	return node
 }
 
+// newNodeConst returns the node of the integer constant v.
+func newNodeConst(v *big.Int) *Node {
+	node := newNodeNum(int(v.Int64()))
+	if !v.IsInt64() {
+		node.Const = v
+	}
+	return node
+}
+
+// clone returns a copy of the node of a constant.
+func (n *Node) clone() *Node {
+	c := *n
+	return &c
+}
+
 var code []*Node
 
+// typedef is a declared type name. Package-level types are registered
+// before any declaration is parsed, so that they can be used before they are
+// declared, and are resolved when they are first needed.
+type typedef struct {
+	Name    string
+	Type    *Type
+	Tok     *Token // The type spec of a package-level type
+	Alias   bool
+	Generic *generic // The generic type, which is instantiated rather than resolved
+	Param   bool     // Whether it is a type parameter standing for its type argument
+
+	resolved  bool
+	resolving bool
+}
+
+var typedefs map[string]*typedef // Package-level types
+var funcs map[string]*Node       // Function declarations
+var curFunc *Node                // The function being parsed
+
+// indirect is positive while parsing a type that is only referred to
+// through a pointer, where a type may refer to itself.
+var indirect int
+
+// discovering is set while skimming the declarations of a package, when
+// the types they refer to are not known yet.
+var discovering bool
+
+// funcsDeclared is set once the functions of a package are declared, after
+// which a call of an undeclared function is an error.
+var funcsDeclared bool
+
 type Labeler struct {
 	Counter int
 }
@@ -118,6 +272,8 @@ func (l *Labeler) New() string {
 
 var labeler = &Labeler{}
 
+var literals []*Var // String literals
+
 func assign() *Node {
 	node := equality()
 	if consume("=") || consume(":=") {
@@ -133,17 +289,36 @@ func expr() *Node {
 func stmt() *Node {
 	var node *Node
 
-	if consume("return") {
-		node = &Node{
-			Kind: ND_RETURN,
-			Lhs:  equality(),
+	if tok := token; consume("return") {
+		node = &Node{Kind: ND_RETURN}
+		if !peek(";") && !peek("}") {
+			node.Lhs = equality()
 		}
+		switch {
+		case curFunc == nil:
+		case curFunc.Type == nil && node.Lhs != nil:
+			tok.errorf("too many return values")
+		case curFunc.Type != nil && node.Lhs == nil && curFunc.Result == nil:
+			tok.errorf("not enough return values")
+		case node.Lhs == nil:
+		case curFunc.Type != nil:
+			node.Lhs = convert(node.Lhs, curFunc.Type, "return argument")
+		}
+	} else if tok := token; consume("defer") {
+		curFunc.HasDefer = true
+		node = callStmt(tok, ND_DEFER)
+	} else if tok := token; consume("go") {
+		node = callStmt(tok, ND_GO)
 	} else if consume("if") {
 		node = ifstmt()
 	} else if consume("for") {
+		label := takeLabel()
+		enterScope()
 		node = &Node{Kind: ND_FOR}
 		if consume("{") { // for {}
-			node.Then = block()
+			node.Then = loopBody(node, label)
+		} else if r := rangeClause(label); r != nil { // for k, v := range x {}
+			node = r
 		} else {
 			unknown := expr()
 			if consume(";") { // for i=0;i<N;i++ {}
@@ -155,27 +330,217 @@ func stmt() *Node {
 				node.Cond = unknown
 			}
 			expect("{")
-			node.Then = block()
+			node.Then = loopBody(node, label)
 		}
+		node.Vars = scopeVars()
+		leaveScope()
+	} else if consume("switch") {
+		node = switchStmt()
+	} else if consume("select") {
+		node = selectStmt()
 	} else if consume("{") {
 		node = block()
 	} else if consume("var") {
 		tok := expectIdent()
-		lvar := tok.findLVar()
-		if lvar != nil {
-			panic(fmt.Sprintf("%s redeclared in this block", tok.str))
-		}
-
+		checkRedeclared(tok)
 		node = newLVarNode(tok.str, parseType())
+	} else if peek("type") {
+		typeDecl(localTypeSpec)
+		consume(";")
+		return &Node{Kind: ND_BLOCK}
+	} else if peek("const") {
+		for _, def := range constDecl() {
+			checkRedeclared(def.Name)
+			scope.Vars[def.Name.str] = &Var{Name: def.Name.str, Const: def.value()}
+		}
+		consume(";")
+		return &Node{Kind: ND_BLOCK}
+	} else if peek("fallthrough") {
+		token.errorf("fallthrough statement out of place")
+	} else if peek("break") || peek("continue") || peek("goto") {
+		node = branchStmt()
+	} else if token.kind == TK_IDENT && token.next.isReserved() && token.next.str == ":" {
+		node = labeledStmt()
+	} else if token.kind == TK_IDENT && token.next.str == "," {
+		node = commaOk()
 	} else {
 		node = expr()
+		if consume("<-") {
+			node = &Node{Kind: ND_SEND, Lhs: node, Rhs: expr()}
+		}
 	}
 
 	consume(";")
 	return node
 }
 
+// A breakable is a statement enclosing the statement being parsed, which
+// break statements, and continue statements if it is a loop, can leave.
+type breakable struct {
+	Node  *Node
+	Label string
+}
+
+var breakables []breakable
+
+// A labelDecl is a labeled statement of the function being parsed.
+type labelDecl struct {
+	Tok   *Token
+	Node  *Node
+	Scope *Scope // The block of the statement
+	Decls int    // The number of variables declared in the block before it
+	Used  bool
+}
+
+// A jump is a goto statement, which is checked at the end of the function.
+type jump struct {
+	Tok   *Token // The goto keyword
+	Name  *Token // The label
+	Node  *Node
+	Decls map[*Scope]int // The number of variables declared in the enclosing blocks
+}
+
+var labels []*labelDecl
+var jumps []*jump
+var pendingLabel string // The label of the for or switch statement to be parsed
+
+// takeLabel returns the label of the for or switch statement being parsed,
+// or "" if it is not labeled.
+func takeLabel() string {
+	l := pendingLabel
+	pendingLabel = ""
+	return l
+}
+
+func findLabel(name string) *labelDecl {
+	for _, l := range labels {
+		if l.Tok.str == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// loopBody parses the body of the loop node with the label, which break and
+// continue statements in it can leave.
+func loopBody(node *Node, label string) *Node {
+	breakables = append(breakables, breakable{node, label})
+	body := block()
+	breakables = breakables[:len(breakables)-1]
+	return body
+}
+
+// labeledStmt parses a labeled statement.
+func labeledStmt() *Node {
+	tok := expectIdent()
+	expect(":")
+	if l := findLabel(tok.str); l != nil {
+		tok.errorf("label %s already defined at %s", tok.str, l.Tok.position())
+	}
+	node := &Node{
+		Kind:  ND_LABEL,
+		Label: fmt.Sprintf(".L.label.%s.%s", curFunc.FunctionName, tok.str),
+	}
+	labels = append(labels, &labelDecl{Tok: tok, Node: node, Scope: scope, Decls: len(scope.Decls)})
+	if peek("}") {
+		return node
+	}
+	if peek("for") || peek("switch") {
+		pendingLabel = tok.str
+	}
+	node.Lhs = stmt()
+	return node
+}
+
+// branchStmt parses a break, continue or goto statement. A break or
+// continue statement leaves the innermost enclosing statement, or the one
+// with the label.
+func branchStmt() *Node {
+	tok := token
+	token = token.next
+	name := consumeIdent()
+	if tok.str == "goto" {
+		if name == nil {
+			expectIdent()
+		}
+		node := &Node{Kind: ND_GOTO}
+		j := &jump{Tok: tok, Name: name, Node: node, Decls: make(map[*Scope]int)}
+		for sc := scope; sc != nil; sc = sc.Parent {
+			j.Decls[sc] = len(sc.Decls)
+		}
+		jumps = append(jumps, j)
+		return node
+	}
+
+	node := &Node{Kind: ND_BREAK}
+	if tok.str == "continue" {
+		node.Kind = ND_CONTINUE
+	}
+	for i := len(breakables) - 1; i >= 0; i-- {
+		b := breakables[i]
+		if name != nil && b.Label != name.str {
+			continue
+		}
+		if node.Kind == ND_CONTINUE && b.Node.Kind != ND_FOR && b.Node.Kind != ND_RANGE {
+			if name != nil {
+				name.errorf("invalid continue label %s", name.str)
+			}
+			continue
+		}
+		if name != nil {
+			findLabel(name.str).Used = true
+		}
+		node.Target = b.Node
+		return node
+	}
+	switch {
+	case name != nil && findLabel(name.str) != nil:
+		name.errorf("invalid %s label %s", tok.str, name.str)
+	case name != nil:
+		name.errorf("%s label not defined: %s", tok.str, name.str)
+	case node.Kind == ND_BREAK:
+		tok.errorf("break is not in a loop, switch, or select")
+	}
+	tok.errorf("continue is not in a loop")
+	return nil
+}
+
+// resolveJumps resolves the goto statements of the function to their
+// labels. A goto statement may not jump into a block, or over a variable
+// declaration in the block of the label.
+func resolveJumps() {
+	for _, j := range jumps {
+		l := findLabel(j.Name.str)
+		if l == nil {
+			j.Name.errorf("label %s not defined", j.Name.str)
+		}
+		l.Used = true
+		n, ok := j.Decls[l.Scope]
+		if !ok {
+			j.Tok.errorf("goto %s jumps into block starting at %s", j.Name.str, l.Scope.Start.position())
+		}
+		if n < l.Decls {
+			j.Tok.errorf("goto %s jumps over declaration of %s", j.Name.str, l.Scope.Decls[n])
+		}
+		j.Node.Target = l.Node
+	}
+	for _, l := range labels {
+		if !l.Used {
+			l.Tok.errorf("label %s defined and not used", l.Tok.str)
+		}
+	}
+}
+
+// checkRedeclared panics if the token names something declared in the current block.
+func checkRedeclared(tok *Token) {
+	if scope.Vars[tok.str] != nil || scope.Types[tok.str] != nil {
+		panic(fmt.Sprintf("%s redeclared in this block", tok.str))
+	}
+}
+
 func ifstmt() *Node {
+	enterScope()
+	defer leaveScope()
 	node := &Node{Kind: ND_IF}
 	unknown := expr()
 	if consume(";") { // if i:=0; i<N {}
@@ -186,6 +551,7 @@ func ifstmt() *Node {
 	}
 	expect("{")
 	node.Then = block()
+	node.Vars = scopeVars()
 	if consume("else") {
 		if consume("if") {
 			node.Els = ifstmt()
@@ -197,151 +563,2266 @@ func ifstmt() *Node {
 	return node
 }
 
-func block() *Node {
-	node := &Node{Kind: ND_BLOCK}
-	for !consume("}") {
-		node.Body = append(node.Body, stmt())
+// commaOk parses an assignment of a value and whether it was obtained, as
+// in v, ok := x.(T). The variables are declared with := unless they are
+// already declared in the current block.
+func commaOk() *Node {
+	tok := token
+	lhs := []*Token{expectIdent()}
+	expect(",")
+	lhs = append(lhs, expectIdent())
+	var vars [2]*Node
+	if consume(":=") {
+		rhs := equality()
+		rhs.addType()
+		tys := [2]*Type{rhs.Type, intType}
+		declared := false
+		for i, t := range lhs {
+			switch {
+			case t.str == "_":
+				vars[i] = newVarNode(newTemp(tys[i]))
+			case scope.Vars[t.str] != nil:
+				vars[i] = newVarNode(scope.Vars[t.str])
+			default:
+				vars[i] = newLVarNode(t.str, tys[i])
+				declared = true
+			}
+		}
+		if !declared {
+			panic("no new variables on left side of :=")
+		}
+		return withOk(vars, rhs)
 	}
-	return node
+	token = tok
+	for i := range vars {
+		if token.str == "_" {
+			vars[i] = &Node{Kind: ND_VAR}
+			token = token.next
+		} else {
+			vars[i] = postfix()
+		}
+		consume(",")
+	}
+	expect("=")
+	rhs := equality()
+	rhs.addType()
+	for i, ty := range []*Type{rhs.Type, intType} {
+		if vars[i].Kind == ND_VAR && vars[i].Var == nil {
+			vars[i].Var = newTemp(ty)
+			vars[i].Type = ty
+		}
+	}
+	return withOk(vars, rhs)
 }
 
-func program() {
-	globals = make(map[string]*Var)
-	for !token.atEof() {
-		switch token.str {
-		case "func":
-			function()
-		case "var":
-			gvar()
-		default:
-			panic(fmt.Sprintf("expected declaration, found %s", token.str))
-		}
-		consume(";")
+// withOk returns the assignment of the comma-ok form of rhs to vars.
+func withOk(vars [2]*Node, rhs *Node) *Node {
+	if rhs.Kind != ND_ASSERT && rhs.Kind != ND_RECV && !isMapIndex(rhs) {
+		panic("assignment mismatch: 2 variables but 1 value")
+	}
+	ok := vars[1]
+	ok.addType()
+	if !ok.Type.isInteger() {
+		panic(fmt.Sprintf("cannot use untyped bool value as type %s in assignment", ok.Type))
 	}
+	rhs.Ok = ok
+	return newNode(ND_ASSIGN, vars[0], rhs)
 }
 
-func function() {
-	expect("func")
-	locals = nil
-	tok := expectIdent()
+// rangeClause parses the range clause of a for statement,
+// [k [, v] (:= | =)] range x, if it follows. Otherwise the token is left
+// unread. The iteration variables are assigned the key and the element of
+// each iteration from temporaries. The variables declared by the clause are
+// new in each iteration.
+func rangeClause(label string) *Node {
+	isRange := false
+	for t := token; !t.atEof() && !(t.isReserved() && (t.str == "{" || t.str == ";")); t = t.next {
+		if t.isReserved() && t.str == "range" {
+			isRange = true
+			break
+		}
+	}
+	if !isRange {
+		return nil
+	}
+
+	var vars []*Token
+	var lhs []*Node
+	if !peek("range") {
+		start := token
+		for {
+			vars = append(vars, expectIdent())
+			if !consume(",") {
+				break
+			}
+		}
+		if !consume(":=") {
+			token = start
+			vars = nil
+			for {
+				if token.str == "_" {
+					lhs = append(lhs, nil)
+					token = token.next
+				} else {
+					lhs = append(lhs, postfix())
+				}
+				if !consume(",") {
+					break
+				}
+			}
+			expect("=")
+		}
+	}
+	n := len(vars) + len(lhs)
+	if n > 2 {
+		panic("range clause permits at most two iteration variables")
+	}
+	expect("range")
+	x := expr()
+	x.addType()
+	key, elem := rangeTypes(x)
+	if elem == nil && n > 1 {
+		panic(fmt.Sprintf("range over %s permits only one iteration variable", x.Type))
+	}
+
 	node := &Node{
-		Kind:         ND_FUNC,
-		FunctionName: tok.str,
-		Args:         definedArgs(),
+		Kind: ND_RANGE,
+		Cond: x,
+		Var:  newTemp(arrayOf(intType, 4)),
+		Lhs:  newVarNode(newTemp(key)),
+	}
+	if n > 1 {
+		node.Rhs = newVarNode(newTemp(elem))
+		if x.Type.isArray() {
+			// The elements are those of a copy of the array.
+			a := newVarNode(newTemp(x.Type))
+			node.Init = newNode(ND_ASSIGN, a, x)
+			node.Init.addType()
+			node.Cond = a
+		}
 	}
-	if !consume("{") {
-		node.Type = parseType()
-		expect("{")
+	vals := []*Node{node.Lhs, node.Rhs}
+	for i, t := range vars {
+		if t.str != "_" {
+			lhs = append(lhs, newLVarNode(t.str, vals[i].Type))
+		} else {
+			lhs = append(lhs, nil)
+		}
 	}
-	node.Block = block()
-	node.Locals = locals
-	node.addType()
-	code = append(code, node)
+	for i, v := range lhs {
+		if v != nil {
+			a := newNode(ND_ASSIGN, v, vals[i])
+			a.addType()
+			node.Body = append(node.Body, a)
+		}
+	}
+
+	expect("{")
+	node.Then = loopBody(node, label)
+	return node
 }
 
-func gvar() {
-	expect("var")
-	tok := expectIdent()
-	if globals[tok.str] != nil {
-		panic(fmt.Sprintf("%s redeclared in this block", tok.str))
+// walk calls f for n and each node below it.
+func walk(n *Node, f func(*Node)) {
+	if n == nil {
+		return
+	}
+	f(n)
+	for _, c := range []*Node{n.Lhs, n.Rhs, n.Cond, n.Then, n.Els, n.Init, n.Inc, n.Ok} {
+		walk(c, f)
+	}
+	for _, c := range n.Body {
+		walk(c, f)
+	}
+	for _, c := range n.Args {
+		walk(c, f)
 	}
-	gvar := newGVar(tok.str, parseType())
-	globals[gvar.Name] = gvar
 }
 
-func equality() *Node {
-	node := relational()
-
-	for {
-		if consume("==") {
-			node = newNode(ND_EQ, node, relational())
-		} else if consume("!=") {
-			node = newNode(ND_NE, node, relational())
-		} else {
+// switchStmt parses a switch statement, which is a type switch if it has a
+// type switch guard and an expression switch otherwise.
+func switchStmt() *Node {
+	label := takeLabel()
+	enterScope()
+	defer leaveScope()
+	var init, tag *Node
+	if !peek("{") {
+		if bind, guard := typeSwitchGuard(); guard != nil {
+			node := typeSwitch(label, nil, bind, guard)
+			node.Vars = scopeVars()
 			return node
 		}
+		tag = expr()
+		if consume(";") {
+			init, tag = tag, nil
+			if !peek("{") {
+				if bind, guard := typeSwitchGuard(); guard != nil {
+					node := typeSwitch(label, init, bind, guard)
+					node.Vars = scopeVars()
+					return node
+				}
+				tag = expr()
+			}
+		}
 	}
+	node := exprSwitch(label, init, tag)
+	node.Vars = scopeVars()
+	return node
 }
 
-func relational() *Node {
-	node := add()
+// exprSwitch parses the clauses of an expression switch. Each case is
+// compared with the tag, and a switch without a tag runs the first clause
+// with a true case.
+func exprSwitch(label string, init, tag *Node) *Node {
+	node := &Node{Kind: ND_SWITCH, Init: init}
+	var tmp *Node
+	if tag != nil {
+		tag.addType()
+		tmp = newVarNode(newTemp(tag.Type))
+		node.Lhs = newNode(ND_ASSIGN, tmp, tag)
+	}
 
-	for {
-		if consume("<") {
-			node = newNode(ND_LT, node, add())
-		} else if consume("<=") {
-			node = newNode(ND_LE, node, add())
-		} else if consume(">") {
-			node = newNode(ND_LT, add(), node)
-		} else if consume(">=") {
-			node = newNode(ND_LE, add(), node)
+	breakables = append(breakables, breakable{node, label})
+	defer func() { breakables = breakables[:len(breakables)-1] }()
+	expect("{")
+	var dflt *Token
+	seen := make(map[int]*Token) // The cases of integer literals
+	var fall *Node               // The fallthrough statement ending the last clause
+	for !consume("}") {
+		c := &Node{Kind: ND_CASE}
+		if tok := token; consume("default") {
+			if dflt != nil {
+				tok.errorf("multiple defaults in switch, first default at %s", dflt.position())
+			}
+			dflt = tok
 		} else {
-			return node
+			expect("case")
+			for {
+				tok := token
+				v := expr()
+				v.addType()
+				if tmp != nil {
+					if !assignable(tmp.Type, v) {
+						tok.errorf("cannot use type %s as type %s in switch case", v.Type, tmp.Type)
+					}
+					if v.Kind == ND_NUM {
+						if prev := seen[v.Val]; prev != nil {
+							tok.errorf("duplicate case %d in expression switch, previous case at %s", v.Val, prev.position())
+						}
+						seen[v.Val] = tok
+					}
+					v = newNode(ND_EQ, tmp, convert(v, tmp.Type, "switch case"))
+					v.addType()
+				}
+				c.Args = append(c.Args, v)
+				if !consume(",") {
+					break
+				}
+			}
+		}
+		expect(":")
+		if fall != nil {
+			fall.Target = c
+			fall = nil
+		}
+
+		enterScope()
+		c.Then = &Node{Kind: ND_BLOCK}
+		for !peek("case") && !peek("default") && !peek("}") {
+			tok := token
+			if !consume("fallthrough") {
+				c.Then.Body = append(c.Then.Body, stmt())
+				continue
+			}
+			consume(";")
+			if peek("}") {
+				tok.errorf("cannot fallthrough final case in switch")
+			}
+			if !peek("case") && !peek("default") {
+				tok.errorf("fallthrough statement out of place")
+			}
+			fall = &Node{Kind: ND_FALLTHROUGH}
+			c.Then.Body = append(c.Then.Body, fall)
 		}
+		c.Then.Vars = scopeVars()
+		leaveScope()
+		node.Body = append(node.Body, c)
 	}
+	return node
 }
 
-func add() *Node {
-	node := mul()
+// typeSwitch parses the clauses of a type switch with the guard.
+func typeSwitch(label string, init *Node, bind *Token, guard *Node) *Node {
+	x := guard.Lhs
+	tmp := newVarNode(newTemp(x.Type))
+	node := &Node{
+		Kind: ND_TYPESWITCH,
+		Init: init,
+		Lhs:  newNode(ND_ASSIGN, tmp, x),
+	}
 
-	for {
-		if consume("+") {
-			node = newNode(ND_ADD, node, mul())
-		} else if consume("-") {
-			node = newNode(ND_SUB, node, mul())
+	breakables = append(breakables, breakable{node, label})
+	defer func() { breakables = breakables[:len(breakables)-1] }()
+	expect("{")
+	var types []*Type
+	hasDefault := false
+	for !consume("}") {
+		c := &Node{Kind: ND_CASE}
+		if consume("default") {
+			if hasDefault {
+				panic("multiple defaults in switch")
+			}
+			hasDefault = true
 		} else {
-			return node
+			expect("case")
+			for {
+				var ty *Type
+				if consume("nil") {
+					ty = nilType
+				} else {
+					ty = parseType()
+				}
+				for _, t := range types {
+					if identical(t, ty) {
+						panic(fmt.Sprintf("duplicate case %s in type switch", ty))
+					}
+				}
+				types = append(types, ty)
+				if ty.isNil() {
+					c.Args = append(c.Args, &Node{Kind: ND_ASSERT, Lhs: tmp, Type: ty})
+				} else {
+					c.Args = append(c.Args, assertion(tmp, ty))
+				}
+				if !consume(",") {
+					break
+				}
+			}
+		}
+		expect(":")
+
+		// The variable of a clause listing a single type has that type,
+		// and otherwise the type of the interface.
+		enterScope()
+		c.Then = &Node{Kind: ND_BLOCK}
+		if bind != nil {
+			val := tmp
+			if len(c.Args) == 1 && !c.Args[0].Type.isNil() {
+				val = assertion(tmp, c.Args[0].Type)
+			}
+			c.Then.Body = append(c.Then.Body, newNode(ND_ASSIGN, newLVarNode(bind.str, val.Type), val))
+		}
+		for !peek("case") && !peek("default") && !peek("}") {
+			if peek("fallthrough") {
+				token.errorf("cannot fallthrough in type switch")
+			}
+			c.Then.Body = append(c.Then.Body, stmt())
 		}
+		c.Then.Vars = scopeVars()
+		leaveScope()
+		node.Body = append(node.Body, c)
 	}
+	return node
 }
 
-func mul() *Node {
-	node := unary()
+// typeSwitchGuard parses the guard of a type switch, [v :=] x.(type), if it
+// follows. Otherwise the token is left unread.
+func typeSwitchGuard() (*Token, *Node) {
+	start := token
+	var bind *Token
+	if tok := consumeIdent(); tok != nil && consume(":=") {
+		bind = tok
+	} else {
+		token = start
+	}
+	x := expr()
+	if x.Kind == ND_ASSERT && x.Type == nil && peek("{") {
+		x.Lhs.addType()
+		if !x.Lhs.Type.isInterface() {
+			panic(fmt.Sprintf("%s is not an interface", x.Lhs.Type))
+		}
+		return bind, x
+	}
+	token = start
+	return nil, nil
+}
 
-	for {
-		if consume("*") {
-			node = newNode(ND_MUL, node, unary())
-		} else if consume("/") {
-			node = newNode(ND_DIV, node, unary())
+// selectStmt parses a select statement. The channels and the values to send
+// of all the cases are evaluated first, and the communication of one ready
+// case is done by the runtime, into or from the temporary of the case. The
+// assignment of a receive case assigns the temporary, and whether the value
+// was received, at the beginning of the clause.
+func selectStmt() *Node {
+	label := takeLabel()
+	node := &Node{Kind: ND_SELECT}
+	breakables = append(breakables, breakable{node, label})
+	defer func() { breakables = breakables[:len(breakables)-1] }()
+	expect("{")
+	var dflt *Token
+	for !consume("}") {
+		c := &Node{Kind: ND_CASE}
+		enterScope()
+		c.Then = &Node{Kind: ND_BLOCK}
+		if tok := token; consume("default") {
+			if dflt != nil {
+				tok.errorf("multiple defaults in select, first default at %s", dflt.position())
+			}
+			dflt = tok
 		} else {
-			return node
+			expect("case")
+			c.Lhs = commCase(c.Then)
+		}
+		expect(":")
+		for !peek("case") && !peek("default") && !peek("}") {
+			c.Then.Body = append(c.Then.Body, stmt())
+		}
+		c.Then.Vars = scopeVars()
+		leaveScope()
+		node.Body = append(node.Body, c)
+	}
+	// The cases passed to the runtime, of the channel, the address of
+	// the temporary and whether it is a receive
+	n := 0
+	for _, c := range node.Body {
+		if c.Lhs != nil {
+			n++
 		}
 	}
+	node.Var = newTemp(arrayOf(intType, uint(n*3)))
+	return node
 }
 
-func unary() *Node {
-	if consume("+") {
-		return unary()
-	} else if consume("-") {
-		return newNode(ND_SUB, newNodeNum(0), unary())
-	} else if consume("&") {
-		return newNode(ND_ADDR, unary(), nil)
-	} else if consume("*") {
-		return newNode(ND_DEREF, unary(), nil)
+// commCase parses the communication of a case of a select statement, which
+// is either a send or a receive, and returns the send or the receive. The
+// assignment of the value received is appended to the clause.
+func commCase(clause *Node) *Node {
+	tok := token
+	var comm *Node
+	if token.kind == TK_IDENT && token.next.str == "," {
+		comm = commaOk()
+	} else {
+		comm = expr()
+		if consume("<-") {
+			comm = &Node{Kind: ND_SEND, Lhs: comm, Rhs: expr()}
+		}
 	}
-	return postfix()
+	comm.addType()
+	if comm.Kind == ND_SEND {
+		return comm
+	}
+	recv := comm
+	if comm.Kind == ND_ASSIGN {
+		recv = comm.Rhs
+		if recv.Kind == ND_CONV {
+			recv = recv.Lhs
+		}
+	}
+	if recv.Kind != ND_RECV {
+		tok.errorf("select case must be receive, send or assign recv")
+	}
+	if comm.Kind == ND_ASSIGN {
+		// The receive in the assignment is replaced by its temporary.
+		r := *recv
+		*recv = *newVarNode(r.Var)
+		clause.Body = append(clause.Body, comm)
+		if r.Ok != nil {
+			ok := newNode(ND_ASSIGN, r.Ok, newVarNode(newTemp(intType)))
+			ok.addType()
+			clause.Body = append(clause.Body, ok)
+			r.Ok = ok.Rhs
+		}
+		recv = &r
+	}
+	return recv
 }
 
-func postfix() *Node {
-	node := primary()
-	if consume("++") {
-		return newNode(ND_INC, node, nil)
-	} else if consume("--") {
-		return newNode(ND_DEC, node, nil)
-	} else if peek("[") {
-		return index(node)
+func block() *Node {
+	enterScope()
+	defer leaveScope()
+	node := &Node{Kind: ND_BLOCK}
+	for !consume("}") {
+		node.Body = append(node.Body, stmt())
 	}
+	node.Vars = scopeVars()
 	return node
 }
 
-func index(base *Node) *Node {
-	if !consume("[") {
-		return base
+// scopeVars returns the local variables declared in the current scope.
+func scopeVars() []*Var {
+	var vars []*Var
+	for _, name := range scope.Decls {
+		if v := scope.Vars[name]; v != nil && v.Const == nil {
+			vars = append(vars, v)
+		}
 	}
-	i := primary()
-	expect("]")
-	node := newNode(ND_INDEX, base, i)
-	return index(node)
+	return vars
+}
+
+// skipBlock skips over a block without parsing it.
+func skipBlock() {
+	expect("{")
+	for depth := 1; depth > 0; token = token.next {
+		if token.atEof() {
+			panic("unexpected EOF, expected '}'")
+		}
+		if peek("{") {
+			depth++
+		} else if peek("}") {
+			depth--
+		}
+	}
+}
+
+func program() {
+	globals = make(map[string]*Var)
+	typedefs = make(map[string]*typedef)
+	consts = make(map[string]*constDef)
+	globalDefs = make(map[string]*varDef)
+	initFunc = &Node{Kind: ND_FUNC, FunctionName: "main.init", Block: &Node{Kind: ND_BLOCK}}
+	initFuncs = nil
+	funcLits = make(map[string]int)
+	imports = nil
+	funcs = make(map[string]*Node)
+	generics = make(map[string]*generic)
+	instEdges = make(map[*Type][]instEdge)
+	pending = nil
+	funcsDeclared = false
+	itabs = nil
+	typeDescs = nil
+	literals = nil
+
+	// Package-level names can be used before they are declared, so the
+	// declarations are skimmed first to register the type names, and the
+	// types and function signatures are resolved before the rest is parsed.
+	var decls []*Token
+	var defs []*typedef
+	var cdefs []*constDef
+	var vdefs []*varDef
+	for peek("import") {
+		importDecl()
+		consume(";")
+	}
+	discovering = true
+	for !token.atEof() {
+		decls = append(decls, token)
+		switch token.str {
+		case "func":
+			signature()
+			skipBlock()
+		case "var":
+			expect("var")
+			tok := expectIdent()
+			if globalDefs[tok.str] != nil {
+				tok.errorf("%s redeclared in this block", tok.str)
+			}
+			if !peek("=") {
+				parseType()
+			}
+			if consume("=") {
+				skipExprs()
+			}
+			def := &varDef{Tok: tok}
+			globalDefs[tok.str] = def
+			vdefs = append(vdefs, def)
+		case "type":
+			typeDecl(func() {
+				def := &typedef{Tok: token}
+				def.Name = expectIdent().str
+				if typedefs[def.Name] != nil {
+					panic(fmt.Sprintf("%s redeclared in this block", def.Name))
+				}
+				if isTypeParams() {
+					def.Generic = &generic{Name: def.Name}
+					typeParams(def.Generic)
+					def.Generic.Tok = token
+				}
+				def.Alias = consume("=")
+				if def.Alias && def.Generic != nil {
+					panic("generic type cannot be alias")
+				}
+				if !def.Alias && def.Generic == nil {
+					def.Type = &Type{Name: def.Name}
+				}
+				parseType()
+				typedefs[def.Name] = def
+				defs = append(defs, def)
+			})
+		case "const":
+			for _, def := range constDecl() {
+				name := def.Name.str
+				if consts[name] != nil || typedefs[name] != nil {
+					def.Name.errorf("%s redeclared in this block", name)
+				}
+				consts[name] = def
+				cdefs = append(cdefs, def)
+			}
+		default:
+			panic(fmt.Sprintf("expected declaration, found %s", token.str))
+		}
+		consume(";")
+	}
+	discovering = false
+
+	for _, def := range defs {
+		def.resolve()
+	}
+	for _, def := range cdefs {
+		def.value()
+	}
+	type funcDecl struct {
+		fn   *Node
+		body *Token
+	}
+	fdecls := make(map[*Token]funcDecl)
+	for _, tok := range decls {
+		if tok.str == "func" {
+			token = tok
+			fdecls[tok] = funcDecl{signature(), token}
+		}
+	}
+	funcsDeclared = true
+	for _, def := range vdefs {
+		def.resolve()
+	}
+	for _, tok := range decls {
+		if tok.str == "func" {
+			if d := fdecls[tok]; d.fn != nil {
+				token = d.body
+				function(d.fn)
+			}
+		}
+	}
+	// The bodies of the instances of generic functions and of the methods
+	// of generic types are parsed once the other functions are, and may
+	// instantiate more. Those of the generic declarations themselves,
+	// instantiated with their type parameters when they are declared, come
+	// first.
+	for len(pending) > 0 {
+		p := pending[0]
+		pending = pending[1:]
+		if p.Check {
+			parseAt(p.Body, p.Scope, func() { checkFunction(p.Fn) })
+		} else {
+			parseAt(p.Body, p.Scope, func() { function(p.Fn) })
+		}
+	}
+	initOrder(vdefs)
+	for _, imp := range imports {
+		if !imp.Used {
+			imp.Tok.errorf("%q imported and not used", imp.Tok.str)
+		}
+	}
+}
+
+// stdFuncs holds the signatures of the functions of the standard packages,
+// which are implemented by the runtime.
+var stdFuncs = map[string]map[string]*Node{
+	"os": {
+		"Exit": {Kind: ND_FUNC, FunctionName: "os.Exit", Args: []*Node{{Type: intType}}},
+	},
+	"runtime": {
+		"GC":      {Kind: ND_FUNC, FunctionName: "runtime.GC", Args: []*Node{}},
+		"Gosched": {Kind: ND_FUNC, FunctionName: "runtime.Gosched", Args: []*Node{}},
+	},
+	"sync":        {},
+	"sync/atomic": atomicFuncs(),
+}
+
+// atomicFuncs returns the signatures of the functions of sync/atomic on
+// int32 and int64, which are compiled as intrinsics.
+func atomicFuncs() map[string]*Node {
+	fns := make(map[string]*Node)
+	for _, ty := range []*Type{runeType, int64Type} {
+		suffix := "Int" + strings.TrimPrefix(ty.String(), "int")
+		addr, val := &Node{Type: pointerTo(ty)}, &Node{Type: ty}
+		add := func(name string, result *Type, args ...*Node) {
+			fns[name+suffix] = &Node{Kind: ND_FUNC, FunctionName: "atomic." + name + suffix, Args: args, Type: result}
+		}
+		add("Add", ty, addr, val)
+		add("Load", ty, addr)
+		add("Store", nil, addr, val)
+		add("CompareAndSwap", intType, addr, val, val)
+	}
+	return fns
+}
+
+// stdTypes holds the types of the standard packages, which are structs of
+// unexported fields whose methods are implemented by the runtime.
+var stdTypes = map[string]map[string]*Type{
+	"sync": {
+		"Mutex": stdType("sync.Mutex", []string{"state", "waitq", "waitqtail"}, []stdMethod{
+			{"Lock", nil, nil},
+			{"TryLock", nil, intType},
+			{"Unlock", nil, nil},
+		}),
+		"WaitGroup": stdType("sync.WaitGroup", []string{"counter", "waitq", "waitqtail"}, []stdMethod{
+			{"Add", []*Type{intType}, nil},
+			{"Done", nil, nil},
+			{"Wait", nil, nil},
+		}),
+	},
+}
+
+// A stdMethod is the signature of a method of a standard type, whose
+// receiver is a pointer.
+type stdMethod struct {
+	Name   string
+	Params []*Type
+	Result *Type
+}
+
+// stdType returns the named struct type of the int fields, whose methods
+// are declared under the names qualified by the name of the type.
+func stdType(name string, fields []string, methods []stdMethod) *Type {
+	var fs []*Field
+	for _, f := range fields {
+		fs = append(fs, &Field{Name: f, Type: intType})
+	}
+	ty := structOf(fs)
+	ty.Name = name
+	ty.Methods = make(map[string]*Node)
+	for _, m := range methods {
+		args := []*Node{{Type: pointerTo(ty)}}
+		for _, p := range m.Params {
+			args = append(args, &Node{Type: p})
+		}
+		ty.Methods[m.Name] = &Node{Kind: ND_FUNC, FunctionName: name + "." + m.Name, Args: args, Type: m.Result}
+	}
+	return ty
+}
+
+// An importSpec is an imported package.
+type importSpec struct {
+	Tok  *Token // The import path
+	Name string // The package name, which is the last element of the path
+	Used bool
+}
+
+var imports []*importSpec
+
+func findImport(name string) *importSpec {
+	for _, imp := range imports {
+		if imp.Name == name {
+			return imp
+		}
+	}
+	return nil
+}
+
+// importDecl parses an import declaration, which may be a parenthesized
+// group. The functions of an imported package, and the methods of its
+// types, are declared under their qualified names.
+func importDecl() {
+	expect("import")
+	group := consume("(")
+	for !group || !consume(")") {
+		tok := token
+		if tok.kind != TK_STR {
+			tok.errorf("missing import path")
+		}
+		token = token.next
+		if stdFuncs[tok.str] == nil {
+			tok.errorf("package %s is not in std", tok.str)
+		}
+		name := tok.str[strings.LastIndex(tok.str, "/")+1:]
+		if findImport(name) != nil {
+			tok.errorf("%s redeclared in this block", name)
+		}
+		imports = append(imports, &importSpec{Tok: tok, Name: name})
+		for _, fn := range stdFuncs[tok.str] {
+			funcs[fn.FunctionName] = fn
+		}
+		for _, ty := range stdTypes[tok.str] {
+			for _, m := range ty.Methods {
+				funcs[m.FunctionName] = m
+			}
+		}
+		if !group {
+			break
+		}
+		consume(";")
+	}
+}
+
+// signature parses the signature of a function and registers the function.
+// A method is registered under a symbol qualified by its receiver base type,
+// so that it cannot collide with functions or methods of other types. A
+// generic function, or a method of a generic type, is registered to be
+// instantiated, and nil is returned.
+func signature() *Node {
+	decl := token
+	expect("func")
+	locals = nil
+	var recv *Node
+	if peek("(") {
+		if g := genericReceiver(); g != nil {
+			g.addMethod(decl, expectIdent())
+			return nil
+		}
+		recv = receiver()
+	}
+	tok := expectIdent()
+	if peek("[") {
+		if recv != nil {
+			panic("syntax error: method must have no type parameters")
+		}
+		g := &generic{Name: tok.str}
+		typeParams(g)
+		if !discovering {
+			if tok.str == "main" || tok.str == "init" {
+				tok.errorf("func %s must have no type parameters", tok.str)
+			}
+			if funcs[pkgSymbol(tok.str)] != nil || generics[tok.str] != nil {
+				panic(fmt.Sprintf("%s redeclared in this block", tok.str))
+			}
+			genericFunc(g)
+			return nil
+		}
+	}
+	node := &Node{
+		Kind:         ND_FUNC,
+		FunctionName: pkgSymbol(tok.str),
+		Args:         definedArgs(),
+	}
+	result(node)
+	if discovering {
+		return node
+	}
+	if recv != nil {
+		base := recv.Type
+		if base.isPointer() {
+			base = base.Ref
+		}
+		if !base.isNamed() || base.isPointer() || typedefs[base.Name] == nil || typedefs[base.Name].Type != base {
+			panic(fmt.Sprintf("invalid receiver type %s", recv.Type))
+		}
+		if base.Methods[tok.str] != nil {
+			panic(fmt.Sprintf("method %s.%s already declared", base.Name, tok.str))
+		}
+		if base.findField(tok.str) != nil {
+			panic(fmt.Sprintf("field and method with the same name %s", tok.str))
+		}
+		if base.Methods == nil {
+			base.Methods = make(map[string]*Node)
+		}
+		base.Methods[tok.str] = node
+		node.FunctionName = pkgSymbol(base.Name + "." + tok.str)
+		node.Args = append([]*Node{recv}, node.Args...)
+	} else if tok.str == "main" || tok.str == "init" {
+		if len(node.Args) > 0 || node.Type != nil {
+			tok.errorf("func %s must have no arguments and no return values", tok.str)
+		}
+		if tok.str == "init" {
+			// There may be several init functions, which cannot be
+			// referred to.
+			node.FunctionName = fmt.Sprintf("main.init.%d", len(initFuncs))
+			initFuncs = append(initFuncs, node)
+			return node
+		}
+		if funcs[node.FunctionName] != nil {
+			tok.errorf("main redeclared in this block")
+		}
+	} else if funcs[node.FunctionName] != nil || generics[tok.str] != nil {
+		panic(fmt.Sprintf("%s redeclared in this block", tok.str))
+	}
+	funcs[node.FunctionName] = node
+	return node
+}
+
+// receiver parses the receiver of a method declaration.
+func receiver() *Node {
+	expect("(")
+	name := "_"
+	if tok := consumeIdent(); tok != nil {
+		if peek(")") {
+			token = tok
+		} else {
+			name = tok.str
+		}
+	}
+	node := newLVarNode(name, parseType())
+	expect(")")
+	return node
+}
+
+// function parses the body of a function whose signature has been parsed.
+func function(node *Node) {
+	curFunc = node
+	locals = nil
+	labels, jumps = nil, nil
+	enterScope()
+	for _, a := range node.Args {
+		locals = &VarList{locals, a.Var}
+		scope.Vars[a.Var.Name] = a.Var
+	}
+	if r := node.Result; r != nil {
+		locals = &VarList{locals, r}
+		scope.Vars[r.Name] = r
+	}
+	expect("{")
+	node.Block = block()
+	leaveScope()
+	resolveJumps()
+	node.Block.addType()
+	escape(node)
+	node.Locals = locals
+	code = append(code, node)
+	curFunc = nil
+}
+
+// checkFunction type-checks the body of a function whose types depend on
+// type parameters. The function, and the itables and the type descriptors
+// it requires, are not emitted.
+func checkFunction(node *Node) {
+	n, i, d := len(code), len(itabs), len(typeDescs)
+	function(node)
+	code, itabs, typeDescs = code[:n], itabs[:i], typeDescs[:d]
+}
+
+// containsParams reports whether any of the types depends on a type
+// parameter.
+func containsParams(types []*Type) bool {
+	for _, t := range types {
+		if containsParam(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// A generic is a generic function or type. It is instantiated for each
+// distinct list of type arguments by parsing its declaration again, with the
+// type parameters declared as aliases of the type arguments.
+type generic struct {
+	Name        string
+	TParams     []*Token // The names of the type parameters
+	Constraints []*Token // The constraints of the type parameters
+	List        string   // The type parameter list as written
+	Tok         *Token   // The declaration after the type parameter list
+	Instances   []*instance
+
+	// The signature of a generic function, parsed with the type parameters
+	// declared as the types of kind TY_PARAM in Params, from which type
+	// arguments are inferred
+	Params []*Type
+	Sig    *Node
+
+	// The methods of a generic type
+	Methods []genericMethod
+}
+
+// A genericMethod is a method declared with a generic receiver base type.
+type genericMethod struct {
+	Name string
+	Decl *Token
+}
+
+// An instance is a generic function or type instantiated with type
+// arguments.
+type instance struct {
+	TArgs     []*Type
+	Fn        *Node
+	Type      *Type
+	resolving bool
+}
+
+var generics map[string]*generic // Generic functions
+
+func (g *generic) String() string {
+	return g.Name + "[" + g.List + "]"
+}
+
+// An instEdge records that a type parameter is used in a type argument for
+// another, which grows if the argument is not the type parameter itself.
+type instEdge struct {
+	To    *Type
+	Grows bool
+}
+
+var instEdges map[*Type][]instEdge // Edges from the type parameters used
+
+// checkCycle records the type parameters used in the type arguments for g,
+// and panics if a type parameter is thereby instantiated with a type
+// argument growing from itself, which would instantiate g endlessly.
+func (g *generic) checkCycle(targs []*Type) {
+	for i, t := range targs {
+		for _, p := range paramsOf(nil, t) {
+			e := instEdge{g.Params[i], t != p}
+			instEdges[p] = append(instEdges[p], e)
+			if reaches(e.To, p, e.Grows, make(map[instEdge]bool)) {
+				panic(fmt.Sprintf("instantiation cycle: %s", instanceName(g.Name, targs)))
+			}
+		}
+	}
+}
+
+// reaches reports whether the type parameter to is reached from from through
+// the recorded edges, with a growing edge on the way unless grows is set.
+func reaches(from, to *Type, grows bool, seen map[instEdge]bool) bool {
+	if from == to && grows {
+		return true
+	}
+	for _, e := range instEdges[from] {
+		e.Grows = e.Grows || grows
+		if !seen[e] {
+			seen[e] = true
+			if reaches(e.To, to, e.Grows, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// find returns the instance of g with the type arguments, or nil.
+func (g *generic) find(targs []*Type) *instance {
+	for _, inst := range g.Instances {
+		found := true
+		for i, t := range inst.TArgs {
+			found = found && identical(t, targs[i])
+		}
+		if found {
+			return inst
+		}
+	}
+	return nil
+}
+
+// A pendingFunc is an instance of a generic function, or a method of an
+// instance of a generic type, whose body is yet to be parsed in the scope
+// of its type arguments.
+type pendingFunc struct {
+	Fn    *Node
+	Body  *Token
+	Scope *Scope
+	Check bool // Whether the body is only type-checked with type parameters
+}
+
+var pending []pendingFunc
+
+// isTypeParams reports whether the "[" following the name in a type spec
+// begins a type parameter list rather than an array length.
+func isTypeParams() bool {
+	if !peek("[") || token.next.kind != TK_IDENT {
+		return false
+	}
+	next := token.next.next
+	if next.kind == TK_IDENT {
+		return true
+	}
+	if _, ok := typeNames[next.str]; ok && next.isReserved() {
+		return true
+	}
+	switch next.str {
+	case ",", "~", "[", "interface", "map", "chan", "func", "struct":
+		return next.isReserved()
+	}
+	return false
+}
+
+// typeParams parses the type parameter list of a generic declaration. The
+// constraints are parsed when the declaration is instantiated, as they may
+// refer to the type parameters.
+func typeParams(g *generic) {
+	open := token
+	expect("[")
+	for !peek("]") {
+		var names []*Token
+		for {
+			names = append(names, expectIdent())
+			if !consume(",") {
+				break
+			}
+		}
+		if peek(",") || peek("]") {
+			panic("missing type constraint")
+		}
+		for _, name := range names {
+			for _, p := range g.TParams {
+				if p.str == name.str && name.str != "_" {
+					name.errorf("%s redeclared in this block", name.str)
+				}
+			}
+			g.TParams = append(g.TParams, name)
+			g.Constraints = append(g.Constraints, token)
+		}
+		skipConstraint()
+		consume(",")
+	}
+	if len(g.TParams) == 0 {
+		panic("empty type parameter list")
+	}
+	g.List = userInput[open.next.pos:token.pos]
+	expect("]")
+}
+
+// skipConstraint skips over a constraint in a type parameter list.
+func skipConstraint() {
+	for depth := 0; depth > 0 || !peek(",") && !peek("]"); token = token.next {
+		switch {
+		case token.atEof():
+			panic("unexpected EOF, expected ']'")
+		case peek("(") || peek("[") || peek("{"):
+			depth++
+		case peek(")") || peek("]") || peek("}"):
+			depth--
+		}
+	}
+}
+
+// typeArgs parses a list of type arguments.
+func typeArgs() []*Type {
+	expect("[")
+	var targs []*Type
+	for !consume("]") {
+		targs = append(targs, parseType())
+		consume(",")
+	}
+	return targs
+}
+
+// typeParamScope returns a scope declaring the type parameters as aliases
+// of the type arguments, in which a generic declaration is parsed.
+func typeParamScope(names []*Token, targs []*Type) *Scope {
+	sc := &Scope{Vars: make(map[string]*Var), Types: make(map[string]*typedef), Start: token}
+	for i, name := range names {
+		sc.Types[name.str] = &typedef{Name: name.str, Type: targs[i], Alias: true, Param: true, resolved: true}
+	}
+	return sc
+}
+
+// parseAt parses with f from tok in the scope sc, and then restores the
+// state of the parser, so that a generic declaration can be instantiated
+// while another declaration is being parsed.
+func parseAt(tok *Token, sc *Scope, f func()) {
+	t, s, l, ind := token, scope, locals, indirect
+	token, scope, indirect = tok, sc, 0
+	f()
+	token, scope, locals, indirect = t, s, l, ind
+}
+
+// checkTypeArgs panics unless the type arguments satisfy the constraints
+// of the type parameters of g.
+func (g *generic) checkTypeArgs(targs []*Type) {
+	sc := typeParamScope(g.TParams, targs)
+	for i, tok := range g.Constraints {
+		var c *Type
+		parseAt(tok, sc, func() { c = constraint() })
+		if msg := unsatisfied(targs[i], c); msg != "" {
+			panic(msg)
+		}
+	}
+}
+
+// instanceName returns the name of the instance of the generic declaration
+// with the type arguments.
+func instanceName(name string, targs []*Type) string {
+	var s []string
+	for _, t := range targs {
+		s = append(s, t.String())
+	}
+	return name + "[" + strings.Join(s, ",") + "]"
+}
+
+// pkgSymbol returns the assembly symbol of a package-level function or
+// variable, which is qualified by the package so that it cannot collide with
+// the symbols of the runtime and the C library.
+func pkgSymbol(name string) string {
+	return "main." + name
+}
+
+// declName returns the name the symbol is declared with in the package.
+func declName(sym string) string {
+	return strings.TrimPrefix(sym, "main.")
+}
+
+// symbol returns the assembly symbol of a function of an instance, in which
+// the bytes that cannot appear in a symbol are escaped. Instances whose type
+// arguments are distinct types of the same name get distinct symbols.
+func symbol(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; isIdent(c) || isDigit(c) || c == '.' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "$%02x", c)
+		}
+	}
+	sym := pkgSymbol(b.String())
+	for i := 1; funcs[sym] != nil; i++ {
+		sym = fmt.Sprintf("%s.%d", pkgSymbol(b.String()), i)
+	}
+	return sym
+}
+
+// declareParams declares the type parameters of g as types of kind
+// TY_PARAM constrained by their constraints, and returns the scope declaring
+// them. A constraint may refer to the type parameters after them, which are
+// constrained by any meanwhile.
+func (g *generic) declareParams() *Scope {
+	for _, p := range g.TParams {
+		g.Params = append(g.Params, &Type{Kind: TY_PARAM, Name: p.str, Constraint: anyType})
+	}
+	sc := typeParamScope(g.TParams, g.Params)
+	for i, tok := range g.Constraints {
+		parseAt(tok, sc, func() {
+			c := constraint()
+			g.Params[i].Constraint, g.Params[i].IMethods = c, c.IMethods
+		})
+	}
+	return sc
+}
+
+// isParams reports whether the type arguments are the type parameters of g
+// themselves, with which the declaration of g is type-checked.
+func (g *generic) isParams(targs []*Type) bool {
+	for i, t := range targs {
+		if t != g.Params[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// genericFunc registers the generic function g, whose type parameter list
+// has been parsed, and instantiates it with its type parameters so that its
+// body is type-checked.
+func genericFunc(g *generic) {
+	g.Tok = token
+	parseAt(token, g.declareParams(), func() {
+		g.Sig = &Node{Kind: ND_FUNC, FunctionName: g.Name, Args: definedArgs()}
+		result(g.Sig)
+	})
+	generics[g.Name] = g
+	instantiateFunc(g, g.Params)
+}
+
+// instantiateFunc returns the instance of the generic function g with the
+// type arguments. Its signature is parsed now, and its body once the
+// functions of the package are. The body of the instance with the type
+// parameters of g themselves is only type-checked, and an instance with
+// other type arguments depending on type parameters has no body.
+func instantiateFunc(g *generic, targs []*Type) *Node {
+	if len(targs) > len(g.TParams) {
+		panic(fmt.Sprintf("got %d type arguments but %s has %d type parameters", len(targs), g, len(g.TParams)))
+	}
+	if len(targs) < len(g.TParams) {
+		panic(fmt.Sprintf("cannot use generic function %s without instantiation", g.Name))
+	}
+	if inst := g.find(targs); inst != nil {
+		return inst.Fn
+	}
+	g.checkTypeArgs(targs)
+	g.checkCycle(targs)
+	fn := &Node{Kind: ND_FUNC, FunctionName: symbol(instanceName(g.Name, targs))}
+	g.Instances = append(g.Instances, &instance{TArgs: targs, Fn: fn})
+	funcs[fn.FunctionName] = fn
+	sc := typeParamScope(g.TParams, targs)
+	parseAt(g.Tok, sc, func() {
+		fn.Args = definedArgs()
+		result(fn)
+		if g.isParams(targs) {
+			pending = append(pending, pendingFunc{fn, token, sc, true})
+		} else if !containsParams(targs) {
+			pending = append(pending, pendingFunc{fn, token, sc, false})
+		}
+	})
+	return fn
+}
+
+// instantiateType returns the instance of the generic type g with the type
+// arguments, which is a named type declared with its methods. The methods of
+// an instance depending on type parameters are type-checked as those of
+// instantiateFunc are.
+func instantiateType(g *generic, targs []*Type) *Type {
+	if len(targs) != len(g.TParams) {
+		msg := "not enough"
+		if len(targs) > len(g.TParams) {
+			msg = "too many"
+		}
+		panic(fmt.Sprintf("%s type arguments for type %s: have %d, want %d", msg, g.Name, len(targs), len(g.TParams)))
+	}
+	if inst := g.find(targs); inst != nil {
+		if inst.resolving && indirect == 0 {
+			panic(fmt.Sprintf("invalid recursive type %s", inst.Type))
+		}
+		return inst.Type
+	}
+	ty := &Type{Name: instanceName(g.Name, targs)}
+	inst := &instance{TArgs: targs, Type: ty}
+	g.Instances = append(g.Instances, inst)
+	g.checkTypeArgs(targs)
+	g.checkCycle(targs)
+	inst.resolving = true
+	parseAt(g.Tok, typeParamScope(g.TParams, targs), func() {
+		namedType(ty, typeOrConstraint())
+	})
+	ty.Generic, ty.TArgs = g, targs
+	inst.resolving = false
+	for _, m := range g.Methods {
+		instantiateMethod(ty, m.Decl)
+	}
+	return ty
+}
+
+// genericReceiver returns the generic type of the receiver of the method
+// declaration being parsed, skipping over the receiver, or nil if the
+// receiver base type is not generic.
+func genericReceiver() *generic {
+	if discovering {
+		return nil
+	}
+	t := token.next
+	if t.kind == TK_IDENT && (t.next.kind == TK_IDENT || t.next.isReserved() && t.next.str == "*") {
+		t = t.next
+	}
+	if t.isReserved() && t.str == "*" {
+		t = t.next
+	}
+	if t.kind != TK_IDENT || !t.next.isReserved() || t.next.str != "[" {
+		return nil
+	}
+	def := typedefs[t.str]
+	if def == nil || def.Generic == nil {
+		return nil
+	}
+	for token = t.next; !consume(")"); token = token.next {
+		if token.atEof() {
+			panic("unexpected EOF, expected ')'")
+		}
+	}
+	return def.Generic
+}
+
+// addMethod adds the method declared at decl, whose name is tok, to the
+// generic type g, and declares it on the instances of g.
+func (g *generic) addMethod(decl *Token, tok *Token) {
+	if peek("[") {
+		panic("syntax error: method must have no type parameters")
+	}
+	for _, m := range g.Methods {
+		if m.Name == tok.str {
+			panic(fmt.Sprintf("method %s.%s already declared", g.Name, tok.str))
+		}
+	}
+	g.Methods = append(g.Methods, genericMethod{tok.str, decl})
+	for _, inst := range g.Instances {
+		if !inst.resolving {
+			instantiateMethod(inst.Type, decl)
+		}
+	}
+}
+
+// instantiateMethod declares the method of a generic type, declared at
+// decl, on the instance ty. The receiver type parameters are declared as
+// aliases of the type arguments of ty.
+func instantiateMethod(ty *Type, decl *Token) {
+	parseAt(decl, nil, func() {
+		expect("func")
+		expect("(")
+		name := "_"
+		if tok := consumeIdent(); tok != nil && (peek("*") || token.kind == TK_IDENT) {
+			name = tok.str
+		} else if tok != nil {
+			token = tok
+		}
+		recvType := ty
+		if consume("*") {
+			recvType = pointerTo(ty)
+		}
+		expectIdent()
+		var names []*Token
+		expect("[")
+		for !consume("]") {
+			names = append(names, expectIdent())
+			consume(",")
+		}
+		expect(")")
+		if len(names) != len(ty.TArgs) {
+			panic(fmt.Sprintf("receiver declares %d type parameters, but receiver base type declares %d", len(names), len(ty.TArgs)))
+		}
+		scope = typeParamScope(names, ty.TArgs)
+		recv := newLVarNode(name, recvType)
+		tok := expectIdent()
+		fn := &Node{Kind: ND_FUNC, Args: definedArgs()}
+		result(fn)
+		if ty.findField(tok.str) != nil {
+			panic(fmt.Sprintf("field and method with the same name %s", tok.str))
+		}
+		if ty.Methods == nil {
+			ty.Methods = make(map[string]*Node)
+		}
+		ty.Methods[tok.str] = fn
+		fn.FunctionName = symbol(ty.Name + "." + tok.str)
+		fn.Args = append([]*Node{recv}, fn.Args...)
+		funcs[fn.FunctionName] = fn
+		if ty.Generic.isParams(ty.TArgs) {
+			pending = append(pending, pendingFunc{fn, token, scope, true})
+		} else if !containsParams(ty.TArgs) {
+			pending = append(pending, pendingFunc{fn, token, scope, false})
+		}
+	})
+}
+
+// A funcLit is a function literal being parsed. The local variables of the
+// enclosing functions it refers to are captured by reference.
+type funcLit struct {
+	Parent   *funcLit
+	Fn       *Node
+	Captures map[*Var]*Var // The captured variables by the variables they refer to
+}
+
+var curLit *funcLit         // The innermost function literal being parsed
+var funcLits map[string]int // The number of function literals in each function
+
+// funcLiteral parses a function literal. Its body is compiled to a function
+// of its own, which takes a closure of the pointers to the variables it
+// captures. The literal evaluates to the closure, which is static if no
+// variables are captured.
+func funcLiteral() *Node {
+	expect("func")
+	outer := curFunc
+	if outer == nil {
+		outer = initFunc
+	}
+	funcLits[outer.FunctionName]++
+	fn := &Node{Kind: ND_FUNC, FunctionName: fmt.Sprintf("%s.func%d", outer.FunctionName, funcLits[outer.FunctionName])}
+
+	fnSaved, litSaved, lvars := curFunc, curLit, locals
+	lbls, jmps, brks := labels, jumps, breakables
+	curFunc, curLit, locals = fn, &funcLit{Parent: curLit, Fn: fn, Captures: make(map[*Var]*Var)}, nil
+	labels, jumps, breakables = nil, nil, nil
+	enterScope()
+	fn.Args = definedArgs()
+	result(fn)
+	expect("{")
+	fn.Block = block()
+	leaveScope()
+	resolveJumps()
+	fn.Block.addType()
+	for _, c := range fn.Vars {
+		locals = &VarList{locals, c}
+	}
+	fn.Locals = locals
+	code = append(code, fn)
+	curFunc, curLit, locals = fnSaved, litSaved, lvars
+	labels, jumps, breakables = lbls, jmps, brks
+	return closure(fn)
+}
+
+// closure returns the node of the closure of the function literal fn.
+func closure(fn *Node) *Node {
+	node := &Node{Kind: ND_CLOSURE, FunctionName: fn.FunctionName, Type: funcTypeOf(fn), Func: fn}
+	if len(fn.Vars) == 0 {
+		return node
+	}
+	// The closure is the address of the function followed by the
+	// pointers to the captured variables.
+	ty := &Type{Kind: TY_STRUCT, Fields: []*Field{{Name: "F", Type: intType}}}
+	for i, c := range fn.Vars {
+		ty.Fields = append(ty.Fields, &Field{Name: c.Name, Type: pointerTo(c.Type), Offset: uint(i+1) * 8})
+	}
+	node.Var = newTemp(ty)
+	for _, f := range ty.Fields[1:] {
+		m := newNode(ND_MEMBER, newVarNode(node.Var), nil)
+		m.Member = f
+		a := newNode(ND_ASSIGN, m, newNode(ND_ADDR, newVarNode(fn.Vars[len(node.Body)].Outer), nil))
+		a.addType()
+		node.Body = append(node.Body, a)
+	}
+	return node
+}
+
+// callStmt parses the call of a defer or go statement of the kind. The
+// function value and the arguments of the call are evaluated into new
+// variables each time the statement is executed, and a function literal
+// capturing them, which makes the call, is deferred until the function
+// returns, or run in a new goroutine.
+func callStmt(tok *Token, kind NodeKind) *Node {
+	call := expr()
+	call.addType()
+	switch call.Kind {
+	case ND_FUNCALL, ND_ICALL:
+	case ND_CLOSE, ND_COPY, ND_DELETE, ND_PANIC, ND_RECOVER:
+		// The builtin functions that may be called as statements
+	default:
+		tok.errorf("expression in %s must be function call", tok.str)
+	}
+	funcLits[curFunc.FunctionName]++
+	fn := &Node{Kind: ND_FUNC, FunctionName: fmt.Sprintf("%s.func%d", curFunc.FunctionName, funcLits[curFunc.FunctionName])}
+
+	node := &Node{Kind: kind}
+	operands := []**Node{}
+	if call.Lhs != nil {
+		operands = append(operands, &call.Lhs)
+	}
+	if call.Rhs != nil {
+		operands = append(operands, &call.Rhs)
+	}
+	for i := range call.Args {
+		operands = append(operands, &call.Args[i])
+	}
+	for _, op := range operands {
+		v := newTemp((*op).Type)
+		a := newNode(ND_ASSIGN, newVarNode(v), *op)
+		a.addType()
+		node.Body = append(node.Body, a)
+		node.Vars = append(node.Vars, v)
+	}
+
+	lvars := locals
+	locals = nil
+	for i, op := range operands {
+		c := &Var{Type: node.Vars[i].Type, IsLocal: true, OnHeap: true, Outer: node.Vars[i]}
+		fn.Vars = append(fn.Vars, c)
+		locals = &VarList{locals, c}
+		*op = newVarNode(c)
+	}
+	if call.Var != nil {
+		// The aggregate result, or the temporary of a builtin, is in a
+		// temporary of the literal.
+		call.Var = newTemp(call.Var.Type)
+	}
+	fn.Block = &Node{Kind: ND_BLOCK, Body: []*Node{call}}
+	fn.Locals = locals
+	code = append(code, fn)
+	locals = lvars
+
+	node.Lhs = closure(fn)
+	return node
+}
+
+// capture returns the variable through which the function being parsed
+// refers to the local variable v. The variable is captured by each function
+// literal between the function it is declared in and the innermost one.
+func capture(v *Var) *Var {
+	owner := curLit
+	for sc := scope; sc != nil; sc = sc.Parent {
+		if sc.Vars[v.Name] == v {
+			owner = sc.Lit
+			break
+		}
+	}
+	var captureIn func(l *funcLit) *Var
+	captureIn = func(l *funcLit) *Var {
+		if l == owner {
+			return v
+		}
+		if c := l.Captures[v]; c != nil {
+			return c
+		}
+		c := &Var{Name: v.Name, Type: v.Type, IsLocal: true, OnHeap: true, Outer: captureIn(l.Parent)}
+		l.Captures[v] = c
+		l.Fn.Vars = append(l.Fn.Vars, c)
+		return c
+	}
+	return captureIn(curLit)
+}
+
+// typeDecl parses a type declaration, which may be a parenthesized group,
+// calling spec for each type spec in it.
+func typeDecl(spec func()) {
+	expect("type")
+	if consume("(") {
+		for !consume(")") {
+			spec()
+			consume(";")
+		}
+		return
+	}
+	spec()
+}
+
+// A constDef is a constant of a constant declaration. Its spec is parsed
+// when the constant is evaluated, which a package-level constant is when it
+// is first used.
+type constDef struct {
+	Name  *Token
+	Type  *Token // The type of the spec, or nil
+	Exprs *Token // The expression list of the spec
+	Index int    // The index of the name in the identifier list
+	Count int    // The number of names in the identifier list
+	Iota  int
+
+	val       *Node
+	resolving bool
+}
+
+var consts map[string]*constDef // Package-level constants
+
+var iotaValue = -1 // The value of iota in the constant spec being evaluated
+
+// constDecl parses a constant declaration, which may be a parenthesized
+// group, skipping over the expressions. In a group, a spec without
+// expressions repeats the type and the expressions of the previous spec.
+func constDecl() []*constDef {
+	expect("const")
+	group := consume("(")
+	var defs []*constDef
+	var typ, exprs *Token
+	for i := 0; !group || !consume(")"); i++ {
+		var names []*Token
+		for {
+			names = append(names, expectIdent())
+			if !consume(",") {
+				break
+			}
+		}
+		tok := token
+		if consume("=") {
+			typ, exprs = nil, token
+			skipExprs()
+		} else if !peek(";") && !peek(")") {
+			typ = token
+			parseType()
+			if !consume("=") {
+				token.errorf("missing init expr for const declaration")
+			}
+			exprs = token
+			skipExprs()
+		} else if !group || exprs == nil {
+			tok.errorf("missing init expr for const declaration")
+		}
+		for j, name := range names {
+			defs = append(defs, &constDef{Name: name, Type: typ, Exprs: exprs, Index: j, Count: len(names), Iota: i})
+		}
+		if !group {
+			break
+		}
+		consume(";")
+	}
+	return defs
+}
+
+// skipExprs skips over an expression list, which ends at a semicolon, or
+// at a closing parenthesis, bracket or brace out of it.
+func skipExprs() {
+	for depth := 0; !token.atEof(); token = token.next {
+		if !token.isReserved() {
+			continue
+		}
+		switch token.str {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth == 0 {
+				return
+			}
+			depth--
+		case ";":
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// value evaluates the constant, which is either an integer or a string.
+func (def *constDef) value() *Node {
+	if def.val != nil {
+		return def.val
+	}
+	if def.resolving {
+		def.Name.errorf("invalid recursive constant %s", def.Name.str)
+	}
+	def.resolving = true
+	tok, iota := token, iotaValue
+	iotaValue = def.Iota
+
+	var ty *Type
+	if def.Type != nil {
+		token = def.Type
+		ty = parseType()
+		if !ty.isInteger() && !ty.isString() {
+			def.Type.errorf("invalid constant type %s", ty)
+		}
+	}
+	token = def.Exprs
+	var vals []*Node
+	var toks []*Token
+	for {
+		toks = append(toks, token)
+		vals = append(vals, equality())
+		if !consume(",") {
+			break
+		}
+	}
+	if len(vals) < def.Count {
+		def.Name.errorf("missing init expr for const declaration")
+	}
+	if len(vals) > def.Count {
+		toks[def.Count].errorf("extra init expr")
+	}
+	n := vals[def.Index]
+	n.addType()
+	if !isConst(n) {
+		toks[def.Index].errorf("value of type %s is not constant", n.Type)
+	}
+	if ty != nil {
+		n = typedConst(convert(n, ty, "constant declaration"), ty)
+	}
+
+	token, iotaValue = tok, iota
+	def.val = n
+	def.resolving = false
+	return n
+}
+
+// localTypeSpec parses a type spec in a function and declares it in the current block.
+func localTypeSpec() {
+	tok := expectIdent()
+	checkRedeclared(tok)
+	def := &typedef{Name: tok.str, resolving: true}
+	if consume("=") {
+		def.Alias = true
+		def.Type = typeOrConstraint()
+	} else {
+		// A type may refer to itself through a pointer.
+		def.Type = &Type{Name: tok.str}
+		scope.Types[tok.str] = def
+		namedType(def.Type, typeOrConstraint())
+	}
+	scope.Types[tok.str] = def
+	def.resolving, def.resolved = false, true
+}
+
+// resolve parses the type spec of a package-level type. A generic type is
+// parsed when it is instantiated instead, and only its constraints are.
+func (def *typedef) resolve() {
+	if def.resolved {
+		return
+	}
+	if g := def.Generic; g != nil {
+		g.declareParams()
+		def.resolved = true
+		instantiateType(g, g.Params)
+		return
+	}
+	tok, ind := token, indirect
+	token, indirect = def.Tok.next, 0
+	def.resolving = true
+	if def.Alias {
+		expect("=")
+		def.Type = typeOrConstraint()
+	} else {
+		namedType(def.Type, typeOrConstraint())
+	}
+	def.resolving, def.resolved = false, true
+	token, indirect = tok, ind
+}
+
+// typ returns the declared type, resolving it first if it is needed.
+func (def *typedef) typ() *Type {
+	switch {
+	case def.resolved:
+	case def.resolving:
+		if def.Alias || indirect == 0 {
+			panic(fmt.Sprintf("invalid recursive type %s", def.Name))
+		}
+	case def.Alias || indirect == 0:
+		def.resolve()
+	}
+	return def.Type
+}
+
+// A varDef is a package-level variable, which is resolved when it is first
+// used, as its type may be inferred from its initializer.
+type varDef struct {
+	Tok  *Token // The name
+	Var  *Var
+	Init *Node // The initializer, or nil
+
+	resolving bool
+}
+
+var globalDefs map[string]*varDef // Package-level variables
+var resolvingVars []*varDef       // The variables being resolved
+
+// initFunc is the function initializing the package, which initializes
+// the package-level variables whose initializers are not constant and then
+// calls the init functions in order.
+var initFunc *Node
+var initFuncs []*Node
+
+// resolve parses the declaration of the package-level variable. The
+// initializer is parsed as a part of the init function.
+func (def *varDef) resolve() *Var {
+	if def.Var != nil {
+		return def.Var
+	}
+	if def.resolving {
+		i := len(resolvingVars) - 1
+		for resolvingVars[i] != def {
+			i--
+		}
+		var path []string
+		for _, d := range resolvingVars[i:] {
+			path = append(path, d.Tok.str)
+		}
+		errorCycle(def.Tok, append(path, def.Tok.str))
+	}
+	def.resolving = true
+	resolvingVars = append(resolvingVars, def)
+	tok, fn, lvars := token, curFunc, locals
+	sc, lit := scope, curLit
+	token = def.Tok.next
+	scope, curLit = nil, nil
+
+	var ty *Type
+	if !peek("=") {
+		ty = parseType()
+	}
+	if consume("=") {
+		curFunc, locals = initFunc, initFunc.Locals
+		n := equality()
+		n.addType()
+		if ty == nil {
+			if n.Type.isNil() {
+				panic("use of untyped nil")
+			}
+			ty = n.Type
+		}
+		def.Init = convert(n, ty, "variable declaration")
+		initFunc.Locals = locals
+	}
+	def.Var = newGVar(def.Tok.str, ty)
+	if n := def.Init; n != nil && (isConst(n) || n.Kind == ND_NIL || isStatic(n) || isStaticRef(n)) {
+		def.Var.Init = n
+	}
+
+	if fn == initFunc {
+		// The initializer of another variable is being parsed.
+		lvars = initFunc.Locals
+	}
+	token, curFunc, locals = tok, fn, lvars
+	scope, curLit = sc, lit
+	resolvingVars = resolvingVars[:len(resolvingVars)-1]
+	def.resolving = false
+	return def.Var
+}
+
+// isStatic reports whether n is an array or a struct literal of constants,
+// which can be laid out in the data section. The elements may also be nil,
+// and slice literals and pointers to literals of constants, which refer to
+// objects laid out in the data section as well.
+func isStatic(n *Node) bool {
+	if n.Kind != ND_COMPLIT || n.Var.OnHeap {
+		return false
+	}
+	return isStaticElems(n)
+}
+
+// isStaticElems reports whether the elements of the literal n are static.
+func isStaticElems(n *Node) bool {
+	for _, a := range n.Body {
+		switch x := a.Rhs; {
+		case isConst(x) || x.Kind == ND_NIL:
+		case x.Kind == ND_COMPLIT:
+			if !isStaticElems(x) {
+				return false
+			}
+		case !isStaticRef(x):
+			return false
+		}
+	}
+	return true
+}
+
+// isStaticRef reports whether n is a slice literal or a pointer to a literal
+// of constants.
+func isStaticRef(n *Node) bool {
+	if n.Kind == ND_SLICE {
+		if n.Args[0] != nil || n.Args[1] != nil || n.Args[2] != nil {
+			return false
+		}
+		n = n.Lhs
+	}
+	return n.Kind == ND_ADDR && n.Lhs.Kind == ND_COMPLIT && isStaticElems(n.Lhs)
+}
+
+// initOrder appends the initialization of the package-level variables with
+// non-constant initializers to the init function. The earliest variable in
+// declaration order not depending on uninitialized variables is initialized
+// first. A variable depends on the variables and the functions referred to
+// in its initializer, and a function on those referred to in its body.
+func initOrder(defs []*varDef) {
+	refs := make(map[string][]string)
+	for _, fn := range code {
+		refs[fn.FunctionName] = references(fn.Block)
+	}
+	pending := make(map[string]bool)
+	var order []*varDef
+	for _, def := range defs {
+		if def.Init != nil && def.Var.Init == nil {
+			refs[def.Var.Name] = references(def.Init)
+			pending[def.Var.Name] = true
+			order = append(order, def)
+		}
+	}
+	for _, def := range order {
+		if path := refCycle(def.Var.Name, refs); path != nil {
+			errorCycle(def.Tok, path)
+		}
+	}
+
+	for len(order) > 0 {
+		for i, def := range order {
+			ready := true
+			for _, v := range varDeps(def.Var.Name, refs) {
+				ready = ready && !pending[v]
+			}
+			if !ready {
+				continue
+			}
+			a := newNode(ND_ASSIGN, newVarNode(def.Var), def.Init)
+			a.addType()
+			initFunc.Block.Body = append(initFunc.Block.Body, a)
+			delete(pending, def.Var.Name)
+			order = append(order[:i], order[i+1:]...)
+			break
+		}
+	}
+	for _, fn := range initFuncs {
+		initFunc.Block.Body = append(initFunc.Block.Body, &Node{Kind: ND_FUNCALL, FunctionName: fn.FunctionName, Type: intType})
+	}
+	escape(initFunc)
+}
+
+// errorCycle reports an initialization cycle, the chain of references path
+// which ends where it starts.
+func errorCycle(tok *Token, path []string) {
+	var chain []string
+	for i := 1; i < len(path); i++ {
+		chain = append(chain, declName(path[i-1])+" refers to "+declName(path[i]))
+	}
+	tok.errorf("initialization cycle: %s", strings.Join(chain, ", "))
+}
+
+// references returns the package-level variables and the functions referred
+// to in n.
+func references(n *Node) []string {
+	var names []string
+	seen := make(map[string]bool)
+	walk(n, func(n *Node) {
+		var name string
+		switch {
+		case n.Kind == ND_VAR && !n.Var.IsLocal:
+			name = n.Var.Name
+		case n.Kind == ND_FUNCALL && funcs[n.FunctionName] != nil, n.Kind == ND_CLOSURE:
+			name = n.FunctionName
+		default:
+			return
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// refCycle returns the chain of references from the variable back to
+// itself, or nil if there is no such chain.
+func refCycle(name string, refs map[string][]string) []string {
+	seen := make(map[string]bool)
+	path := []string{name}
+	var visit func(n string) bool
+	visit = func(n string) bool {
+		for _, r := range refs[n] {
+			if r == name {
+				path = append(path, r)
+				return true
+			}
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			path = append(path, r)
+			if visit(r) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	if visit(name) {
+		return path
+	}
+	return nil
+}
+
+// varDeps returns the variables the variable depends on, through the
+// functions referred to in its initializer.
+func varDeps(name string, refs map[string][]string) []string {
+	var deps []string
+	seen := make(map[string]bool)
+	var visit func(n string)
+	visit = func(n string) {
+		for _, r := range refs[n] {
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			if globalDefs[declName(r)] != nil {
+				deps = append(deps, r)
+			} else {
+				visit(r)
+			}
+		}
+	}
+	visit(name)
+	return deps
+}
+
+func equality() *Node {
+	node := relational()
+
+	for {
+		if consume("==") {
+			node = newNode(ND_EQ, node, relational())
+		} else if consume("!=") {
+			node = newNode(ND_NE, node, relational())
+		} else {
+			return node
+		}
+	}
+}
+
+func relational() *Node {
+	node := add()
+
+	for {
+		if consume("<") {
+			node = newNode(ND_LT, node, add())
+		} else if consume("<=") {
+			node = newNode(ND_LE, node, add())
+		} else if consume(">") {
+			node = newNode(ND_LT, add(), node)
+		} else if consume(">=") {
+			node = newNode(ND_LE, add(), node)
+		} else {
+			return node
+		}
+	}
+}
+
+func add() *Node {
+	node := mul()
+
+	for {
+		if consume("+") {
+			node = newNode(ND_ADD, node, mul())
+		} else if consume("-") {
+			node = newNode(ND_SUB, node, mul())
+		} else {
+			return node
+		}
+	}
+}
+
+func mul() *Node {
+	node := unary()
+
+	for {
+		if consume("*") {
+			node = newNode(ND_MUL, node, unary())
+		} else if consume("/") {
+			node = newNode(ND_DIV, node, unary())
+		} else {
+			return node
+		}
+	}
+}
+
+func unary() *Node {
+	if consume("+") {
+		return unary()
+	} else if consume("-") {
+		return newNode(ND_SUB, newNodeNum(0), unary())
+	} else if consume("&") {
+		return newNode(ND_ADDR, unary(), nil)
+	} else if consume("*") {
+		return newNode(ND_DEREF, unary(), nil)
+	} else if consume("<-") {
+		return newNode(ND_RECV, unary(), nil)
+	}
+	return postfix()
+}
+
+func postfix() *Node {
+	node := primary()
+	for {
+		if peek("[") {
+			node = index(node)
+		} else if consume(".") {
+			if consume("(") {
+				node = typeAssertion(node)
+			} else {
+				node = selector(node)
+			}
+		} else if consume("(") {
+			// A call through a function value
+			node = &Node{Kind: ND_FUNCALL, Lhs: node, Args: args()}
+		} else {
+			break
+		}
+	}
+	if consume("++") {
+		return newNode(ND_INC, node, nil)
+	} else if consume("--") {
+		return newNode(ND_DEC, node, nil)
+	}
+	return node
+}
+
+func index(base *Node) *Node {
+	if !consume("[") {
+		return base
+	}
+	var i *Node
+	if !peek(":") {
+		i = expr()
+	}
+	if consume(":") {
+		return index(sliceExpr(base, i))
+	}
+	expect("]")
+	node := newNode(ND_INDEX, base, i)
+	return index(node)
+}
+
+// sliceExpr parses the rest of the slice expression a[lo:hi] or
+// a[lo:hi:max], whose indices may be nil if they are omitted.
+func sliceExpr(a *Node, lo *Node) *Node {
+	node := &Node{Kind: ND_SLICE, Lhs: a, Args: []*Node{lo, nil, nil}}
+	if !peek("]") && !peek(":") {
+		node.Args[1] = expr()
+	}
+	if consume(":") {
+		if node.Args[1] == nil {
+			panic("middle index required in 3-index slice")
+		}
+		if peek("]") {
+			panic("final index required in 3-index slice")
+		}
+		node.Args[2] = expr()
+	}
+	expect("]")
+	return node
+}
+
+// typeAssertion parses the type of the type assertion x.(T), or x.(type)
+// in a type switch.
+func typeAssertion(x *Node) *Node {
+	if consume("type") {
+		expect(")")
+		return &Node{Kind: ND_ASSERT, Lhs: x}
+	}
+	ty := parseType()
+	expect(")")
+	return assertion(x, ty)
+}
+
+// assertion returns the node asserting that the dynamic type of x is ty.
+func assertion(x *Node, ty *Type) *Node {
+	x.addType()
+	if !x.Type.isInterface() {
+		panic(fmt.Sprintf("invalid type assertion: non-interface type %s on left", x.Type))
+	}
+	if !ty.isInterface() {
+		if m := missingMethod(ty, x.Type); m != "" {
+			panic(fmt.Sprintf("impossible type assertion: %s does not implement %s (missing method %s)", ty, x.Type, m))
+		}
+	}
+	node := &Node{Kind: ND_ASSERT, Lhs: x, Type: ty}
+	if ty.isAggregate() {
+		node.Var = newTemp(ty)
+	}
+	return node
+}
+
+// selector parses a field selector or a method call on x.
+func selector(x *Node) *Node {
+	tok := expectIdent()
+	x.addType()
+
+	// The methods of a type parameter are those of its constraint.
+	if x.Type.isInterface() || x.Type.Kind == TY_PARAM {
+		i := x.Type.findIMethod(tok.str)
+		if i < 0 {
+			panic(fmt.Sprintf("%s.%s undefined (type %s has no method %s)", x.Type, tok.str, x.Type, tok.str))
+		}
+		expect("(")
+		return &Node{
+			Kind:         ND_ICALL,
+			FunctionName: tok.str,
+			Lhs:          x,
+			Val:          i,
+			Args:         args(),
+		}
+	}
+
+	sel, ambiguous := x.Type.lookup(tok.str)
+	if ambiguous {
+		panic(fmt.Sprintf("ambiguous selector %s.%s", x.Type, tok.str))
+	}
+	if sel == nil {
+		panic(fmt.Sprintf("%s.%s undefined (type %s has no field or method %s)", x.Type, tok.str, x.Type, tok.str))
+	}
+	if sel.Field != nil && sel.Owner.isForeign() && !isExported(tok.str) {
+		panic(fmt.Sprintf("%s.%s undefined (cannot refer to unexported field %s)", x.Type, tok.str, tok.str))
+	}
+	x = promote(x, sel.Path)
+
+	if sel.IMethod >= 0 {
+		expect("(")
+		return &Node{
+			Kind:         ND_ICALL,
+			FunctionName: tok.str,
+			Lhs:          x,
+			Val:          sel.IMethod,
+			Args:         args(),
+		}
+	}
+
+	if m := sel.Method; m != nil {
+		// The receiver is addressed or dereferenced as the method requires.
+		recv := m.Args[0].Type
+		if recv.isPointer() && !x.Type.isPointer() {
+			if !addressable(x) {
+				panic(fmt.Sprintf("cannot call pointer method %s on %s", tok.str, x.Type))
+			}
+			x = newNode(ND_ADDR, x, nil)
+		} else if !recv.isPointer() && x.Type.isPointer() {
+			x = newNode(ND_DEREF, x, nil)
+		}
+		expect("(")
+		return &Node{
+			Kind:         ND_FUNCALL,
+			FunctionName: m.FunctionName,
+			Args:         append([]*Node{x}, args()...),
+		}
+	}
+
+	if x.Type.isPointer() {
+		x = newNode(ND_DEREF, x, nil)
+	}
+	node := newNode(ND_MEMBER, x, nil)
+	node.Member = sel.Field
+	return node
+}
+
+// promote selects the embedded fields of the path from x, through which a
+// field or a method of an embedded type is promoted.
+func promote(x *Node, path []*Field) *Node {
+	for _, f := range path {
+		if x.Type.isPointer() {
+			x = newNode(ND_DEREF, x, nil)
+		}
+		x = newNode(ND_MEMBER, x, nil)
+		x.Member = f
+		x.addType()
+	}
+	return x
 }
 
 func primary() *Node {
@@ -352,12 +2833,102 @@ func primary() *Node {
 		return node
 	}
 
+	if peek("func") {
+		return funcLiteral()
+	}
+
+	// Array literal with the length given by the number of the elements
+	if peek("[") && token.next.isReserved() && token.next.str == "..." {
+		expect("[")
+		expect("...")
+		expect("]")
+		elem := parseType()
+		elems, n := arrayElems(elem, -1)
+		return arrayLit(arrayOf(elem, uint(n)), elems)
+	}
+
+	// Composite literal, or conversion to a predeclared type or a type
+	// literal
+	if _, ok := typeNames[token.str]; ok && token.isReserved() || peek("[") || peek("map") || peek("struct") {
+		ty := parseType()
+		if peek("{") && !ty.isInterface() {
+			return compositeLit(ty)
+		}
+		return conversion(ty)
+	}
+
 	if tok := consumeIdent(); tok != nil {
-		// Function call
-		if consume("(") {
+		// Variable declaration
+		if peek(":=") && scope.Vars[tok.str] == nil {
+			expect(":=")
+			rhs := equality()
+			rhs.addType()
+			if rhs.Type.isNil() {
+				panic("use of untyped nil")
+			}
+			if rhs.Kind == ND_NUM && !rhs.Typed {
+				checkOverflow(rhs, intType)
+			}
+			node := newNode(ND_ASSIGN, newLVarNode(tok.str, rhs.Type), rhs)
+			return node
+		}
+
+		// Qualified identifier of an imported package
+		if imp := findImport(tok.str); imp != nil && peek(".") && tok.findLVar() == nil {
+			if ty := stdTypes[imp.Tok.str][token.next.str]; ty != nil {
+				ty = qualifiedType(tok, imp)
+				if peek("{") {
+					return compositeLit(ty)
+				}
+				return conversion(ty)
+			}
+			imp.Used = true
+			expect(".")
+			name := expectIdent()
+			fn := stdFuncs[imp.Tok.str][name.str]
+			if fn == nil {
+				name.errorf("undefined: %s.%s", tok.str, name.str)
+			}
+			expect("(")
+			return &Node{Kind: ND_FUNCALL, FunctionName: fn.FunctionName, Args: args()}
+		}
+
+		// Composite literal or conversion
+		if def := tok.findTypedef(); def != nil {
+			ty := typeName(def)
+			if peek("{") {
+				return compositeLit(ty)
+			}
+			return conversion(ty)
+		}
+
+		// Instantiation of a generic function, which may be called with
+		// the rest of the type arguments inferred
+		if g := generics[tok.str]; g != nil && peek("[") && tok.findLVar() == nil {
+			targs := typeArgs()
+			if consume("(") {
+				return &Node{Kind: ND_FUNCALL, FunctionName: pkgSymbol(tok.str), TypeArgs: targs, Args: args()}
+			}
+			fn := instantiateFunc(g, targs)
+			return &Node{Kind: ND_CLOSURE, FunctionName: fn.FunctionName, Type: funcTypeOf(fn)}
+		}
+
+		// Builtin function call
+		if kind, ok := builtins[tok.str]; ok && peek("(") && tok.findLVar() == nil && globals[tok.str] == nil && funcs[pkgSymbol(tok.str)] == nil && generics[tok.str] == nil {
+			expect("(")
+			return builtin(kind)
+		}
+
+		// Function call, unless the name is of a variable holding a
+		// function value
+		if peek("(") && tok.findLVar() == nil && globalDefs[tok.str] == nil {
+			expect("(")
+			if tok.str == "init" {
+				tok.errorf("undefined: init")
+			}
 			node := Node{
 				Kind:         ND_FUNCALL,
-				FunctionName: tok.str,
+				FunctionName: pkgSymbol(tok.str),
 				Args:         args(),
 			}
 			return &node
@@ -365,42 +2936,372 @@ func primary() *Node {
 
 		// Variables
 		lvar := tok.findLVar()
-		if lvar != nil {
+		if lvar != nil && lvar.Const != nil {
+			return lvar.Const.clone()
+		} else if lvar != nil {
+			lvar = capture(lvar)
 			node := &Node{
 				Kind: ND_VAR,
 				Var:  lvar,
 				Type: lvar.Type,
 			}
 			return node
-		} else if gvar := globals[tok.str]; gvar != nil {
+		} else if def := globalDefs[tok.str]; def != nil {
+			gvar := def.resolve()
 			node := &Node{
 				Kind: ND_VAR,
 				Var:  gvar,
 				Type: gvar.Type,
 			}
 			return node
-		} else {
-			if !consume(":=") {
-				panic(fmt.Sprintf("undeclared name: %s %s %s", tok.str, tok.next.str, tok.next.next.str))
+		} else if def := consts[tok.str]; def != nil {
+			return def.value().clone()
+		} else if fn := funcs[pkgSymbol(tok.str)]; fn != nil {
+			// A function value
+			return &Node{Kind: ND_CLOSURE, FunctionName: fn.FunctionName, Type: funcTypeOf(fn)}
+		} else if generics[tok.str] != nil {
+			panic(fmt.Sprintf("cannot use generic function %s without instantiation", tok.str))
+		} else if tok.str == "iota" {
+			if iotaValue < 0 {
+				tok.errorf("cannot use iota outside constant declaration")
 			}
-			rhs := equality()
-			rhs.addType()
-			node := newNode(ND_ASSIGN, newLVarNode(tok.str, rhs.Type), rhs)
-			return node
+			return newNodeNum(iotaValue)
+		} else {
+			panic(fmt.Sprintf("undeclared name: %s", tok.str))
 		}
 	}
 
+	if consume("nil") {
+		return &Node{Kind: ND_NIL, Type: nilType}
+	}
+
 	if token.kind == TK_STR {
-		ty := arrayOf(byteType, uint(token.len))
-		v := newGVar(labeler.New(), ty)
-		v.Content = token.str
-		v.Len = token.len
+		v := stringLiteral(token.str)
 		token = token.next
-		return newVarNode(v)
+		return &Node{Kind: ND_STR, Var: v}
 	}
 
 	// If not so, it should be a number
-	return newNodeNum(expectNumber())
+	tok := token
+	n := newNodeNum(expectNumber())
+	if v, _ := new(big.Int).SetString(tok.str, 10); !v.IsInt64() {
+		n.Const = v
+	}
+	return n
+}
+
+// stringLiteral returns the variable of a new string literal.
+func stringLiteral(s string) *Var {
+	v := &Var{
+		Name:    labeler.New(),
+		Type:    stringType,
+		Content: s,
+		Len:     len(s),
+	}
+	literals = append(literals, v)
+	return v
+}
+
+var builtins = map[string]NodeKind{
+	"len":     ND_LEN,
+	"cap":     ND_CAP,
+	"append":  ND_APPEND,
+	"copy":    ND_COPY,
+	"make":    ND_MAKE,
+	"new":     ND_NEW,
+	"delete":  ND_DELETE,
+	"panic":   ND_PANIC,
+	"recover": ND_RECOVER,
+	"close":   ND_CLOSE,
+}
+
+// builtin parses the arguments of a call to a builtin function.
+func builtin(kind NodeKind) *Node {
+	node := &Node{Kind: kind}
+	switch kind {
+	case ND_APPEND:
+		// The elements are appended one by one, or all at once from
+		// a slice in append(s, t...).
+		node.Lhs = assign()
+		for consume(",") && !peek(")") {
+			x := assign()
+			if consume("...") {
+				node.Rhs = x
+				break
+			}
+			node.Args = append(node.Args, x)
+		}
+		if node.Rhs != nil && node.Args != nil {
+			panic("can only use ... with final argument in list")
+		}
+		expect(")")
+		return node
+	case ND_COPY, ND_DELETE:
+		node.Lhs = assign()
+		expect(",")
+		node.Rhs = assign()
+	case ND_MAKE:
+		// A slice is made of the length and the capacity, which defaults
+		// to the length. The capacity of a map is only a hint for the
+		// size of its table, and that of a channel is the size of its
+		// buffer, which is unbuffered by default.
+		ty := parseType()
+		if !ty.isMap() && !ty.isSlice() && !ty.isChan() {
+			panic(fmt.Sprintf("invalid argument: cannot make %s; type must be slice, map, or channel", ty))
+		}
+		var sizes []*Node
+		for consume(",") && !peek(")") {
+			n := assign()
+			n.addType()
+			if !n.Type.isInteger() {
+				panic(fmt.Sprintf("cannot convert type %s to type int in argument to make", n.Type))
+			}
+			if n.Kind == ND_NUM && constOf(n).Sign() < 0 {
+				panic(fmt.Sprintf("invalid argument: index %s must not be negative", constOf(n)))
+			}
+			if n.Kind == ND_NUM && n.Const != nil {
+				checkOverflow(n, intType)
+			}
+			sizes = append(sizes, n)
+		}
+		min := 1
+		if ty.isSlice() {
+			min = 2
+			node.Var = newTemp(ty)
+		}
+		if len(sizes)+1 < min || len(sizes)+1 > min+1 {
+			panic(fmt.Sprintf("invalid operation: make(%s) expects %d or %d arguments; found %d", ty, min, min+1, len(sizes)+1))
+		}
+		if len(sizes) == 2 {
+			if sizes[0].Kind == ND_NUM && sizes[1].Kind == ND_NUM && sizes[0].Val > sizes[1].Val {
+				panic("invalid argument: length and capacity swapped")
+			}
+			node.Rhs = sizes[1]
+		}
+		if len(sizes) > 0 {
+			node.Lhs = sizes[0]
+		}
+		node.Type = ty
+		expect(")")
+		return node
+	case ND_NEW:
+		// new(T) is the address of a new variable of type T, which is
+		// allocated on the heap if it escapes.
+		ty := parseType()
+		expect(")")
+		return &Node{Kind: ND_NEW, Type: pointerTo(ty), Var: newTemp(ty)}
+	case ND_RECOVER:
+		expect(")")
+		return node
+	default:
+		node.Lhs = assign()
+	}
+	consume(",")
+	expect(")")
+	return node
+}
+
+// mapLiteral parses the elements of a map literal of type ty, which results
+// in a new map in a temporary with the elements assigned in order.
+func mapLiteral(ty *Type) *Node {
+	expect("{")
+	m := newVarNode(newTemp(ty))
+	node := &Node{Kind: ND_MAPLIT, Type: ty, Var: m.Var}
+	keys := make(map[string]bool)
+	for !consume("}") {
+		k := element(ty.Key)
+		expect(":")
+		a := newNode(ND_ASSIGN, newNode(ND_INDEX, m, k), element(ty.Ref))
+		a.addType()
+		node.Body = append(node.Body, a)
+
+		var key string
+		switch k := a.Lhs.Rhs; k.Kind {
+		case ND_NUM:
+			key = fmt.Sprint(k.Val)
+		case ND_STR:
+			key = fmt.Sprintf("%q", k.Var.Content)
+		}
+		if key != "" && keys[key] {
+			panic(fmt.Sprintf("duplicate key %s in map literal", key))
+		}
+		keys[key] = true
+		if !consume(",") {
+			expect("}")
+			break
+		}
+	}
+	return node
+}
+
+// compositeLit parses a composite literal of type ty. The value of an
+// array or a struct literal is built in a temporary, which is zeroed and
+// then assigned the elements in order.
+func compositeLit(ty *Type) *Node {
+	switch {
+	case ty.isMap():
+		return mapLiteral(ty)
+	case ty.isStruct():
+		return structLit(ty)
+	case ty.isArray():
+		elems, _ := arrayElems(ty.Ref, int(ty.ArrayLen))
+		return arrayLit(ty, elems)
+	case ty.isSlice():
+		// A slice literal refers to a new array holding the elements.
+		elems, n := arrayElems(ty.Ref, -1)
+		lit := arrayLit(arrayOf(ty.Ref, uint(n)), elems)
+		arr := newNode(ND_ADDR, lit, nil)
+		arr.addType()
+		return &Node{Kind: ND_SLICE, Lhs: arr, Args: make([]*Node, 3), Type: ty, Var: newTemp(ty)}
+	}
+	panic(fmt.Sprintf("invalid composite literal type %s", ty))
+}
+
+// element parses an element of a composite literal, which is of type ty.
+// The type of a composite literal may be elided, as may the & of a
+// pointer to one.
+func element(ty *Type) *Node {
+	if !peek("{") {
+		return expr()
+	}
+	if ty.isPointer() {
+		lit := compositeLit(ty.Ref)
+		if lit.Kind != ND_COMPLIT {
+			panic(fmt.Sprintf("invalid composite literal type %s", ty))
+		}
+		return newNode(ND_ADDR, lit, nil)
+	}
+	return compositeLit(ty)
+}
+
+// arrayElem is an element of an array or a slice literal at the index.
+type arrayElem struct {
+	Index int
+	Val   *Node
+}
+
+// arrayElems parses the elements of an array or a slice literal whose
+// elements are of type elem. An element may be keyed by a constant index,
+// and the others follow the previous one. It returns the elements and the
+// length, which is checked against bound unless it is -1.
+func arrayElems(elem *Type, bound int) ([]arrayElem, int) {
+	expect("{")
+	var elems []arrayElem
+	seen := make(map[int]bool)
+	i, n := 0, 0
+	for !consume("}") {
+		tok := token
+		x := element(elem)
+		if consume(":") {
+			x.addType()
+			if x.Kind != ND_NUM || !x.Type.isInteger() || x.Const != nil || x.Val < 0 {
+				tok.errorf("index must be non-negative integer constant")
+			}
+			i = x.Val
+			x = element(elem)
+		}
+		if bound >= 0 && i >= bound {
+			tok.errorf("array index %d out of bounds [0:%d]", i, bound)
+		}
+		if seen[i] {
+			tok.errorf("duplicate index %d in array or slice literal", i)
+		}
+		seen[i] = true
+		elems = append(elems, arrayElem{i, convert(x, elem, "array or slice literal")})
+		i++
+		if i > n {
+			n = i
+		}
+		if !consume(",") {
+			expect("}")
+			break
+		}
+	}
+	return elems, n
+}
+
+// arrayLit returns the literal of the array type ty with the elements.
+func arrayLit(ty *Type, elems []arrayElem) *Node {
+	v := newVarNode(newTemp(ty))
+	node := &Node{Kind: ND_COMPLIT, Type: ty, Var: v.Var}
+	for _, e := range elems {
+		a := newNode(ND_ASSIGN, newNode(ND_INDEX, v, newNodeNum(e.Index)), e.Val)
+		a.addType()
+		node.Body = append(node.Body, a)
+	}
+	return node
+}
+
+// structLit parses the elements of a literal of the struct type ty, which
+// are either all keyed by the field names or the values of all the fields
+// in order.
+func structLit(ty *Type) *Node {
+	expect("{")
+	v := newVarNode(newTemp(ty))
+	node := &Node{Kind: ND_COMPLIT, Type: ty, Var: v.Var}
+	keyed := token.kind == TK_IDENT && token.next.isReserved() && token.next.str == ":"
+	seen := make(map[string]bool)
+	for i := 0; !consume("}"); i++ {
+		tok := token
+		var f *Field
+		if token.kind == TK_IDENT && token.next.isReserved() && token.next.str == ":" {
+			if !keyed {
+				tok.errorf("mixture of field:value and value elements in struct literal")
+			}
+			token = token.next.next
+			if f = ty.findField(tok.str); f == nil {
+				tok.errorf("unknown field %s in struct literal of type %s", tok.str, ty)
+			}
+			if seen[f.Name] {
+				tok.errorf("duplicate field name %s in struct literal", f.Name)
+			}
+			seen[f.Name] = true
+		} else if keyed {
+			tok.errorf("mixture of field:value and value elements in struct literal")
+		} else if i < len(ty.Fields) {
+			f = ty.Fields[i]
+		} else {
+			tok.errorf("too many values in struct literal of type %s", ty)
+		}
+		x := convert(element(f.Type), f.Type, "struct literal")
+		m := newNode(ND_MEMBER, v, nil)
+		m.Member = f
+		a := newNode(ND_ASSIGN, m, x)
+		a.addType()
+		node.Body = append(node.Body, a)
+		if !consume(",") {
+			expect("}")
+			break
+		}
+	}
+	if !keyed && len(node.Body) > 0 && len(node.Body) < len(ty.Fields) {
+		panic(fmt.Sprintf("too few values in struct literal of type %s", ty))
+	}
+	return node
+}
+
+// conversion parses the operand of a conversion to type ty.
+func conversion(ty *Type) *Node {
+	expect("(")
+	node := expr()
+	expect(")")
+	node.addType()
+	if !convertible(ty, node) {
+		panic(fmt.Sprintf("cannot convert type %s to type %s", node.Type, ty))
+	}
+	// A constant converted to a type of the same kind is a typed constant.
+	if node.Kind == ND_NUM && ty.isInteger() || node.Kind == ND_STR && ty.isString() {
+		return typedConst(node, ty)
+	}
+	if ty.isInterface() && !identical(ty.underlying(), node.Type.underlying()) {
+		return toInterface(node, ty)
+	}
+	conv := &Node{Kind: ND_CONV, Lhs: node, Type: ty}
+	if node.Type.isInteger() && ty.isString() || isStringConv(node.Type, ty) || isStringConv(ty, node.Type) {
+		// The result refers to a copy made by the runtime.
+		conv.Var = newTemp(ty)
+	}
+	return conv
 }
 
 func args() []*Node {
@@ -415,15 +3316,121 @@ func args() []*Node {
 }
 
 func definedArgs() []*Node {
-	expect("(")
+	names, types := params()
 	args := []*Node{}
+	for i, name := range names {
+		args = append(args, newLVarNode(name, types[i]))
+	}
+	return args
+}
+
+// params parses a parenthesized parameter list and returns the names and
+// the types of the parameters. Either all or none of the parameters are
+// named, and consecutive names may share a type as in (a, b int).
+func params() ([]string, []*Type) {
+	expect("(")
+	var names []*Token
+	var types []*Type
+	named := false
 	for !consume(")") {
-		if tok := consumeIdent(); tok != nil {
-			args = append(args, newLVarNode(tok.str, parseType()))
+		tok := consumeIdent()
+		if tok != nil && !peek(",") && !peek(")") {
+			named = true
+			names = append(names, tok)
+			types = append(types, parseType())
+		} else if tok != nil {
+			// Either a name sharing the type that follows, or a type.
+			names = append(names, tok)
+			types = append(types, nil)
+		} else {
+			names = append(names, nil)
+			types = append(types, parseType())
 		}
 		consume(",")
 	}
-	return args
+
+	strs := make([]string, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		switch {
+		case !named && types[i] == nil:
+			tok := token
+			token = names[i]
+			types[i] = parseType()
+			token = tok
+			strs[i] = "_"
+		case !named:
+			strs[i] = "_"
+		case names[i] == nil:
+			panic("mixed named and unnamed parameters")
+		case types[i] == nil:
+			if i == len(names)-1 {
+				panic("mixed named and unnamed parameters")
+			}
+			types[i] = types[i+1]
+			fallthrough
+		default:
+			strs[i] = names[i].str
+		}
+	}
+	return strs, types
+}
+
+// isTypeStart reports whether the current token can begin a type.
+func isTypeStart() bool {
+	if _, ok := typeNames[token.str]; ok && token.isReserved() {
+		return true
+	}
+	if token.kind == TK_IDENT {
+		return !token.next.isReserved() || token.next.str != "("
+	}
+	return peek("*") || peek("[") || peek("struct") || peek("interface") || peek("map") || peek("func") ||
+		peek("chan") || peek("<-")
+}
+
+// funcType parses the parameters and the result of a function signature.
+func funcType() *Type {
+	// The parameters and the result are only referred to by the function.
+	indirect++
+	_, types := params()
+	ty := &Type{Kind: TY_FUNC, Params: types}
+	if peek("(") {
+		_, types := params()
+		ty.Result = singleResult(types)
+	} else if isTypeStart() {
+		ty.Result = parseType()
+	}
+	indirect--
+	return ty
+}
+
+// result parses the result of the signature of the function fn, which is
+// either a type or a parenthesized parameter list of one, possibly named,
+// result.
+func result(fn *Node) {
+	if peek("{") {
+		return
+	}
+	if !peek("(") {
+		fn.Type = parseType()
+		return
+	}
+	names, types := params()
+	fn.Type = singleResult(types)
+	if fn.Type != nil && names[0] != "_" {
+		fn.Result = newLVar(names[0], fn.Type)
+	}
+}
+
+// singleResult returns the type of the result in the result list types, or
+// nil if it is empty.
+func singleResult(types []*Type) *Type {
+	switch len(types) {
+	case 0:
+		return nil
+	case 1:
+		return types[0]
+	}
+	panic("multiple return values are not supported")
 }
 
 func newVarNode(v *Var) *Node {
@@ -442,13 +3449,23 @@ func newGVarNode(name string, ty *Type) *Node {
 
 func newGVar(name string, ty *Type) *Var {
 	gvar := &Var{
-		Name: name,
+		Name: pkgSymbol(name),
 		Type: ty,
 	}
 	globals[name] = gvar
 	return gvar
 }
 
+// newTemp returns an unnamed local variable holding an intermediate value.
+func newTemp(ty *Type) *Var {
+	lvar := &Var{
+		Type:    ty,
+		IsLocal: true,
+	}
+	locals = &VarList{locals, lvar}
+	return lvar
+}
+
 func newLVarNode(name string, ty *Type) *Node {
 	v := newLVar(name, ty)
 	return newVarNode(v)
@@ -461,24 +3478,322 @@ func newLVar(name string, ty *Type) *Var {
 		IsLocal: true,
 	}
 	locals = &VarList{locals, lvar}
+	if scope != nil {
+		scope.Vars[name] = lvar
+		scope.Decls = append(scope.Decls, name)
+	}
 	return lvar
 }
 
 func array() *Type {
 	expect("[")
-	l := expectNumber()
+	if consume("]") {
+		// The elements are referred to through the slice.
+		indirect++
+		elem := parseType()
+		indirect--
+		return sliceOf(elem)
+	}
+	if discovering {
+		skipExprs()
+		expect("]")
+		return arrayOf(parseType(), 0)
+	}
+	tok := token
+	l := expr()
+	l.addType()
+	switch {
+	case !isConst(l):
+		tok.errorf("array length must be constant")
+	case l.Kind != ND_NUM:
+		tok.errorf("array length must be integer")
+	case l.Const != nil || l.Val < 0:
+		tok.errorf("invalid array length %s", constOf(l))
+	}
 	expect("]")
-	return arrayOf(parseType(), uint(l))
+	return arrayOf(parseType(), uint(l.Val))
+}
+
+// structType parses the field declarations of a struct type.
+func structType() *Type {
+	expect("struct")
+	expect("{")
+	var fields []*Field
+	add := func(f *Field) {
+		for _, f2 := range fields {
+			if f2.Name == f.Name {
+				panic(fmt.Sprintf("duplicate field %s", f.Name))
+			}
+		}
+		fields = append(fields, f)
+	}
+	for !consume("}") {
+		if name := embeddedField(); name != "" {
+			add(&Field{Name: name, Type: parseType(), Embedded: true})
+			consume(";")
+			continue
+		}
+		var names []*Token
+		for {
+			names = append(names, expectIdent())
+			if !consume(",") {
+				break
+			}
+		}
+		ty := parseType()
+		for _, name := range names {
+			add(&Field{Name: name.str, Type: ty})
+		}
+		consume(";")
+	}
+	return structOf(fields)
+}
+
+// embeddedField returns the name of the embedded field declared by the
+// next tokens, [*]TypeName[TypeArgs], which is the type name without its
+// package and type arguments, or "" if they declare named fields.
+func embeddedField() string {
+	tok := token
+	if tok.isReserved() && tok.str == "*" {
+		tok = tok.next
+	}
+	if tok.kind != TK_IDENT {
+		return ""
+	}
+	name, def := tok.str, tok.findTypedef()
+	if tok.next.isReserved() && tok.next.str == "." {
+		tok = tok.next.next
+		name, def = tok.str, nil
+	}
+	tok = tok.next
+	if tok.isReserved() && tok.str == "[" {
+		// A field of an array type has its element type after the ']'.
+		for depth := 0; ; tok = tok.next {
+			if tok.kind == TK_EOF {
+				return ""
+			}
+			if tok.isReserved() && tok.str == "[" {
+				depth++
+			} else if tok.isReserved() && tok.str == "]" {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		tok = tok.next
+	}
+	if !tok.isReserved() || tok.str != ";" && tok.str != "}" {
+		return ""
+	}
+	if def != nil && def.Param {
+		panic("embedded field type cannot be a (pointer to a) type parameter")
+	}
+	return name
+}
+
+// interfaceType parses the method specs of an interface type, and the
+// embedded interfaces and unions of terms restricting its type set.
+func interfaceType() *Type {
+	expect("interface")
+	expect("{")
+	var methods []*Field
+	add := func(m *Field, embedded bool) {
+		for _, m2 := range methods {
+			if m2.Name != m.Name {
+				continue
+			}
+			// The same method may be embedded more than once.
+			if embedded && identical(m2.Type, m.Type) {
+				return
+			}
+			panic(fmt.Sprintf("duplicate method %s", m.Name))
+		}
+		methods = append(methods, m)
+	}
+	var terms []*Term
+	restricted, comparable := false, false
+	restrict := func(union []*Term) {
+		if restricted {
+			union = intersectTerms(terms, union)
+		}
+		terms, restricted = union, true
+	}
+	for !consume("}") {
+		if tok := consumeIdent(); tok != nil && peek("(") {
+			add(&Field{Name: tok.str, Type: funcType()}, false)
+			consume(";")
+			continue
+		} else if tok != nil {
+			token = tok
+		}
+		if union := typeTerms(); len(union) == 1 && !union[0].Tilde && union[0].Type.isInterface() {
+			// An embedded interface
+			ty := union[0].Type
+			for _, m := range ty.IMethods {
+				add(m, true)
+			}
+			if len(ty.Terms) > 0 {
+				restrict(ty.Terms)
+			}
+			comparable = comparable || ty.Comparable
+		} else {
+			restrict(union)
+		}
+		consume(";")
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Name < methods[j].Name
+	})
+	return &Type{Kind: TY_INTERFACE, IMethods: methods, Terms: terms, Comparable: comparable}
 }
 
 func parseType() *Type {
+	ty := typeOrConstraint()
+	if !discovering && ty.isConstraint() {
+		panic(fmt.Sprintf("cannot use type %s outside a type constraint: interface contains type constraints", ty))
+	}
+	return ty
+}
+
+// typeOrConstraint parses a type, which may be a constraint interface.
+func typeOrConstraint() *Type {
 	if peek("[") {
 		return array()
 	}
+	if peek("struct") {
+		return structType()
+	}
+	if peek("interface") {
+		return interfaceType()
+	}
+	if consume("map") {
+		// The elements are referred to through the hash table.
+		expect("[")
+		key := parseType()
+		expect("]")
+		indirect++
+		elem := parseType()
+		indirect--
+		if !discovering && !key.isHashable() {
+			panic(fmt.Sprintf("invalid map key type %s", key))
+		}
+		return mapOf(key, elem)
+	}
 	if consume("*") {
+		indirect++
 		ty := parseType()
-		return &Type{TY_POINTER, ty, 0}
+		indirect--
+		return pointerTo(ty)
+	}
+	if consume("<-") {
+		expect("chan")
+		return chanOf(chanElem(), CHAN_RECV)
+	}
+	if consume("chan") {
+		if consume("<-") {
+			return chanOf(chanElem(), CHAN_SEND)
+		}
+		return chanOf(chanElem(), CHAN_BOTH)
+	}
+	if consume("func") {
+		return funcType()
+	}
+	if tok := consumeIdent(); tok != nil {
+		if imp := findImport(tok.str); imp != nil && peek(".") {
+			return qualifiedType(tok, imp)
+		}
+		if discovering {
+			if peek("[") {
+				typeArgs()
+			}
+			return intType
+		}
+		def := tok.findTypedef()
+		if def == nil && tok.str == "comparable" {
+			return comparableType
+		}
+		if kind, ok := unsupportedTypes[tok.str]; def == nil && ok {
+			panic(fmt.Sprintf("%s type %s is not supported", kind, tok.str))
+		}
+		if def == nil {
+			panic(fmt.Sprintf("undefined: %s", tok.str))
+		}
+		return typeName(def)
 	}
 	kind := expectType()
-	return &Type{kind, nil, 0}
+	return &Type{Kind: kind}
+}
+
+// typeName returns the type declared by def, instantiating a generic type
+// with the type arguments that follow.
+func typeName(def *typedef) *Type {
+	if def.Generic == nil {
+		return def.typ()
+	}
+	if !peek("[") {
+		panic(fmt.Sprintf("cannot use generic type %s without instantiation", def.Generic))
+	}
+	def.resolve()
+	return instantiateType(def.Generic, typeArgs())
+}
+
+// typeTerms parses a union of terms in a constraint. A constraint interface
+// in a union of several terms stands for its terms.
+func typeTerms() []*Term {
+	var terms []*Term
+	for {
+		tilde := consume("~")
+		ty := typeOrConstraint()
+		if tilde && !discovering && ty.isNamed() {
+			panic(fmt.Sprintf("invalid use of ~ (underlying type of %s is %s)", ty, ty.underlying()))
+		}
+		terms = append(terms, &Term{Tilde: tilde, Type: ty})
+		if !consume("|") {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms
+	}
+	var union []*Term
+	for _, t := range terms {
+		if !t.Tilde && len(t.Type.Terms) > 0 {
+			union = append(union, t.Type.Terms...)
+		} else {
+			union = append(union, t)
+		}
+	}
+	return union
+}
+
+// constraint parses the constraint of a type parameter, which is an
+// interface, or a union of terms standing for the interface of them.
+func constraint() *Type {
+	terms := typeTerms()
+	if len(terms) == 1 && !terms[0].Tilde && terms[0].Type.isInterface() {
+		return terms[0].Type
+	}
+	return &Type{Kind: TY_INTERFACE, Terms: terms}
+}
+
+// qualifiedType parses the rest of the qualified name of a type of the
+// imported package imp, whose name is tok.
+func qualifiedType(tok *Token, imp *importSpec) *Type {
+	imp.Used = true
+	expect(".")
+	name := expectIdent()
+	ty := stdTypes[imp.Tok.str][name.str]
+	if ty == nil {
+		name.errorf("undefined: %s.%s", tok.str, name.str)
+	}
+	return ty
+}
+
+// chanElem parses the element type of a channel type, which is referred to
+// through the channel.
+func chanElem() *Type {
+	indirect++
+	ty := parseType()
+	indirect--
+	return ty
 }
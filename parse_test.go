@@ -15,11 +15,12 @@ func TestParse(t *testing.T) {
 	}{
 		{
 			desc:  "Function",
-			input: "func add(a int,b int) { return a + b } func main() { return add(1,2) }",
+			input: "func add(a int,b int) int { return a + b } func main() { add(1,2) }",
 			expected: []*Node{
 				{
 					Kind:         ND_FUNC,
-					FunctionName: "add",
+					Type:         intType,
+					FunctionName: "main.add",
 					Args: []*Node{
 						{Kind: ND_VAR, Type: intType, Var: lvarInt("a")}, {Kind: ND_VAR, Type: intType, Var: lvarInt("b")},
 					},
@@ -45,18 +46,15 @@ func TestParse(t *testing.T) {
 				},
 				{
 					Kind:         ND_FUNC,
-					FunctionName: "main",
+					FunctionName: "main.main",
 					Args:         []*Node{},
 					Block: &Node{
 						Kind: ND_BLOCK, Body: []*Node{
 							{
-								Kind: ND_RETURN,
-								Lhs: &Node{
-									Kind: ND_FUNCALL, FunctionName: "add",
-									Type: intType,
-									Args: []*Node{
-										{Kind: ND_NUM, Type: intType, Val: 1}, {Kind: ND_NUM, Type: intType, Val: 2},
-									},
+								Kind: ND_FUNCALL, FunctionName: "main.add",
+								Type: intType,
+								Args: []*Node{
+									{Kind: ND_NUM, Type: intType, Val: 1}, {Kind: ND_NUM, Type: intType, Val: 2},
 								},
 							},
 						},
@@ -70,7 +68,7 @@ func TestParse(t *testing.T) {
 			expected: []*Node{
 				{
 					Kind:         ND_FUNC,
-					FunctionName: "main",
+					FunctionName: "main.main",
 					Args:         []*Node{},
 					Locals: &VarList{
 						Next: &VarList{Var: lvarInt("x")},
@@ -79,16 +77,17 @@ func TestParse(t *testing.T) {
 					Block: &Node{
 						Kind: ND_BLOCK, Body: []*Node{
 							{Kind: ND_VAR, Type: intType, Var: lvarInt("x")},
-							{Kind: ND_VAR, Type: &Type{TY_POINTER, intType, 0}, Var: lvarPointerInt("y")},
+							{Kind: ND_VAR, Type: pointerTo(intType), Var: lvarPointerInt("y")},
 							{Kind: ND_ASSIGN,
-								Lhs: &Node{Kind: ND_VAR, Type: &Type{TY_POINTER, intType, 0}, Var: lvarPointerInt("y")},
-								Rhs: &Node{Kind: ND_ADDR, Type: &Type{TY_POINTER, intType, 0}, Lhs: &Node{Kind: ND_VAR, Type: intType, Var: lvarInt("x")}},
+								Lhs: &Node{Kind: ND_VAR, Type: pointerTo(intType), Var: lvarPointerInt("y")},
+								Rhs: &Node{Kind: ND_ADDR, Type: pointerTo(intType), Lhs: &Node{Kind: ND_VAR, Type: intType, Var: lvarInt("x")}},
 							},
 							{Kind: ND_ASSIGN,
-								Lhs: &Node{Kind: ND_DEREF, Type: intType, Lhs: &Node{Kind: ND_VAR, Type: &Type{TY_POINTER, intType, 0}, Var: lvarPointerInt("y")}},
+								Lhs: &Node{Kind: ND_DEREF, Type: intType, Lhs: &Node{Kind: ND_VAR, Type: pointerTo(intType), Var: lvarPointerInt("y")}},
 								Rhs: &Node{Kind: ND_NUM, Type: intType, Val: 3},
 							},
 						},
+						Vars: []*Var{lvarInt("x"), lvarPointerInt("y")},
 					},
 				},
 			},
@@ -96,7 +95,17 @@ func TestParse(t *testing.T) {
 		{
 			desc:    "Global variable",
 			input:   "var i int",
-			globals: map[string]*Var{"i": {Name: "i", Type: intType}},
+			globals: map[string]*Var{"i": {Name: "main.i", Type: intType}},
+		},
+		{
+			desc:    "Named type",
+			input:   "var c Celsius; type Celsius int",
+			globals: map[string]*Var{"c": {Name: "main.c", Type: &Type{Kind: TY_INT, Name: "Celsius"}}},
+		},
+		{
+			desc:    "Type alias",
+			input:   "type (Celsius int; C = Celsius); var c C",
+			globals: map[string]*Var{"c": {Name: "main.c", Type: &Type{Kind: TY_INT, Name: "Celsius"}}},
 		},
 	}
 	for _, tC := range testCases {
@@ -207,10 +216,11 @@ func TestStmt(t *testing.T) {
 							Kind: ND_BLOCK,
 							Body: []*Node{{
 								Kind: ND_RETURN,
-								Lhs:  &Node{Kind: ND_SUB, Type: intType, Lhs: &Node{Kind: ND_NUM, Type: intType, Val: 0}, Rhs: &Node{Kind: ND_NUM, Type: intType, Val: 1}},
+								Lhs:  &Node{Kind: ND_NUM, Type: intType, Val: -1},
 							}},
 						},
 					},
+					Vars: []*Var{lvarInt("a")},
 				},
 
 				&Node{
@@ -233,6 +243,7 @@ func TestStmt(t *testing.T) {
 					},
 					Inc:  &Node{Kind: ND_INC, Lhs: &Node{Kind: ND_VAR, Type: intType, Var: lvarInt("i")}},
 					Then: &Node{Kind: ND_BLOCK, Body: []*Node{{Kind: ND_NUM, Type: intType, Val: 1}}},
+					Vars: []*Var{lvarInt("i")},
 				},
 			},
 		},
@@ -291,8 +302,9 @@ func TestStmt(t *testing.T) {
 
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			token, code, locals = nil, nil, nil
+			token, code, locals, scope = nil, nil, nil, nil
 			globals = make(map[string]*Var)
+			enterScope()
 			if err := tokenize(tC.input); err != nil {
 				t.Fatal(err)
 			}
@@ -315,7 +327,7 @@ func lvarInt(s string) *Var {
 }
 
 func lvarPointerInt(s string) *Var {
-	return &Var{Name: s, Type: &Type{TY_POINTER, intType, 0}, IsLocal: true}
+	return &Var{Name: s, Type: pointerTo(intType), IsLocal: true}
 }
 
 func lvarPointerPoinsterInt(s string) *Var {
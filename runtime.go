@@ -0,0 +1,2949 @@
+package main
+
+import "os"
+
+// runtimeText is the runtime support emitted along with every program.
+// Runtime routines follow the calling convention of the generated code.
+const runtimeText = `
+# main is the entry point called by the C runtime. It initializes the
+# package and runs main.main in the main goroutine, whose stack is the one
+# of the C runtime below its frame, and the program exits with status 0 when
+# main.main returns.
+.global main
+main:
+  push rbp
+  mov rbp, rsp
+  mov [rip+runtime.g0+24], rbp
+  call runtime.stackinit
+  call runtime.gcinit
+  call main.init
+  call main.main
+  mov rdi, 0
+  call exit
+
+# os.Exit(code) exits the program with the status code.
+os.Exit:
+  push rbp
+  mov rbp, rsp
+  call exit
+
+# The heap consists of the objects allocated by runtime.alloc, which are
+# listed in runtime.heap. An object is preceded by a header of its size, the
+# descriptor of its layout, and its mark. The object is an array of elements
+# laid out as the descriptor tells: the size of an element, the number of its
+# words which may hold pointers, and their offsets. An object without
+# pointers has no descriptor.
+#
+# The collector marks the objects reachable from the global variables in
+# runtime.roots, from the goroutines and their deferred calls, and from the
+# words on the stacks of the goroutines, which are scanned conservatively,
+# and frees the rest. The next collection is triggered when
+# the heap has grown by GOGC percent of the marked objects, or to 4 MB.
+
+# runtime.alloc(size, desc) returns a pointer to a new object of size bytes
+# of zeroed memory laid out as the descriptor desc tells. The garbage is
+# collected first if the heap has reached the goal.
+runtime.alloc:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  mov rbx, rdi
+  mov r12, rsi
+  cmp qword ptr [rip+runtime.gcpercent], 0
+  jl .L.alloc.table
+  mov rax, [rip+runtime.heapsize]
+  add rax, rbx
+  cmp rax, [rip+runtime.nextgc]
+  jbe .L.alloc.table
+  call runtime.GC
+.L.alloc.table:
+  mov rsi, [rip+runtime.heapcap]
+  cmp [rip+runtime.heaplen], rsi
+  jb .L.alloc.object
+  add rsi, rsi
+  cmp rsi, 1024
+  jae .L.alloc.grow
+  mov rsi, 1024
+.L.alloc.grow:
+  mov [rip+runtime.heapcap], rsi
+  shl rsi, 3
+  mov rdi, [rip+runtime.heap]
+  call realloc
+  cmp rax, 0
+  je runtime.outofmemory
+  mov [rip+runtime.heap], rax
+.L.alloc.object:
+  mov rdi, 1
+  lea rsi, [rbx+32]
+  call calloc
+  cmp rax, 0
+  je runtime.outofmemory
+  mov [rax], rbx
+  mov [rax+8], r12
+  add rax, 32
+  mov rcx, [rip+runtime.heap]
+  mov rdx, [rip+runtime.heaplen]
+  mov [rcx+rdx*8], rax
+  inc qword ptr [rip+runtime.heaplen]
+  add [rip+runtime.heapsize], rbx
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.outofmemory reports that memory is exhausted and exits.
+runtime.outofmemory:
+  and rsp, -16
+  mov rdi, offset .L.rt.outofmemory
+  call runtime.throw
+
+# runtime.gcinit sets the percentage of the growth of the heap which
+# triggers a collection from the environment variable GOGC. Collections
+# are triggered only by runtime.GC if it is "off".
+runtime.gcinit:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  mov rdi, offset .L.rt.gogc
+  call getenv
+  cmp rax, 0
+  je .L.gcinit.end
+  mov rbx, rax
+  mov rdi, rax
+  mov rsi, offset .L.rt.off
+  call strcmp
+  mov rcx, -1
+  cmp eax, 0
+  je .L.gcinit.set
+  mov rdi, rbx
+  call atoi
+  movsxd rcx, eax
+.L.gcinit.set:
+  mov [rip+runtime.gcpercent], rcx
+.L.gcinit.end:
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.GC collects the garbage. The heap is sorted by address so that
+# the objects referred to by the pointers are found by binary search. The
+# callee-saved registers are pushed to be scanned along with the stack.
+runtime.GC:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  sub rsp, 8
+  mov rdi, [rip+runtime.heap]
+  mov rsi, [rip+runtime.heaplen]
+  mov rdx, 8
+  mov rcx, offset runtime.cmpaddr
+  call qsort
+  mov rbx, offset runtime.roots
+.L.GC.root:
+  mov rdi, [rbx]
+  cmp rdi, 0
+  je .L.GC.stack
+  mov rdx, [rbx+8]
+  mov rsi, [rdx]
+  call runtime.scanblock
+  add rbx, 16
+  jmp .L.GC.root
+.L.GC.stack:
+  mov rdi, [rip+runtime.defers]
+  call runtime.mark
+  mov rdi, rsp
+  mov rax, [rip+runtime.g]
+  mov rsi, [rax+24]
+  call runtime.scanstack
+  mov rdi, [rip+runtime.g]
+  call runtime.scansegments
+  mov rbx, [rip+runtime.allgs]
+.L.GC.g:
+  cmp rbx, 0
+  je .L.GC.drain
+  cmp qword ptr [rbx+48], 2
+  je .L.GC.nextg
+  mov rdi, [rbx+56]
+  call runtime.mark
+  cmp rbx, [rip+runtime.g]
+  je .L.GC.nextg
+  mov rdi, [rbx+32]
+  call runtime.mark
+  mov rdi, [rbx+8]
+  mov rsi, [rbx+24]
+  call runtime.scanstack
+  mov rdi, rbx
+  call runtime.scansegments
+.L.GC.nextg:
+  mov rbx, [rbx+64]
+  jmp .L.GC.g
+.L.GC.drain:
+  mov rax, [rip+runtime.marklen]
+  cmp rax, 0
+  je .L.GC.sweep
+  dec rax
+  mov [rip+runtime.marklen], rax
+  mov rcx, [rip+runtime.markstack]
+  mov rdi, [rcx+rax*8]
+  mov rsi, [rdi-32]
+  mov rdx, [rdi-24]
+  call runtime.scanblock
+  jmp .L.GC.drain
+.L.GC.sweep:
+  mov r12, [rip+runtime.heap]
+  mov r13, 0
+  mov r14, 0
+  mov r15, 0
+.L.GC.object:
+  cmp r13, [rip+runtime.heaplen]
+  je .L.GC.goal
+  mov rdi, [r12+r13*8]
+  inc r13
+  cmp qword ptr [rdi-16], 0
+  je .L.GC.free
+  mov qword ptr [rdi-16], 0
+  add r15, [rdi-32]
+  mov [r12+r14*8], rdi
+  inc r14
+  jmp .L.GC.object
+.L.GC.free:
+  sub rdi, 32
+  call free
+  jmp .L.GC.object
+.L.GC.goal:
+  mov [rip+runtime.heaplen], r14
+  mov [rip+runtime.heapsize], r15
+  mov rax, r15
+  imul rax, [rip+runtime.gcpercent]
+  mov rcx, 100
+  cqo
+  idiv rcx
+  add rax, r15
+  cmp rax, 4194304
+  jge .L.GC.end
+  mov rax, 4194304
+.L.GC.end:
+  mov [rip+runtime.nextgc], rax
+  add rsp, 8
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.scansegments(g) scans the segments of the stack of the goroutine
+# g older than the one it runs on, each from the stack pointer it was left
+# with.
+runtime.scansegments:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  mov rbx, [rdi+80]
+.L.scansegments.next:
+  cmp rbx, 0
+  je .L.scansegments.end
+  mov rax, [rbx+16]
+  cmp rax, 0
+  je .L.scansegments.end
+  mov rdi, [rbx+32]
+  mov rsi, [rax+8]
+  call runtime.scanstack
+  mov rbx, [rbx+16]
+  jmp .L.scansegments.next
+.L.scansegments.end:
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.scanstack(lo, hi) marks the objects referred to by the words
+# between lo and hi on a stack.
+runtime.scanstack:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  mov rbx, rdi
+  mov r12, rsi
+.L.scanstack.word:
+  cmp rbx, r12
+  jae .L.scanstack.end
+  mov rdi, [rbx]
+  call runtime.mark
+  add rbx, 8
+  jmp .L.scanstack.word
+.L.scanstack.end:
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.cmpaddr(a, b) compares the addresses of objects at a and b for
+# qsort.
+runtime.cmpaddr:
+  mov rcx, [rdi]
+  mov rdx, [rsi]
+  mov eax, -1
+  cmp rcx, rdx
+  jb .L.cmpaddr.end
+  seta al
+  movzx eax, al
+.L.cmpaddr.end:
+  ret
+
+# runtime.scanblock(p, size, desc) marks the objects referred to by the
+# pointers in the size bytes at p, which are laid out as desc tells.
+runtime.scanblock:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  cmp rdx, 0
+  je .L.scanblock.end
+  mov rbx, rdi
+  lea r12, [rdi+rsi]
+  mov r13, rdx
+.L.scanblock.elem:
+  cmp rbx, r12
+  jae .L.scanblock.end
+  mov r14, 0
+.L.scanblock.word:
+  cmp r14, [r13+8]
+  je .L.scanblock.next
+  mov rax, [r13+r14*8+16]
+  mov rdi, [rbx+rax]
+  call runtime.mark
+  inc r14
+  jmp .L.scanblock.word
+.L.scanblock.next:
+  add rbx, [r13]
+  jmp .L.scanblock.elem
+.L.scanblock.end:
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.mark(p) marks the object which p points into, or just past, if it
+# is not marked yet, and pushes it to the mark stack to be scanned if it may
+# hold pointers. The heap must be sorted.
+runtime.mark:
+  mov r8, [rip+runtime.heap]
+  mov rcx, 0
+  mov rdx, [rip+runtime.heaplen]
+.L.mark.search:
+  cmp rcx, rdx
+  je .L.mark.found
+  lea rax, [rcx+rdx]
+  shr rax, 1
+  cmp [r8+rax*8], rdi
+  ja .L.mark.below
+  lea rcx, [rax+1]
+  jmp .L.mark.search
+.L.mark.below:
+  mov rdx, rax
+  jmp .L.mark.search
+.L.mark.found:
+  cmp rcx, 0
+  je .L.mark.end
+  mov rax, [r8+rcx*8-8]
+  mov rdx, [rax-32]
+  add rdx, rax
+  cmp rdi, rdx
+  ja .L.mark.end
+  cmp qword ptr [rax-16], 0
+  jne .L.mark.end
+  mov qword ptr [rax-16], 1
+  cmp qword ptr [rax-24], 0
+  je .L.mark.end
+  mov rcx, [rip+runtime.marklen]
+  cmp rcx, [rip+runtime.markcap]
+  jb .L.mark.push
+  push rbp
+  mov rbp, rsp
+  push rax
+  sub rsp, 8
+  mov rsi, rcx
+  add rsi, rsi
+  cmp rsi, 1024
+  jae .L.mark.grow
+  mov rsi, 1024
+.L.mark.grow:
+  mov [rip+runtime.markcap], rsi
+  shl rsi, 3
+  mov rdi, [rip+runtime.markstack]
+  call realloc
+  cmp rax, 0
+  je runtime.outofmemory
+  mov [rip+runtime.markstack], rax
+  add rsp, 8
+  pop rax
+  pop rbp
+  mov rcx, [rip+runtime.marklen]
+.L.mark.push:
+  mov rdx, [rip+runtime.markstack]
+  mov [rdx+rcx*8], rax
+  inc rcx
+  mov [rip+runtime.marklen], rcx
+.L.mark.end:
+  ret
+
+# runtime.growslice(s, n, size, desc) extends the length of the slice s of
+# elements of size bytes laid out as desc tells by n. If the capacity does
+# not suffice, the elements are moved to a new array of at least twice the
+# capacity.
+runtime.growslice:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  sub rsp, 8
+  mov rbx, rdi
+  mov r12, rdx
+  mov r15, rcx
+  mov r13, [rbx+8]
+  add r13, rsi
+  cmp r13, [rbx+16]
+  jle .L.growslice.done
+  mov r14, [rbx+16]
+  add r14, r14
+  cmp r14, r13
+  jge .L.growslice.alloc
+  mov r14, r13
+.L.growslice.alloc:
+  mov rdi, r14
+  imul rdi, r12
+  mov rsi, r15
+  call runtime.alloc
+  mov rdi, rax
+  mov rsi, [rbx]
+  mov rcx, [rbx+8]
+  imul rcx, r12
+  rep movsb
+  mov [rbx], rax
+  mov [rbx+16], r14
+.L.growslice.done:
+  mov [rbx+8], r13
+  add rsp, 8
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.makeslice(dst, len, cap, size, desc) stores to dst the header of a
+# new slice of len elements of size bytes laid out as desc tells with the
+# capacity cap.
+runtime.makeslice:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  mov rbx, rdi
+  mov r12, rsi
+  mov r13, rdx
+  mov rdi, offset .L.rt.makeslicelen
+  cmp r12, 0
+  jl .L.makeslice.panic
+  mov rdi, offset .L.rt.makeslicecap
+  cmp r13, r12
+  jl .L.makeslice.panic
+  mov rdi, r13
+  imul rdi, rcx
+  mov rsi, r8
+  call runtime.alloc
+  mov [rbx], rax
+  mov [rbx+8], r12
+  mov [rbx+16], r13
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+.L.makeslice.panic:
+  call runtime.panicf
+
+# runtime.copy(dst, src, size) copies the elements of size bytes from the
+# slice src to the slice dst, and returns the number of elements copied,
+# which is the minimum of their lengths.
+runtime.copy:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  mov rax, [rdi+8]
+  cmp rax, [rsi+8]
+  jle .L.copy.min
+  mov rax, [rsi+8]
+.L.copy.min:
+  mov rbx, rax
+  mov rdi, [rdi]
+  mov rsi, [rsi]
+  imul rdx, rax
+  call memmove
+  mov rax, rbx
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.cmpstring(a, b) compares the strings a and b lexically, and
+# returns -1, 0 or 1.
+runtime.cmpstring:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  mov rbx, [rdi+8]
+  mov r12, [rsi+8]
+  mov rdx, rbx
+  cmp rdx, r12
+  jle .L.cmpstring.min
+  mov rdx, r12
+.L.cmpstring.min:
+  mov rdi, [rdi]
+  mov rsi, [rsi]
+  call memcmp
+  cmp eax, 0
+  jl .L.cmpstring.less
+  jg .L.cmpstring.greater
+  cmp rbx, r12
+  jl .L.cmpstring.less
+  jg .L.cmpstring.greater
+  mov rax, 0
+  jmp .L.cmpstring.end
+.L.cmpstring.less:
+  mov rax, -1
+  jmp .L.cmpstring.end
+.L.cmpstring.greater:
+  mov rax, 1
+.L.cmpstring.end:
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.concatstring(dst, a, b) stores the concatenation of the strings a
+# and b to dst, allocating its bytes on the heap.
+runtime.concatstring:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  mov rbx, rdi
+  mov r12, rsi
+  mov r13, rdx
+  mov rdi, [r12+8]
+  add rdi, [r13+8]
+  mov rsi, 0
+  call runtime.alloc
+  mov r14, rax
+  mov rdi, rax
+  mov rsi, [r12]
+  mov rcx, [r12+8]
+  rep movsb
+  mov rsi, [r13]
+  mov rcx, [r13+8]
+  rep movsb
+  mov [rbx], r14
+  mov rax, [r12+8]
+  add rax, [r13+8]
+  mov [rbx+8], rax
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.dupbytes(dst, src) stores to dst the header of a copy on the heap
+# of the bytes of src, which is a string or a slice of bytes.
+runtime.dupbytes:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  mov rbx, rdi
+  mov r12, rsi
+  mov rdi, [r12+8]
+  mov rsi, 0
+  call runtime.alloc
+  mov [rbx], rax
+  mov rcx, [r12+8]
+  mov [rbx+8], rcx
+  mov rdi, rax
+  mov rsi, [r12]
+  rep movsb
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.encoderune(p, r) writes the UTF-8 encoding of the rune r to p, and
+# returns the number of bytes written. An invalid rune is encoded as U+FFFD.
+runtime.encoderune:
+  cmp rsi, 0
+  jl .L.encoderune.invalid
+  cmp rsi, 0x7f
+  jg .L.encoderune.2
+  mov [rdi], sil
+  mov rax, 1
+  ret
+.L.encoderune.2:
+  cmp rsi, 0x7ff
+  jg .L.encoderune.3
+  mov rax, rsi
+  shr rax, 6
+  or al, 0xc0
+  mov [rdi], al
+  mov rax, rsi
+  and al, 0x3f
+  or al, 0x80
+  mov [rdi+1], al
+  mov rax, 2
+  ret
+.L.encoderune.3:
+  cmp rsi, 0xffff
+  jg .L.encoderune.4
+  cmp rsi, 0xd800
+  jl .L.encoderune.3b
+  cmp rsi, 0xdfff
+  jle .L.encoderune.invalid
+.L.encoderune.3b:
+  mov rax, rsi
+  shr rax, 12
+  or al, 0xe0
+  mov [rdi], al
+  mov rax, rsi
+  shr rax, 6
+  and al, 0x3f
+  or al, 0x80
+  mov [rdi+1], al
+  mov rax, rsi
+  and al, 0x3f
+  or al, 0x80
+  mov [rdi+2], al
+  mov rax, 3
+  ret
+.L.encoderune.4:
+  cmp rsi, 0x10ffff
+  jg .L.encoderune.invalid
+  mov rax, rsi
+  shr rax, 18
+  or al, 0xf0
+  mov [rdi], al
+  mov rax, rsi
+  shr rax, 12
+  and al, 0x3f
+  or al, 0x80
+  mov [rdi+1], al
+  mov rax, rsi
+  shr rax, 6
+  and al, 0x3f
+  or al, 0x80
+  mov [rdi+2], al
+  mov rax, rsi
+  and al, 0x3f
+  or al, 0x80
+  mov [rdi+3], al
+  mov rax, 4
+  ret
+.L.encoderune.invalid:
+  mov rsi, 0xfffd
+  jmp .L.encoderune.3b
+
+# runtime.decoderune(p, n) decodes the UTF-8 encoded rune at the beginning
+# of the n > 0 bytes at p, and returns the rune in RAX and the number of
+# bytes it takes in RDX. An invalid encoding is decoded as U+FFFD of 1 byte.
+runtime.decoderune:
+  movzx eax, byte ptr [rdi]
+  mov rdx, 1
+  cmp rax, 0x80
+  jb .L.decoderune.end
+  cmp rax, 0xc2
+  jb .L.decoderune.invalid
+  cmp rax, 0xe0
+  jb .L.decoderune.2
+  cmp rax, 0xf0
+  jb .L.decoderune.3
+  cmp rax, 0xf5
+  jb .L.decoderune.4
+  jmp .L.decoderune.invalid
+.L.decoderune.2:
+  and rax, 0x1f
+  mov rcx, 2
+  jmp .L.decoderune.cont
+.L.decoderune.3:
+  and rax, 0x0f
+  mov rcx, 3
+  jmp .L.decoderune.cont
+.L.decoderune.4:
+  and rax, 0x07
+  mov rcx, 4
+.L.decoderune.cont:
+  # The continuation bytes each hold 6 bits of the rune.
+  cmp rsi, rcx
+  jb .L.decoderune.invalid
+.L.decoderune.next:
+  cmp rdx, rcx
+  je .L.decoderune.check
+  movzx r8d, byte ptr [rdi+rdx]
+  mov r9, r8
+  and r9, 0xc0
+  cmp r9, 0x80
+  jne .L.decoderune.invalid
+  shl rax, 6
+  and r8, 0x3f
+  or rax, r8
+  inc rdx
+  jmp .L.decoderune.next
+.L.decoderune.check:
+  # Overlong encodings, surrogates and runes out of range are invalid.
+  cmp rdx, 3
+  jb .L.decoderune.end
+  ja .L.decoderune.check4
+  cmp rax, 0x800
+  jb .L.decoderune.invalid
+  cmp rax, 0xd800
+  jb .L.decoderune.end
+  cmp rax, 0xdfff
+  jbe .L.decoderune.invalid
+  ret
+.L.decoderune.check4:
+  cmp rax, 0x10000
+  jb .L.decoderune.invalid
+  cmp rax, 0x10ffff
+  ja .L.decoderune.invalid
+.L.decoderune.end:
+  ret
+.L.decoderune.invalid:
+  mov rax, 0xfffd
+  mov rdx, 1
+  ret
+
+# runtime.intstring(dst, r) stores to dst the string of the UTF-8 encoding
+# of the rune r.
+runtime.intstring:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  mov rbx, rdi
+  mov r12, rsi
+  mov rdi, 4
+  mov rsi, 0
+  call runtime.alloc
+  mov [rbx], rax
+  mov rdi, rax
+  mov rsi, r12
+  call runtime.encoderune
+  mov [rbx+8], rax
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.stringtoslicerune(dst, s) stores to dst the header of a slice of
+# the runes decoded from the string s.
+runtime.stringtoslicerune:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  sub rsp, 8
+  mov rbx, rdi
+  mov r12, rsi
+  # Count the runes, and decode them to a new array.
+  mov r13, [r12]
+  mov r14, [r12+8]
+  mov r15, 0
+.L.stringtoslicerune.count:
+  cmp r14, 0
+  je .L.stringtoslicerune.alloc
+  mov rdi, r13
+  mov rsi, r14
+  call runtime.decoderune
+  add r13, rdx
+  sub r14, rdx
+  inc r15
+  jmp .L.stringtoslicerune.count
+.L.stringtoslicerune.alloc:
+  mov [rbx+8], r15
+  mov [rbx+16], r15
+  lea rdi, [r15*4]
+  mov rsi, 0
+  call runtime.alloc
+  mov [rbx], rax
+  mov r15, rax
+  mov r13, [r12]
+  mov r14, [r12+8]
+.L.stringtoslicerune.decode:
+  cmp r14, 0
+  je .L.stringtoslicerune.end
+  mov rdi, r13
+  mov rsi, r14
+  call runtime.decoderune
+  mov [r15], eax
+  add r15, 4
+  add r13, rdx
+  sub r14, rdx
+  jmp .L.stringtoslicerune.decode
+.L.stringtoslicerune.end:
+  add rsp, 8
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.slicerunetostring(dst, s) stores to dst the string of the UTF-8
+# encoding of the runes of the slice s.
+runtime.slicerunetostring:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  sub rsp, 8
+  mov rbx, rdi
+  mov r12, [rsi]
+  mov r13, [rsi+8]
+  lea rdi, [r13*4]
+  mov rsi, 0
+  call runtime.alloc
+  mov r14, rax
+  mov r15, rax
+.L.slicerunetostring.next:
+  cmp r13, 0
+  je .L.slicerunetostring.end
+  mov rdi, r15
+  movsxd rsi, dword ptr [r12]
+  call runtime.encoderune
+  add r15, rax
+  add r12, 4
+  dec r13
+  jmp .L.slicerunetostring.next
+.L.slicerunetostring.end:
+  mov [rbx], r14
+  sub r15, r14
+  mov [rbx+8], r15
+  add rsp, 8
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# A map is a pointer to a hash table of the count of its entries, the
+# number of slots, which is a power of two, the slots, the descriptor of the
+# map type, and the number of used slots, which are either full or deleted.
+# A slot is an entry of a word of its state, 0 for empty, 1 for full or 2
+# for deleted, followed by the key and the element. The descriptor holds the
+# size of a key, the offset of the element, the size of an entry, the
+# descriptor of the layout of an entry, and the number of spans of a key
+# followed by the spans. A span is its offset, its
+# size, and 1 if it is a string or 0 if it is compared by its bytes.
+
+# runtime.makemap(desc, hint) returns a new map of the type described by
+# desc, whose table is large enough for hint entries.
+runtime.makemap:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  sub rsp, 8
+  mov rbx, rdi
+  mov r13, rsi
+  mov r12, 8
+.L.makemap.size:
+  mov rax, r12
+  imul rax, 3
+  mov rcx, r13
+  shl rcx, 2
+  cmp rcx, rax
+  jle .L.makemap.alloc
+  shl r12, 1
+  jmp .L.makemap.size
+.L.makemap.alloc:
+  mov rdi, 40
+  mov rsi, offset .L.rt.hmap
+  call runtime.alloc
+  mov r13, rax
+  mov [r13+8], r12
+  mov [r13+24], rbx
+  mov rdi, r12
+  imul rdi, [rbx+16]
+  mov rsi, [rbx+24]
+  call runtime.alloc
+  mov [r13+16], rax
+  mov rax, r13
+  add rsp, 8
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.hashkey(desc, key) returns the FNV-1a hash of the key.
+runtime.hashkey:
+  mov rax, 0xcbf29ce484222325
+  mov r11, 0x100000001b3
+  mov rcx, [rdi+32]
+  lea r8, [rdi+40]
+.L.hashkey.span:
+  cmp rcx, 0
+  je .L.hashkey.end
+  mov r9, [r8]
+  add r9, rsi
+  mov r10, [r8+8]
+  cmp qword ptr [r8+16], 0
+  je .L.hashkey.byte
+  mov r10, [r9+8]
+  mov r9, [r9]
+.L.hashkey.byte:
+  cmp r10, 0
+  je .L.hashkey.next
+  movzx edx, byte ptr [r9]
+  xor rax, rdx
+  imul rax, r11
+  inc r9
+  dec r10
+  jmp .L.hashkey.byte
+.L.hashkey.next:
+  add r8, 24
+  dec rcx
+  jmp .L.hashkey.span
+.L.hashkey.end:
+  ret
+
+# runtime.equalkey(desc, a, b) returns 1 if the keys a and b are equal, and
+# 0 otherwise.
+runtime.equalkey:
+  mov rcx, [rdi+32]
+  lea r8, [rdi+40]
+.L.equalkey.span:
+  cmp rcx, 0
+  je .L.equalkey.equal
+  mov r9, [r8]
+  lea r10, [rsi+r9]
+  add r9, rdx
+  mov r11, [r8+8]
+  cmp qword ptr [r8+16], 0
+  je .L.equalkey.byte
+  mov r11, [r10+8]
+  cmp r11, [r9+8]
+  jne .L.equalkey.differ
+  mov r10, [r10]
+  mov r9, [r9]
+.L.equalkey.byte:
+  cmp r11, 0
+  je .L.equalkey.next
+  mov al, [r10]
+  cmp al, [r9]
+  jne .L.equalkey.differ
+  inc r10
+  inc r9
+  dec r11
+  jmp .L.equalkey.byte
+.L.equalkey.next:
+  add r8, 24
+  dec rcx
+  jmp .L.equalkey.span
+.L.equalkey.equal:
+  mov rax, 1
+  ret
+.L.equalkey.differ:
+  mov rax, 0
+  ret
+
+# runtime.ifaceeq(a, b) returns 1 if the interface values a and b are equal,
+# and 0 otherwise. They are equal if both are nil, or if their dynamic types
+# are identical and their dynamic values are equal. A type descriptor holds
+# how its values are compared, followed by the spans of a boxed value as in a
+# map descriptor, so that runtime.equalkey compares the values the data words
+# point to. Comparing values of an uncomparable type panics.
+runtime.ifaceeq:
+  mov rax, [rdi]
+  mov rcx, [rsi]
+  cmp rax, 0
+  je .L.ifaceeq.nil
+  cmp rcx, 0
+  je .L.ifaceeq.differ
+  mov rax, [rax]
+  cmp rax, [rcx]
+  jne .L.ifaceeq.differ
+  mov rdx, [rsi+8]
+  mov rsi, [rdi+8]
+  cmp qword ptr [rax+24], 1
+  jb .L.ifaceeq.uncomparable
+  ja .L.ifaceeq.boxed
+  cmp rsi, rdx
+  sete al
+  movzx rax, al
+  ret
+.L.ifaceeq.boxed:
+  mov rdi, rax
+  jmp runtime.equalkey
+.L.ifaceeq.nil:
+  cmp rcx, 0
+  sete al
+  movzx rax, al
+  ret
+.L.ifaceeq.differ:
+  mov rax, 0
+  ret
+.L.ifaceeq.uncomparable:
+  and rsp, -16
+  mov rsi, [rax]
+  mov rdi, offset .L.rt.uncomparable
+  call runtime.panicf
+
+# runtime.mapfind(m, key) returns the entry of the key in the map m, or 0 if
+# it is not in the map, in which case RDX is set to the free entry where it
+# is to be inserted. The slots are probed linearly from the hash of the key.
+runtime.mapfind:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  sub rsp, 8
+  mov rbx, rdi
+  mov r12, rsi
+  mov rdi, [rbx+24]
+  call runtime.hashkey
+  mov r13, [rbx+8]
+  dec r13
+  mov r14, rax
+  and r14, r13
+  mov r15, 0
+.L.mapfind.probe:
+  mov rax, [rbx+24]
+  mov rax, [rax+16]
+  imul rax, r14
+  add rax, [rbx+16]
+  cmp qword ptr [rax], 0
+  je .L.mapfind.empty
+  cmp qword ptr [rax], 2
+  je .L.mapfind.deleted
+  mov [rsp], rax
+  mov rdi, [rbx+24]
+  mov rsi, r12
+  lea rdx, [rax+8]
+  call runtime.equalkey
+  cmp rax, 0
+  jne .L.mapfind.found
+  jmp .L.mapfind.next
+.L.mapfind.deleted:
+  cmp r15, 0
+  jne .L.mapfind.next
+  mov r15, rax
+.L.mapfind.next:
+  inc r14
+  and r14, r13
+  jmp .L.mapfind.probe
+.L.mapfind.empty:
+  cmp r15, 0
+  jne .L.mapfind.missing
+  mov r15, rax
+.L.mapfind.missing:
+  mov rax, 0
+  mov rdx, r15
+  jmp .L.mapfind.end
+.L.mapfind.found:
+  mov rax, [rsp]
+.L.mapfind.end:
+  add rsp, 8
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.growmap(m) moves the entries of the map m to a new table, which is
+# twice as large unless more than half of the slots are free.
+runtime.growmap:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  sub rsp, 8
+  mov rbx, rdi
+  mov r12, [rbx+16]
+  mov r13, [rbx+8]
+  mov rax, r13
+  mov rcx, [rbx]
+  shl rcx, 1
+  cmp rcx, rax
+  jl .L.growmap.alloc
+  shl rax, 1
+.L.growmap.alloc:
+  mov [rbx+8], rax
+  mov rcx, [rbx+24]
+  imul rax, [rcx+16]
+  mov rdi, rax
+  mov rsi, [rcx+24]
+  call runtime.alloc
+  mov [rbx+16], rax
+  mov qword ptr [rbx], 0
+  mov qword ptr [rbx+32], 0
+  mov r14, 0
+.L.growmap.next:
+  cmp r14, r13
+  je .L.growmap.end
+  mov r15, [rbx+24]
+  mov r15, [r15+16]
+  imul r15, r14
+  add r15, r12
+  inc r14
+  cmp qword ptr [r15], 1
+  jne .L.growmap.next
+  mov rdi, rbx
+  lea rsi, [r15+8]
+  call runtime.mapfind
+  mov rdi, rdx
+  mov rsi, r15
+  mov rcx, [rbx+24]
+  mov rcx, [rcx+16]
+  rep movsb
+  inc qword ptr [rbx]
+  inc qword ptr [rbx+32]
+  jmp .L.growmap.next
+.L.growmap.end:
+  add rsp, 8
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.mapaccess(m, key) returns the pointer to the element of the key in
+# the map m, or 0 if it is not in the map.
+runtime.mapaccess:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  mov rbx, rdi
+  mov rax, 0
+  cmp rbx, 0
+  je .L.mapaccess.end
+  call runtime.mapfind
+  cmp rax, 0
+  je .L.mapaccess.end
+  mov rdx, [rbx+24]
+  add rax, [rdx+8]
+.L.mapaccess.end:
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.mapassign(m, key) returns the pointer to the element of the key in
+# the map m, inserting the key with the zero value if it is not in the map.
+# The table grows before more than 3/4 of its slots are used.
+runtime.mapassign:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  sub rsp, 8
+  mov rbx, rdi
+  mov r12, rsi
+  cmp rbx, 0
+  jne .L.mapassign.grow
+  mov rdi, offset .L.rt.nilmap
+  call runtime.panicf
+.L.mapassign.grow:
+  mov rax, [rbx+32]
+  inc rax
+  shl rax, 2
+  mov rcx, [rbx+8]
+  imul rcx, 3
+  cmp rax, rcx
+  jle .L.mapassign.find
+  mov rdi, rbx
+  call runtime.growmap
+.L.mapassign.find:
+  mov rdi, rbx
+  mov rsi, r12
+  call runtime.mapfind
+  cmp rax, 0
+  jne .L.mapassign.found
+  mov r13, rdx
+  cmp qword ptr [r13], 0
+  jne .L.mapassign.insert
+  inc qword ptr [rbx+32]
+.L.mapassign.insert:
+  # The element of a deleted entry is cleared.
+  mov qword ptr [r13], 1
+  inc qword ptr [rbx]
+  mov rax, [rbx+24]
+  lea rdi, [r13+8]
+  mov rsi, r12
+  mov rcx, [rax]
+  rep movsb
+  mov rdx, [rbx+24]
+  mov rdi, r13
+  add rdi, [rdx+8]
+  mov rcx, [rdx+16]
+  sub rcx, [rdx+8]
+  mov al, 0
+  rep stosb
+  mov rax, r13
+.L.mapassign.found:
+  mov rdx, [rbx+24]
+  add rax, [rdx+8]
+  add rsp, 8
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.mapdelete(m, key) deletes the key from the map m.
+runtime.mapdelete:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  mov rbx, rdi
+  cmp rbx, 0
+  je .L.mapdelete.end
+  call runtime.mapfind
+  cmp rax, 0
+  je .L.mapdelete.end
+  mov qword ptr [rax], 2
+  dec qword ptr [rbx]
+.L.mapdelete.end:
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.mapiter(m, i) returns the first full entry of the map m from the
+# i-th slot, or 0 if there is none, and sets RDX to the slot following it.
+runtime.mapiter:
+  mov rax, 0
+  cmp rdi, 0
+  je .L.mapiter.end
+.L.mapiter.next:
+  cmp rsi, [rdi+8]
+  jae .L.mapiter.none
+  mov rax, [rdi+24]
+  mov rax, [rax+16]
+  imul rax, rsi
+  add rax, [rdi+16]
+  inc rsi
+  cmp qword ptr [rax], 1
+  je .L.mapiter.end
+  jmp .L.mapiter.next
+.L.mapiter.none:
+  mov rax, 0
+.L.mapiter.end:
+  mov rdx, rsi
+  ret
+
+# A deferred call is recorded in a record of the next record, the closure
+# to call, the frame of the function deferring it, and the recovery point of
+# the function, from which it returns if the call recovers from a panic. The
+# records are listed in runtime.defers, the most recent first.
+
+# runtime.deferproc(fn, frame, recovery) records a call of the closure fn
+# deferred by the function of the frame.
+runtime.deferproc:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push rdi
+  push rsi
+  push rdx
+  mov rdi, 32
+  mov rsi, offset .L.rt.defer
+  call runtime.alloc
+  pop rdx
+  pop rsi
+  pop rdi
+  mov rcx, [rip+runtime.defers]
+  mov [rax], rcx
+  mov [rax+8], rdi
+  mov [rax+16], rsi
+  mov [rax+24], rdx
+  mov [rip+runtime.defers], rax
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.deferreturn(frame) makes the calls deferred by the function of
+# the frame, the most recent first.
+runtime.deferreturn:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  mov rbx, rdi
+.L.deferreturn.next:
+  mov rax, [rip+runtime.defers]
+  cmp rax, 0
+  je .L.deferreturn.end
+  cmp [rax+16], rbx
+  jne .L.deferreturn.end
+  mov rcx, [rax]
+  mov [rip+runtime.defers], rcx
+  mov r10, [rax+8]
+  mov r11, [r10]
+  call r11
+  jmp .L.deferreturn.next
+.L.deferreturn.end:
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# A panic is recorded in a record on the stack of runtime.gopanic, of the
+# previous panic, the interface value it was called with, whether it has been
+# recovered, and the frame of the deferred call being made. The records are
+# listed in runtime.panics, the most recent first.
+
+# runtime.gopanic(itab, data) panics with the interface value. The deferred
+# calls are made, the most recent first, until one of them recovers, and
+# then the function which deferred it returns normally from its recovery
+# point. The panics made in the frames left are abandoned. If no deferred
+# call recovers, the panics are printed and the program exits with status 2.
+runtime.gopanic:
+  push rbp
+  mov rbp, rsp
+  sub rsp, 48
+  cmp rdi, 0
+  jne .L.gopanic.record
+  mov rdi, offset .L.rt.panicnil
+  call runtime.panicf
+.L.gopanic.record:
+  mov rax, [rip+runtime.panics]
+  mov [rbp-40], rax
+  mov [rbp-32], rdi
+  mov [rbp-24], rsi
+  mov qword ptr [rbp-16], 0
+  mov qword ptr [rbp-8], 0
+  lea rax, [rbp-40]
+  mov [rip+runtime.panics], rax
+.L.gopanic.next:
+  mov rax, [rip+runtime.defers]
+  cmp rax, 0
+  je .L.gopanic.fatal
+  mov rcx, [rax]
+  mov [rip+runtime.defers], rcx
+  mov [rbp-48], rax
+  # The frame of the deferred call is below its return address and the
+  # saved frame pointer.
+  lea rcx, [rsp-16]
+  mov [rbp-8], rcx
+  mov r10, [rax+8]
+  mov r11, [r10]
+  call r11
+  cmp qword ptr [rbp-16], 0
+  je .L.gopanic.next
+  mov rax, [rbp-48]
+  mov rdi, [rax+16]
+  mov rsi, [rax+24]
+  mov rcx, [rip+runtime.panics]
+  # The segments of the stack newer than the one of the frame are left,
+  # along with the panics made on them.
+.L.gopanic.segment:
+  mov r11, [rip+runtime.g]
+  cmp rdi, [r11+16]
+  jb .L.gopanic.leave
+  cmp rdi, [r11+24]
+  jb .L.gopanic.abandon
+.L.gopanic.leave:
+  cmp rcx, 0
+  je .L.gopanic.pop
+  cmp rcx, [r11+16]
+  jb .L.gopanic.pop
+  cmp rcx, [r11+24]
+  jae .L.gopanic.pop
+  mov rcx, [rcx]
+  jmp .L.gopanic.leave
+.L.gopanic.pop:
+  push rcx
+  push rdi
+  push rsi
+  call runtime.stackpop
+  pop rsi
+  pop rdi
+  pop rcx
+  jmp .L.gopanic.segment
+.L.gopanic.abandon:
+  cmp rcx, 0
+  je .L.gopanic.resume
+  cmp rcx, rdi
+  jae .L.gopanic.resume
+  mov rcx, [rcx]
+  jmp .L.gopanic.abandon
+.L.gopanic.resume:
+  mov [rip+runtime.panics], rcx
+  mov rbp, rdi
+  jmp rsi
+.L.gopanic.fatal:
+  lea rdi, [rbp-40]
+  call runtime.printpanics
+  mov rdi, 2
+  call exit
+
+# runtime.gorecover(frame, v) stores the value of the current panic to the
+# interface value at v, and stops the panic, if it is called by the deferred
+# call of the frame. Otherwise it stores nil. A frame on the top of a segment
+# of the stack is taken to be where it would be without the segment.
+runtime.gorecover:
+  mov rcx, offset runtime.lessstack
+  cmp [rdi+8], rcx
+  jne .L.gorecover.frame
+  mov rcx, [rip+runtime.g]
+  mov rcx, [rcx+80]
+  lea rdx, [rdi+16]
+.L.gorecover.segment:
+  cmp [rcx+8], rdx
+  je .L.gorecover.top
+  mov rcx, [rcx+16]
+  jmp .L.gorecover.segment
+.L.gorecover.top:
+  mov rdi, [rcx+32]
+  sub rdi, 8
+.L.gorecover.frame:
+  mov rax, [rip+runtime.panics]
+  cmp rax, 0
+  je .L.gorecover.nil
+  cmp qword ptr [rax+24], 0
+  jne .L.gorecover.nil
+  cmp [rax+32], rdi
+  jne .L.gorecover.nil
+  mov qword ptr [rax+24], 1
+  mov rcx, [rax+8]
+  mov [rsi], rcx
+  mov rcx, [rax+16]
+  mov [rsi+8], rcx
+  ret
+.L.gorecover.nil:
+  mov qword ptr [rsi], 0
+  mov qword ptr [rsi+8], 0
+  ret
+
+# runtime.printpanics(p) prints the panic p after the ones it was made
+# during.
+runtime.printpanics:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  mov rbx, rdi
+  mov rdi, [rbx]
+  cmp rdi, 0
+  je .L.printpanics.print
+  call runtime.printpanics
+  mov rdi, 2
+  mov rsi, offset .L.rt.tab
+  mov rax, 0
+  call dprintf
+.L.printpanics.print:
+  mov rdi, 2
+  mov rsi, offset .L.rt.panic
+  mov rax, 0
+  call dprintf
+  mov rdi, [rbx+8]
+  mov rsi, [rbx+16]
+  call runtime.printpanicval
+  cmp qword ptr [rbx+24], 0
+  je .L.printpanics.end
+  mov rdi, 2
+  mov rsi, offset .L.rt.recovered
+  mov rax, 0
+  call dprintf
+.L.printpanics.end:
+  mov rdi, 2
+  mov rsi, offset .L.rt.newline
+  mov rax, 0
+  call dprintf
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.printpanicval(itab, data) prints the interface value a panic was
+# called with, as the descriptor of its type tells: an integer or a string as
+# it is, qualified by the name of the type if it is named, and any other
+# value by the name of its type and its address.
+runtime.printpanicval:
+  push rbp
+  mov rbp, rsp
+  mov rax, [rdi]
+  mov rdx, [rax]
+  mov rax, [rax+16]
+  mov rdi, 2
+  cmp rax, 1
+  je .L.printpanicval.int
+  cmp rax, 2
+  je .L.printpanicval.namedint
+  cmp rax, 3
+  je .L.printpanicval.string
+  cmp rax, 4
+  je .L.printpanicval.namedstring
+  mov rcx, rsi
+  mov rsi, offset .L.rt.printother
+  jmp .L.printpanicval.print
+.L.printpanicval.int:
+  mov rdx, rsi
+  mov rsi, offset .L.rt.printint
+  jmp .L.printpanicval.print
+.L.printpanicval.namedint:
+  mov rcx, rsi
+  mov rsi, offset .L.rt.printnamedint
+  jmp .L.printpanicval.print
+.L.printpanicval.string:
+  mov rcx, [rsi]
+  mov rdx, [rsi+8]
+  mov rsi, offset .L.rt.printstring
+  jmp .L.printpanicval.print
+.L.printpanicval.namedstring:
+  mov r8, [rsi]
+  mov rcx, [rsi+8]
+  mov rsi, offset .L.rt.printnamedstring
+.L.printpanicval.print:
+  mov rax, 0
+  call dprintf
+  pop rbp
+  ret
+
+# runtime.panicf(format, args...) panics with a runtime error, whose message
+# is formatted by snprintf. A runtime error is a string of the message with
+# a type of its own.
+runtime.panicf:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push rdi
+  push rsi
+  push rdx
+  push rcx
+  push r8
+  push r8
+  mov r9, rcx
+  mov r8, rdx
+  mov rcx, rsi
+  mov rdx, rdi
+  mov rdi, 0
+  mov rsi, 0
+  mov rax, 0
+  call snprintf
+  movsxd rbx, eax
+  lea rdi, [rbx+1]
+  mov rsi, 0
+  call runtime.alloc
+  mov r12, rax
+  mov rdi, r12
+  lea rsi, [rbx+1]
+  mov rdx, [rbp-24]
+  mov rcx, [rbp-32]
+  mov r8, [rbp-40]
+  mov r9, [rbp-48]
+  mov rax, [rbp-56]
+  mov [rsp], rax
+  mov rax, 0
+  call snprintf
+  mov rdi, 16
+  mov rsi, offset .L.rt.string
+  call runtime.alloc
+  mov [rax], r12
+  mov [rax+8], rbx
+  mov rdi, offset .L.rt.erroritab
+  mov rsi, rax
+  call runtime.gopanic
+
+# runtime.panicmem panics with the runtime error of a nil pointer
+# dereference.
+runtime.panicmem:
+  and rsp, -16
+  mov rdi, offset .L.rt.nilderef
+  call runtime.panicf
+
+# runtime.panicdivide panics with the runtime error of an integer division by
+# zero.
+runtime.panicdivide:
+  and rsp, -16
+  mov rdi, offset .L.rt.divide
+  call runtime.panicf
+
+# A goroutine is described by a G of the next goroutine in the run queue or
+# in the free list, the saved stack pointer, the bounds of the segment of its
+# stack it runs on, its deferred calls and panics while it is not running,
+# its status, the closure it runs, the next G of all, the sudog it was last
+# woken with, the segment of its stack it runs on, and the size of the
+# segments in use. The status is 0 if the goroutine is
+# runnable, 1 if it waits, and 2 if it has exited. The running goroutine is
+# runtime.g, and the runnable ones wait in runtime.runq in turn.
+#
+# The goroutines are scheduled cooperatively on a single thread. Once the
+# first goroutine is created, a timer of the CPU time sets runtime.preempt
+# every time slice, and the running goroutine yields when it next enters a
+# function.
+#
+# The stack of a goroutine grows by segments. A segment is described by a
+# record of the bounds of its memory, the previous and the next segments, and
+# the stack pointer and the address a function is resumed from when it
+# starts the segment. A function whose frame does not fit above the stack
+# guard of the running segment at its entry continues on the next segment
+# by runtime.morestack, and returns to the previous one by
+# runtime.lessstack. The segments left are kept for reuse. The memory of a
+# segment has a guard page below, and the stack guard is further above it by
+# the space for the calls to the runtime and the C library. The stack of the
+# main goroutine is the one of the C runtime as far as its resource limit
+# allows, if it has one, from which it grows by segments as well.
+
+# runtime.newproc(fn) creates a goroutine calling the closure fn, which
+# starts from runtime.goentry. The G and the stack of an exited goroutine
+# are reused.
+runtime.newproc:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  mov r12, rdi
+  mov rbx, [rip+runtime.gfree]
+  cmp rbx, 0
+  je .L.newproc.alloc
+  mov rax, [rbx]
+  mov [rip+runtime.gfree], rax
+  jmp .L.newproc.init
+.L.newproc.alloc:
+  cmp qword ptr [rip+runtime.allgs+8], 0
+  jne .L.newproc.g
+  call runtime.starttimer
+.L.newproc.g:
+  mov rdi, 1
+  mov rsi, 128
+  call calloc
+  cmp rax, 0
+  je runtime.outofmemory
+  mov rbx, rax
+  mov rdi, 1
+  mov rsi, 48
+  call calloc
+  cmp rax, 0
+  je runtime.outofmemory
+  mov [rbx+80], rax
+  mov rdi, rax
+  mov rsi, 266240
+  call runtime.stackalloc
+  mov rax, [rbx+80]
+  mov rcx, [rax]
+  mov [rbx+16], rcx
+  mov rcx, [rax+8]
+  mov [rbx+24], rcx
+  mov qword ptr [rbx+88], 266240
+  mov rax, [rip+runtime.allgs]
+  mov [rbx+64], rax
+  mov [rip+runtime.allgs], rbx
+  mov qword ptr [rip+runtime.allgs+8], 1
+.L.newproc.init:
+  mov [rbx+56], r12
+  mov qword ptr [rbx+32], 0
+  mov qword ptr [rbx+40], 0
+  mov qword ptr [rbx+48], 0
+  # The goroutine is switched to as if it had saved the callee-saved
+  # registers below the address of runtime.goentry.
+  mov rax, [rbx+24]
+  mov rcx, offset runtime.goentry
+  mov [rax-8], rcx
+  lea rdi, [rax-56]
+  mov [rbx+8], rdi
+  mov rcx, 6
+  mov rax, 0
+  rep stosq
+  mov rdi, rbx
+  call runtime.runqput
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.goentry calls the closure of the goroutine, and exits the
+# goroutine when it returns.
+runtime.goentry:
+  mov rax, [rip+runtime.g]
+  mov r10, [rax+56]
+  mov r11, [r10]
+  call r11
+
+# runtime.goexit exits the running goroutine, and switches to the next one.
+runtime.goexit:
+  mov rax, [rip+runtime.g]
+  mov qword ptr [rax+48], 2
+  mov qword ptr [rax+56], 0
+  mov rcx, [rip+runtime.gfree]
+  mov [rax], rcx
+  mov [rip+runtime.gfree], rax
+  call runtime.schedule
+
+# runtime.runqput(g) puts the goroutine g at the tail of the run queue.
+runtime.runqput:
+  mov qword ptr [rdi], 0
+  mov rax, [rip+runtime.runq+8]
+  cmp rax, 0
+  je .L.runqput.empty
+  mov [rax], rdi
+  jmp .L.runqput.end
+.L.runqput.empty:
+  mov [rip+runtime.runq], rdi
+.L.runqput.end:
+  mov [rip+runtime.runq+8], rdi
+  ret
+
+# runtime.runqget() takes the goroutine at the head of the run queue, or
+# returns 0 if it is empty.
+runtime.runqget:
+  mov rax, [rip+runtime.runq]
+  cmp rax, 0
+  je .L.runqget.end
+  mov rcx, [rax]
+  mov [rip+runtime.runq], rcx
+  cmp rcx, 0
+  jne .L.runqget.end
+  mov [rip+runtime.runq+8], rcx
+.L.runqget.end:
+  ret
+
+# runtime.schedule switches to the next runnable goroutine, from a
+# goroutine which is not runnable. The goroutines are deadlocked if there
+# is none.
+runtime.schedule:
+  and rsp, -16
+  call runtime.runqget
+  cmp rax, 0
+  je .L.schedule.deadlock
+  mov rdi, rax
+  call runtime.gogo
+  ret
+.L.schedule.deadlock:
+  mov rdi, offset .L.rt.deadlock
+  call runtime.throw
+
+# runtime.Gosched yields to the next runnable goroutine, if any, and the
+# running one is put at the tail of the run queue.
+runtime.Gosched:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  call runtime.runqget
+  cmp rax, 0
+  je .L.Gosched.end
+  mov rbx, rax
+  mov rdi, [rip+runtime.g]
+  call runtime.runqput
+  mov rdi, rbx
+  call runtime.gogo
+.L.Gosched.end:
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.gopreempt yields at the entry of a function, preserving the
+# registers of the arguments.
+runtime.gopreempt:
+  push rdi
+  push rsi
+  push rdx
+  push rcx
+  push r8
+  push r9
+  push r10
+  push rax
+  mov qword ptr [rip+runtime.preempt], 0
+  call runtime.Gosched
+  pop rax
+  pop r10
+  pop r9
+  pop r8
+  pop rcx
+  pop rdx
+  pop rsi
+  pop rdi
+  ret
+
+# runtime.gogo(g) switches from the running goroutine to the goroutine g.
+# The callee-saved registers are saved on the stack of the running one,
+# which resumes returning from here when it is switched back to.
+runtime.gogo:
+  push rbp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  mov rax, [rip+runtime.g]
+  mov [rax+8], rsp
+  mov rcx, [rip+runtime.defers]
+  mov [rax+32], rcx
+  mov rcx, [rip+runtime.panics]
+  mov [rax+40], rcx
+  mov [rip+runtime.g], rdi
+  mov rcx, [rdi+16]
+  add rcx, 36864
+  mov [rip+runtime.stackguard], rcx
+  mov rsp, [rdi+8]
+  mov rcx, [rdi+32]
+  mov [rip+runtime.defers], rcx
+  mov rcx, [rdi+40]
+  mov [rip+runtime.panics], rcx
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.stackinit sets the stack guard of the main goroutine by the limit
+# of the size of the stack. A quarter of the limit is left to the arguments
+# and the environment above the stack.
+runtime.stackinit:
+  push rbp
+  mov rbp, rsp
+  sub rsp, 16
+  mov rdi, 3
+  mov rsi, rsp
+  call getrlimit
+  cmp eax, 0
+  jne .L.stackinit.end
+  mov rax, [rsp]
+  cmp rax, -1
+  je .L.stackinit.end
+  shr rax, 2
+  imul rax, rax, 3
+  mov rcx, [rip+runtime.g0+24]
+  sub rcx, rax
+  mov [rip+runtime.g0+16], rcx
+  add rcx, 36864
+  mov [rip+runtime.stackguard], rcx
+  mov [rip+runtime.g0+88], rax
+  mov rdi, 1
+  mov rsi, 48
+  call calloc
+  cmp rax, 0
+  je runtime.outofmemory
+  mov rcx, [rip+runtime.g0+16]
+  mov [rax], rcx
+  mov rcx, [rip+runtime.g0+24]
+  mov [rax+8], rcx
+  mov [rip+runtime.g0+80], rax
+.L.stackinit.end:
+  leave
+  ret
+
+# runtime.stackalloc(s, size) maps the memory of size bytes for the segment
+# s, with a guard page below.
+runtime.stackalloc:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  mov rbx, rdi
+  mov r12, rsi
+  mov rdi, 0
+  mov rdx, 3
+  mov rcx, 34
+  mov r8, -1
+  mov r9, 0
+  call mmap
+  cmp rax, -1
+  je runtime.outofmemory
+  mov [rbx], rax
+  add rax, r12
+  mov [rbx+8], rax
+  mov rdi, [rbx]
+  mov rsi, 4096
+  mov rdx, 0
+  call mprotect
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.morestack is called at the entry of a function, whose frame of
+# R11 bytes does not fit above the stack guard, and continues the function
+# on the next segment of the stack, which is made large enough for the
+# frame. The registers of the arguments are preserved. The stack overflows
+# if the segments in use would exceed runtime.maxstack bytes.
+runtime.morestack:
+  push rbp
+  mov rbp, rsp
+  push rdi
+  push rsi
+  push rdx
+  push rcx
+  push r8
+  push r9
+  push r10
+  push rax
+  push rbx
+  push r12
+  push r13
+  mov rbx, [rip+runtime.g]
+  lea r13, [r11+36864+8191]
+  and r13, -4096
+  cmp r13, 266240
+  jae .L.morestack.size
+  mov r13, 266240
+.L.morestack.size:
+  mov rax, [rbx+88]
+  add rax, r13
+  cmp rax, [rip+runtime.maxstack]
+  ja .L.morestack.overflow
+  mov rax, [rbx+80]
+  mov r12, [rax+24]
+  cmp r12, 0
+  jne .L.morestack.reuse
+  mov rdi, 1
+  mov rsi, 48
+  call calloc
+  cmp rax, 0
+  je runtime.outofmemory
+  mov r12, rax
+  mov rax, [rbx+80]
+  mov [rax+24], r12
+  mov [r12+16], rax
+  jmp .L.morestack.alloc
+.L.morestack.reuse:
+  mov rax, [r12+8]
+  sub rax, [r12]
+  cmp rax, r13
+  jae .L.morestack.switch
+  mov rdi, [r12]
+  mov rsi, rax
+  call munmap
+.L.morestack.alloc:
+  mov rdi, r12
+  mov rsi, r13
+  call runtime.stackalloc
+.L.morestack.switch:
+  lea rax, [rbp+16]
+  mov [r12+32], rax
+  mov rax, [rbp+8]
+  mov [r12+40], rax
+  mov rax, [r12+8]
+  mov rcx, offset runtime.lessstack
+  mov [rax-8], rcx
+  sub rax, [r12]
+  add [rbx+88], rax
+  mov [rbx+80], r12
+  mov rax, [r12]
+  mov [rbx+16], rax
+  add rax, 36864
+  mov [rip+runtime.stackguard], rax
+  mov rax, [r12+8]
+  mov [rbx+24], rax
+  pop r13
+  pop r12
+  pop rbx
+  pop rax
+  pop r10
+  pop r9
+  pop r8
+  pop rcx
+  pop rdx
+  pop rsi
+  pop rdi
+  pop rbp
+  mov r11, [rip+runtime.g]
+  mov r11, [r11+80]
+  mov rsp, [r11+8]
+  sub rsp, 8
+  jmp [r11+40]
+.L.morestack.overflow:
+  mov rdi, offset .L.rt.stackoverflow
+  call runtime.throw
+
+# runtime.lessstack is returned to by a function which started a segment of
+# the stack, and returns to its caller on the previous segment.
+runtime.lessstack:
+  call runtime.stackpop
+  mov rsp, [rcx+32]
+  ret
+
+# runtime.stackpop makes the running goroutine run on the previous segment
+# of its stack, and returns the segment left in RCX. Only RCX, RSI, RDI and
+# R11 are clobbered.
+runtime.stackpop:
+  mov r11, [rip+runtime.g]
+  mov rcx, [r11+80]
+  mov rsi, [rcx+8]
+  sub rsi, [rcx]
+  sub [r11+88], rsi
+  mov rsi, [rcx+16]
+  mov [r11+80], rsi
+  mov rdi, [rsi]
+  mov [r11+16], rdi
+  add rdi, 36864
+  mov [rip+runtime.stackguard], rdi
+  mov rdi, [rsi+8]
+  mov [r11+24], rdi
+  ret
+
+# runtime.starttimer sets runtime.preempt by a signal every time slice of
+# CPU time.
+runtime.starttimer:
+  push rbp
+  mov rbp, rsp
+  mov rdi, 26
+  mov rsi, offset runtime.sigpreempt
+  call signal
+  mov rdi, 1
+  mov rsi, offset .L.rt.timeslice
+  mov rdx, 0
+  call setitimer
+  pop rbp
+  ret
+
+# runtime.sigpreempt is the handler of the signal of the timer.
+runtime.sigpreempt:
+  mov qword ptr [rip+runtime.preempt], 1
+  ret
+
+# runtime.gopark parks the running goroutine until it is made runnable by
+# runtime.goready, and switches to the next runnable one. The goroutines are
+# deadlocked if there is none.
+runtime.gopark:
+  push rbp
+  mov rbp, rsp
+  mov rax, [rip+runtime.g]
+  mov qword ptr [rax+48], 1
+  call runtime.runqget
+  cmp rax, 0
+  je .L.gopark.deadlock
+  mov rdi, rax
+  call runtime.gogo
+  pop rbp
+  ret
+.L.gopark.deadlock:
+  mov rdi, offset .L.rt.deadlock
+  call runtime.throw
+
+# runtime.block parks the running goroutine forever, as an operation on a
+# nil channel or a select statement without cases does.
+runtime.block:
+  and rsp, -16
+  call runtime.gopark
+
+# A channel is an hchan of the pointer to its buffer, the size of an
+# element, the size of the buffer, the number of the elements in it, the
+# indices of the elements sent and received next, whether it is closed, and
+# the queues of the goroutines waiting to receive and to send, each of its
+# head and its tail. The buffer is a ring of the elements.
+#
+# A waiting goroutine is described by a sudog on its stack, of the next
+# sudog in the queue, the G, the address of the value to send or to receive
+# into, whether the communication succeeded rather than the channel was
+# closed, the channel, and the index of the case of a select statement. The
+# goroutine is woken with the sudog stored in its G, and waits in a select
+# statement with a sudog in the queue of each channel, of which those of a
+# goroutine already woken are skipped.
+
+# runtime.makechan(elemsize, size, desc) returns a new channel of elements
+# of elemsize bytes laid out as the descriptor desc tells, whose buffer holds
+# size elements.
+runtime.makechan:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  cmp rsi, 0
+  jl .L.makechan.panic
+  mov r12, rdi
+  mov r13, rsi
+  mov r14, rdx
+  mov rdi, 88
+  mov rsi, offset .L.rt.hchan
+  call runtime.alloc
+  mov rbx, rax
+  mov [rbx+8], r12
+  mov [rbx+16], r13
+  mov rdi, r12
+  imul rdi, r13
+  mov rsi, r14
+  call runtime.alloc
+  mov [rbx], rax
+  mov rax, rbx
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+.L.makechan.panic:
+  mov rdi, offset .L.rt.makechan
+  call runtime.panicf
+
+# runtime.chanbufput(c, elem) puts the value at elem at the tail of the
+# buffer of the channel c, which has space for it.
+runtime.chanbufput:
+  mov rax, rdi
+  mov rdi, [rax+32]
+  imul rdi, [rax+8]
+  add rdi, [rax]
+  mov rcx, [rax+8]
+  rep movsb
+  mov rcx, [rax+32]
+  inc rcx
+  cmp rcx, [rax+16]
+  jne .L.chanbufput.end
+  mov rcx, 0
+.L.chanbufput.end:
+  mov [rax+32], rcx
+  inc qword ptr [rax+24]
+  ret
+
+# runtime.chansend(c, elem) sends the value at elem on the channel c. The
+# value is handed to a waiting receiver, or put in the buffer if it has
+# space, or the sender waits for a receiver to take it.
+runtime.chansend:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  sub rsp, 48
+  mov rbx, rdi
+  mov r12, rsi
+  cmp rbx, 0
+  je runtime.block
+  cmp qword ptr [rbx+48], 0
+  jne .L.chansend.closed
+  lea rdi, [rbx+56]
+  call runtime.dequeue
+  cmp rax, 0
+  je .L.chansend.buffer
+  mov rdi, [rax+16]
+  cmp rdi, 0
+  je .L.chansend.ready
+  mov rsi, r12
+  mov rcx, [rbx+8]
+  rep movsb
+.L.chansend.ready:
+  mov rdi, rax
+  mov rsi, 1
+  call runtime.goready
+  jmp .L.chansend.end
+.L.chansend.buffer:
+  mov rax, [rbx+24]
+  cmp rax, [rbx+16]
+  je .L.chansend.wait
+  mov rdi, rbx
+  mov rsi, r12
+  call runtime.chanbufput
+  jmp .L.chansend.end
+.L.chansend.wait:
+  lea rsi, [rbp-64]
+  mov rax, [rip+runtime.g]
+  mov qword ptr [rax+72], 0
+  mov [rsi+8], rax
+  mov [rsi+16], r12
+  mov qword ptr [rsi+24], 0
+  mov [rsi+32], rbx
+  mov qword ptr [rsi+40], 0
+  lea rdi, [rbx+72]
+  call runtime.enqueue
+  call runtime.gopark
+  cmp qword ptr [rbp-40], 0
+  je .L.chansend.closed
+.L.chansend.end:
+  add rsp, 48
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+.L.chansend.closed:
+  mov rdi, offset .L.rt.sendclosed
+  call runtime.panicf
+
+# runtime.chanrecv(c, elem) receives a value from the channel c into elem,
+# unless it is 0, and returns 1 if the value was sent, or 0 if it is the
+# zero value of the closed channel. The value is taken from the buffer,
+# whose space is then taken by the value of a waiting sender, or from a
+# waiting sender of an unbuffered channel, or the receiver waits for a
+# sender.
+runtime.chanrecv:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  sub rsp, 48
+  mov rbx, rdi
+  mov r12, rsi
+  cmp rbx, 0
+  je runtime.block
+  cmp qword ptr [rbx+24], 0
+  jne .L.chanrecv.buffer
+  lea rdi, [rbx+72]
+  call runtime.dequeue
+  cmp rax, 0
+  jne .L.chanrecv.sender
+  cmp qword ptr [rbx+48], 0
+  jne .L.chanrecv.closed
+  lea rsi, [rbp-64]
+  mov rax, [rip+runtime.g]
+  mov qword ptr [rax+72], 0
+  mov [rsi+8], rax
+  mov [rsi+16], r12
+  mov qword ptr [rsi+24], 0
+  mov [rsi+32], rbx
+  mov qword ptr [rsi+40], 0
+  lea rdi, [rbx+56]
+  call runtime.enqueue
+  call runtime.gopark
+  mov rax, [rbp-40]
+  jmp .L.chanrecv.end
+.L.chanrecv.sender:
+  cmp r12, 0
+  je .L.chanrecv.ready
+  mov rdi, r12
+  mov rsi, [rax+16]
+  mov rcx, [rbx+8]
+  rep movsb
+.L.chanrecv.ready:
+  mov rdi, rax
+  mov rsi, 1
+  call runtime.goready
+  mov rax, 1
+  jmp .L.chanrecv.end
+.L.chanrecv.buffer:
+  # The element is cleared for the collector once it is taken.
+  mov r8, [rbx+40]
+  imul r8, [rbx+8]
+  add r8, [rbx]
+  cmp r12, 0
+  je .L.chanrecv.clear
+  mov rdi, r12
+  mov rsi, r8
+  mov rcx, [rbx+8]
+  rep movsb
+.L.chanrecv.clear:
+  mov rdi, r8
+  mov rcx, [rbx+8]
+  mov al, 0
+  rep stosb
+  dec qword ptr [rbx+24]
+  mov rax, [rbx+40]
+  inc rax
+  cmp rax, [rbx+16]
+  jne .L.chanrecv.recvx
+  mov rax, 0
+.L.chanrecv.recvx:
+  mov [rbx+40], rax
+  lea rdi, [rbx+72]
+  call runtime.dequeue
+  cmp rax, 0
+  je .L.chanrecv.received
+  mov [rbp-64], rax
+  mov rdi, rbx
+  mov rsi, [rax+16]
+  call runtime.chanbufput
+  mov rdi, [rbp-64]
+  mov rsi, 1
+  call runtime.goready
+.L.chanrecv.received:
+  mov rax, 1
+  jmp .L.chanrecv.end
+.L.chanrecv.closed:
+  cmp r12, 0
+  je .L.chanrecv.zero
+  mov rdi, r12
+  mov rcx, [rbx+8]
+  mov al, 0
+  rep stosb
+.L.chanrecv.zero:
+  mov rax, 0
+.L.chanrecv.end:
+  add rsp, 48
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+
+# runtime.closechan(c) closes the channel c, and wakes the goroutines
+# waiting on it. The receivers receive the zero value, and the senders
+# panic.
+runtime.closechan:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  sub rsp, 8
+  cmp rdi, 0
+  je .L.closechan.nil
+  cmp qword ptr [rdi+48], 0
+  jne .L.closechan.closed
+  mov rbx, rdi
+  mov qword ptr [rbx+48], 1
+.L.closechan.recv:
+  lea rdi, [rbx+56]
+  call runtime.dequeue
+  cmp rax, 0
+  je .L.closechan.send
+  mov rdi, [rax+16]
+  cmp rdi, 0
+  je .L.closechan.wakerecv
+  mov rdx, rax
+  mov rcx, [rbx+8]
+  mov al, 0
+  rep stosb
+  mov rax, rdx
+.L.closechan.wakerecv:
+  mov rdi, rax
+  mov rsi, 0
+  call runtime.goready
+  jmp .L.closechan.recv
+.L.closechan.send:
+  lea rdi, [rbx+72]
+  call runtime.dequeue
+  cmp rax, 0
+  je .L.closechan.end
+  mov rdi, rax
+  mov rsi, 0
+  call runtime.goready
+  jmp .L.closechan.send
+.L.closechan.end:
+  add rsp, 8
+  pop rbx
+  pop rbp
+  ret
+.L.closechan.nil:
+  mov rdi, offset .L.rt.closenil
+  call runtime.panicf
+.L.closechan.closed:
+  mov rdi, offset .L.rt.closeclosed
+  call runtime.panicf
+
+# runtime.selectgo(cases, n, block) does the communication of one of the n
+# cases of a select statement, and returns its index, and whether the value
+# was received if it is a receive in rdx. A case is the channel, the address
+# of the value to send or to receive into, and 1 if it is a receive or 0 if
+# it is a send. The cases are polled in a random order, so that each of the
+# ready ones is as likely to be done, and the first ready is. If none is, -1 is
+# returned for the default clause unless block is set, in which case the
+# goroutine waits on all the channels until a case is done by another
+# goroutine. The cases of nil channels are never ready.
+runtime.selectgo:
+  push rbp
+  mov rbp, rsp
+  push rbx
+  push r12
+  push r13
+  push r14
+  push r15
+  sub rsp, 8
+  mov rbx, rdi
+  mov r12, rsi
+  mov r13, rdx
+  # The order is a permutation of the indices of the cases on the stack,
+  # shuffled by Fisher-Yates, through which the count at [rbp-48] polls.
+  lea rax, [r12*8+15]
+  and rax, -16
+  sub rsp, rax
+  mov rcx, 0
+.L.selectgo.order:
+  cmp rcx, r12
+  je .L.selectgo.shuffle
+  mov [rsp+rcx*8], rcx
+  inc rcx
+  jmp .L.selectgo.order
+.L.selectgo.shuffle:
+  cmp rcx, 1
+  jbe .L.selectgo.shuffled
+  call runtime.fastrand
+  mov rdx, 0
+  div rcx
+  dec rcx
+  mov rax, [rsp+rcx*8]
+  mov r8, [rsp+rdx*8]
+  mov [rsp+rdx*8], rax
+  mov [rsp+rcx*8], r8
+  jmp .L.selectgo.shuffle
+.L.selectgo.shuffled:
+  mov qword ptr [rbp-48], 0
+.L.selectgo.poll:
+  mov rax, [rbp-48]
+  cmp rax, r12
+  je .L.selectgo.wait
+  mov r14, [rsp+rax*8]
+  lea r15, [r14+r14*2]
+  lea r15, [rbx+r15*8]
+  mov rdi, [r15]
+  cmp rdi, 0
+  je .L.selectgo.next
+  cmp qword ptr [r15+16], 0
+  je .L.selectgo.send
+  # A receive is ready if the buffer has a value, a sender waits, or the
+  # channel is closed.
+  cmp qword ptr [rdi+24], 0
+  jne .L.selectgo.recv
+  cmp qword ptr [rdi+48], 0
+  jne .L.selectgo.recv
+  add rdi, 72
+  call runtime.waitqfirst
+  cmp rax, 0
+  je .L.selectgo.next
+.L.selectgo.recv:
+  mov rdi, [r15]
+  mov rsi, [r15+8]
+  call runtime.chanrecv
+  mov rdx, rax
+  mov rax, r14
+  jmp .L.selectgo.end
+.L.selectgo.send:
+  # A send is ready if a receiver waits, the buffer has space, or the
+  # channel is closed to panic.
+  cmp qword ptr [rdi+48], 0
+  jne .L.selectgo.sendready
+  mov rax, [rdi+24]
+  cmp rax, [rdi+16]
+  jne .L.selectgo.sendready
+  add rdi, 56
+  call runtime.waitqfirst
+  cmp rax, 0
+  je .L.selectgo.next
+.L.selectgo.sendready:
+  mov rdi, [r15]
+  mov rsi, [r15+8]
+  call runtime.chansend
+  mov rdx, 0
+  mov rax, r14
+  jmp .L.selectgo.end
+.L.selectgo.next:
+  inc qword ptr [rbp-48]
+  jmp .L.selectgo.poll
+.L.selectgo.wait:
+  mov rax, -1
+  mov rdx, 0
+  cmp r13, 0
+  je .L.selectgo.end
+  mov rax, r12
+  imul rax, 48
+  sub rsp, rax
+  mov r15, rsp
+  mov rax, [rip+runtime.g]
+  mov qword ptr [rax+72], 0
+  mov r14, 0
+.L.selectgo.enqueue:
+  cmp r14, r12
+  je .L.selectgo.park
+  call .L.selectgo.queue
+  cmp rdi, 0
+  je .L.selectgo.nextenqueue
+  mov rax, [rip+runtime.g]
+  mov [rsi+8], rax
+  mov rax, [rcx+8]
+  mov [rsi+16], rax
+  mov qword ptr [rsi+24], 0
+  mov rax, [rcx]
+  mov [rsi+32], rax
+  mov [rsi+40], r14
+  call runtime.enqueue
+.L.selectgo.nextenqueue:
+  inc r14
+  jmp .L.selectgo.enqueue
+.L.selectgo.park:
+  call runtime.gopark
+  # The sudogs are removed from the queues, but for the one the goroutine
+  # was woken with, which is already dequeued.
+  mov r14, 0
+.L.selectgo.dequeue:
+  cmp r14, r12
+  je .L.selectgo.woken
+  call .L.selectgo.queue
+  cmp rdi, 0
+  je .L.selectgo.nextdequeue
+  call runtime.unlink
+.L.selectgo.nextdequeue:
+  inc r14
+  jmp .L.selectgo.dequeue
+.L.selectgo.woken:
+  mov rax, [rip+runtime.g]
+  mov rcx, [rax+72]
+  mov rdx, [rcx+24]
+  mov rax, [rcx+40]
+  lea rcx, [rax+rax*2]
+  cmp qword ptr [rbx+rcx*8+16], 0
+  jne .L.selectgo.end
+  cmp rdx, 0
+  je .L.selectgo.closed
+.L.selectgo.end:
+  lea rsp, [rbp-40]
+  pop r15
+  pop r14
+  pop r13
+  pop r12
+  pop rbx
+  pop rbp
+  ret
+.L.selectgo.closed:
+  mov rdi, offset .L.rt.sendclosed
+  call runtime.panicf
+# .L.selectgo.queue sets rcx to the case r14, rsi to its sudog, and rdi to
+# the queue of its channel it waits in, or 0 if the channel is nil.
+.L.selectgo.queue:
+  lea rcx, [r14+r14*2]
+  lea rcx, [rbx+rcx*8]
+  lea rsi, [r14+r14*2]
+  shl rsi, 4
+  add rsi, r15
+  mov rdi, [rcx]
+  cmp rdi, 0
+  je .L.selectgo.queueend
+  add rdi, 72
+  cmp qword ptr [rcx+16], 0
+  je .L.selectgo.queueend
+  sub rdi, 16
+.L.selectgo.queueend:
+  ret
+
+# runtime.fastrand returns a pseudo-random number of a xorshift generator,
+# which is seeded by the time stamp counter. Only RAX and RDX are clobbered.
+runtime.fastrand:
+  mov rax, [rip+runtime.randstate]
+  cmp rax, 0
+  jne .L.fastrand.next
+  rdtsc
+  shl rdx, 32
+  or rax, rdx
+  or rax, 1
+.L.fastrand.next:
+  mov rdx, rax
+  shl rdx, 13
+  xor rax, rdx
+  mov rdx, rax
+  shr rdx, 7
+  xor rax, rdx
+  mov rdx, rax
+  shl rdx, 17
+  xor rax, rdx
+  mov [rip+runtime.randstate], rax
+  ret
+
+# runtime.waitqfirst(q) returns the first sudog in the queue q whose
+# goroutine still waits, after removing those before it, or 0.
+runtime.waitqfirst:
+  mov rax, [rdi]
+  cmp rax, 0
+  je .L.waitqfirst.end
+  mov rcx, [rax+8]
+  cmp qword ptr [rcx+72], 0
+  je .L.waitqfirst.end
+  mov rcx, [rax]
+  mov [rdi], rcx
+  cmp rcx, 0
+  jne runtime.waitqfirst
+  mov [rdi+8], rcx
+  jmp runtime.waitqfirst
+.L.waitqfirst.end:
+  ret
+
+# runtime.dequeue(q) removes the first sudog whose goroutine still waits
+# from the queue q, and returns it, or 0.
+runtime.dequeue:
+  call runtime.waitqfirst
+  cmp rax, 0
+  je .L.dequeue.end
+  mov rcx, [rax]
+  mov [rdi], rcx
+  cmp rcx, 0
+  jne .L.dequeue.end
+  mov [rdi+8], rcx
+.L.dequeue.end:
+  ret
+
+# runtime.enqueue(q, sg) puts the sudog sg at the tail of the queue q.
+runtime.enqueue:
+  mov qword ptr [rsi], 0
+  mov rax, [rdi+8]
+  cmp rax, 0
+  je .L.enqueue.empty
+  mov [rax], rsi
+  jmp .L.enqueue.end
+.L.enqueue.empty:
+  mov [rdi], rsi
+.L.enqueue.end:
+  mov [rdi+8], rsi
+  ret
+
+# runtime.unlink(q, sg) removes the sudog sg from the queue q, if it is in
+# it.
+runtime.unlink:
+  mov rcx, 0
+  mov rax, [rdi]
+.L.unlink.next:
+  cmp rax, 0
+  je .L.unlink.end
+  cmp rax, rsi
+  je .L.unlink.found
+  mov rcx, rax
+  mov rax, [rax]
+  jmp .L.unlink.next
+.L.unlink.found:
+  mov rdx, [rax]
+  cmp rcx, 0
+  je .L.unlink.head
+  mov [rcx], rdx
+  jmp .L.unlink.tail
+.L.unlink.head:
+  mov [rdi], rdx
+.L.unlink.tail:
+  cmp [rdi+8], rax
+  jne .L.unlink.end
+  mov [rdi+8], rcx
+.L.unlink.end:
+  ret
+
+# runtime.goready(sg, success) makes the goroutine waiting with the sudog sg
+# runnable, with whether the communication succeeded.
+runtime.goready:
+  mov [rdi+24], rsi
+  mov rax, [rdi+8]
+  mov [rax+72], rdi
+  mov qword ptr [rax+48], 0
+  mov rdi, rax
+  jmp runtime.runqput
+
+# runtime.waitq(q) parks the running goroutine with a sudog in the queue q
+# until it is woken, and returns whether it was woken with success.
+runtime.waitq:
+  push rbp
+  mov rbp, rsp
+  sub rsp, 48
+  lea rsi, [rbp-48]
+  mov rax, [rip+runtime.g]
+  mov qword ptr [rax+72], 0
+  mov [rsi+8], rax
+  mov qword ptr [rsi+16], 0
+  mov qword ptr [rsi+24], 0
+  mov qword ptr [rsi+32], 0
+  mov qword ptr [rsi+40], 0
+  call runtime.enqueue
+  call runtime.gopark
+  mov rax, [rbp-24]
+  add rsp, 48
+  pop rbp
+  ret
+
+# A sync.Mutex is whether it is locked, and the queue of the goroutines
+# waiting to lock it. Unlocking a mutex some goroutine waits for hands it
+# over to the first of them, so that it stays locked.
+
+# sync.Mutex.Lock(m) locks the mutex m, waiting until it is unlocked.
+sync.Mutex.Lock:
+  cmp qword ptr [rdi], 0
+  jne .L.Mutex.Lock.wait
+  mov qword ptr [rdi], 1
+  ret
+.L.Mutex.Lock.wait:
+  add rdi, 8
+  jmp runtime.waitq
+
+# sync.Mutex.TryLock(m) locks the mutex m and returns 1 if it is unlocked,
+# or returns 0.
+sync.Mutex.TryLock:
+  mov rax, 0
+  cmp qword ptr [rdi], 0
+  jne .L.Mutex.TryLock.end
+  mov qword ptr [rdi], 1
+  mov rax, 1
+.L.Mutex.TryLock.end:
+  ret
+
+# sync.Mutex.Unlock(m) unlocks the mutex m, or hands it over to the first
+# goroutine waiting for it. Unlocking an unlocked mutex is a fatal error.
+sync.Mutex.Unlock:
+  cmp qword ptr [rdi], 0
+  je .L.Mutex.Unlock.throw
+  push rdi
+  add rdi, 8
+  call runtime.dequeue
+  pop rdi
+  cmp rax, 0
+  je .L.Mutex.Unlock.free
+  mov rdi, rax
+  mov rsi, 1
+  jmp runtime.goready
+.L.Mutex.Unlock.free:
+  mov qword ptr [rdi], 0
+  ret
+.L.Mutex.Unlock.throw:
+  and rsp, -16
+  mov rdi, offset .L.rt.unlock
+  call runtime.throw
+
+# A sync.WaitGroup is its counter, and the queue of the goroutines waiting
+# for it to become zero.
+
+# sync.WaitGroup.Add(wg, delta) adds delta to the counter of wg, and wakes
+# the goroutines waiting for it if it becomes zero. A negative counter
+# panics.
+sync.WaitGroup.Add:
+  add [rdi], rsi
+  jl .L.WaitGroup.Add.panic
+  jne .L.WaitGroup.Add.end
+  push rbx
+  mov rbx, rdi
+.L.WaitGroup.Add.wake:
+  lea rdi, [rbx+8]
+  call runtime.dequeue
+  cmp rax, 0
+  je .L.WaitGroup.Add.woken
+  mov rdi, rax
+  mov rsi, 1
+  call runtime.goready
+  jmp .L.WaitGroup.Add.wake
+.L.WaitGroup.Add.woken:
+  pop rbx
+.L.WaitGroup.Add.end:
+  ret
+.L.WaitGroup.Add.panic:
+  push rbp
+  mov rbp, rsp
+  mov rdi, offset .L.rt.negwaitgroup
+  call runtime.panicf
+
+# sync.WaitGroup.Done(wg) decrements the counter of wg.
+sync.WaitGroup.Done:
+  mov rsi, -1
+  jmp sync.WaitGroup.Add
+
+# sync.WaitGroup.Wait(wg) waits until the counter of wg is zero.
+sync.WaitGroup.Wait:
+  cmp qword ptr [rdi], 0
+  je .L.WaitGroup.Wait.end
+  add rdi, 8
+  jmp runtime.waitq
+.L.WaitGroup.Wait.end:
+  ret
+
+# runtime.throw(message) prints the message of a fatal error to the
+# standard error and exits with status 2.
+runtime.throw:
+  push rbp
+  mov rbp, rsp
+  mov rsi, rdi
+  mov rdi, 2
+  mov rax, 0
+  call dprintf
+  mov rdi, 2
+  call exit
+
+# runtime.findItab(type, table) returns the itable for the type descriptor
+# in the table of an interface type, or 0 if the type does not implement it.
+runtime.findItab:
+  mov rax, [rsi]
+  cmp rax, 0
+  je .L.findItab.end
+  cmp rax, rdi
+  je .L.findItab.found
+  add rsi, 16
+  jmp runtime.findItab
+.L.findItab.found:
+  mov rax, [rsi+8]
+.L.findItab.end:
+  ret
+
+# runtime.panicAssert(itab, format) panics with a failed type assertion on
+# an interface value with the itable. The format has a verb for the
+# name of the dynamic type.
+runtime.panicAssert:
+  push rbp
+  mov rbp, rsp
+  mov rdx, offset .L.rt.nil
+  cmp rdi, 0
+  je .L.panicAssert.print
+  mov rdx, [rdi]
+  mov rdx, [rdx]
+.L.panicAssert.print:
+  mov rdi, rsi
+  mov rsi, rdx
+  call runtime.panicf
+
+.L.rt.nil:
+  .string "nil"
+.L.rt.gogc:
+  .string "GOGC"
+.L.rt.off:
+  .string "off"
+.L.rt.outofmemory:
+  .string "fatal error: runtime: out of memory\n"
+.L.rt.deadlock:
+  .string "fatal error: all goroutines are asleep - deadlock!\n"
+.L.rt.stackoverflow:
+  .string "fatal error: stack overflow\n"
+.L.rt.nilderef:
+  .string "runtime error: invalid memory address or nil pointer dereference"
+.L.rt.divide:
+  .string "runtime error: integer divide by zero"
+.L.rt.panicnil:
+  .string "panic called with nil argument"
+.L.rt.panic:
+  .string "panic: "
+.L.rt.tab:
+  .string "\t"
+.L.rt.recovered:
+  .string " [recovered]"
+.L.rt.newline:
+  .string "\n"
+.L.rt.printint:
+  .string "%ld"
+.L.rt.printnamedint:
+  .string "%s(%ld)"
+.L.rt.printstring:
+  .string "%.*s"
+.L.rt.printnamedstring:
+  .string "%s(\"%.*s\")"
+.L.rt.printother:
+  .string "(%s) %p"
+.L.rt.errorname:
+  .string "runtime.Error"
+# The type of runtime errors, whose values are strings of their messages
+.L.rt.errortype:
+  .quad .L.rt.errorname, 16, 3, 2, 1, 0, 16, 1
+.L.rt.erroritab:
+  .quad .L.rt.errortype
+.L.rt.uncomparable:
+  .string "runtime error: comparing uncomparable type %s"
+.L.rt.nilmap:
+  .string "assignment to entry in nil map"
+.L.rt.makeslicelen:
+  .string "runtime error: makeslice: len out of range"
+.L.rt.makeslicecap:
+  .string "runtime error: makeslice: cap out of range"
+.L.rt.makechan:
+  .string "makechan: size out of range"
+.L.rt.sendclosed:
+  .string "send on closed channel"
+.L.rt.closenil:
+  .string "close of nil channel"
+.L.rt.closeclosed:
+  .string "close of closed channel"
+.L.rt.unlock:
+  .string "fatal error: sync: unlock of unlocked mutex\n"
+.L.rt.negwaitgroup:
+  .string "sync: negative WaitGroup counter"
+
+.data
+  .align 8
+# The header of a map holds a pointer to its table.
+.L.rt.hmap:
+  .quad 40, 1, 16
+# The header of a channel holds a pointer to its buffer.
+.L.rt.hchan:
+  .quad 88, 1, 0
+# A defer record holds pointers to the next record and to the closure.
+.L.rt.defer:
+  .quad 32, 2, 0, 8
+# A string header holds a pointer to the bytes.
+.L.rt.string:
+  .quad 16, 1, 0
+runtime.defers:
+  .quad 0
+runtime.panics:
+  .quad 0
+runtime.gcpercent:
+  .quad 100
+runtime.nextgc:
+  .quad 4194304
+runtime.heap:
+  .quad 0
+runtime.heaplen:
+  .quad 0
+runtime.heapcap:
+  .quad 0
+runtime.heapsize:
+  .quad 0
+runtime.markstack:
+  .quad 0
+runtime.marklen:
+  .quad 0
+runtime.markcap:
+  .quad 0
+# The state of runtime.fastrand, which is seeded when it is 0
+runtime.randstate:
+  .quad 0
+# The main goroutine, which is the first of all
+runtime.g0:
+  .zero 128
+runtime.g:
+  .quad runtime.g0
+# The head of the list of all goroutines, and whether any has been created
+runtime.allgs:
+  .quad runtime.g0, 0
+runtime.gfree:
+  .quad 0
+# The head and the tail of the run queue
+runtime.runq:
+  .quad 0, 0
+# The lowest address a frame of the running goroutine may extend to without
+# a new segment of the stack, and the limit of the size of the segments in
+# use by a goroutine
+runtime.stackguard:
+  .quad 0
+runtime.maxstack:
+  .quad 1000000000
+runtime.preempt:
+  .quad 0
+# The interval and the initial value of the timer, of 10 ms
+.L.rt.timeslice:
+  .quad 0, 10000, 0, 10000
+`
+
+func emitRuntime() {
+	os.Stdout.WriteString(runtimeText)
+}
@@ -16,121 +16,191 @@ try() {
   fi
 }
 
-try 0 "func main() {return 0}"
-try 42 "func main() {return 42}"
-try 41 "func main() {return 12 + 34 - 5}"
-try 47 "func main() {return 5+6*7}"
-try 15 "func main() {return 5*(9-6)}"
-try 4 "func main() {return (3+5)/2}"
-try 10 "func main() {return -10+20}"
-try 10 "func main() {return - -10}"
-try 10 "func main() {return - - +10}"
-try 0 "func main() {return 0==1}"
-try 1 "func main() {return 42==42}"
-try 1 "func main() {return 0!=1}"
-try 0 "func main() {return 42!=42}"
-
-try 1 "func main() {return 0<1}"
-try 0 "func main() {return 1<1}"
-try 0 "func main() {return 2<1}"
-try 1 "func main() {return 0<=1}"
-try 1 "func main() {return 1<=1}"
-try 0 "func main() {return 2<=1}"
-
-try 1 "func main() {return 1>0}"
-try 0 "func main() {return 1>1}"
-try 0 "func main() {return 1>2}"
-try 1 "func main() {return 1>=0}"
-try 1 "func main() {return 1>=1}"
-try 0 "func main() {return 1>=2}"
-
-try 2 "func main() {var a int;a=2;return a;}"
-try 10 "func main() {var a int;var c int;a=2;c=10return c}"
-try 99 "func main() {var a int;var z int;a=1;z=99;return z}"
-try 32 "func main() {var a int;var z int;a=1;z=10; return 32}"
-try 57 "func main() {var triple int;var nineteen int;triple=3; nineteen=19;return nineteen*triple}"
-try 5 "
-func main() {
-  return 5
-  return 10;
-}"
-try 14 "
+trypanic() {
+  expected="$1"
+  input="$2"
+
+  ./9gc "$input" > tmp.s
+  gcc -static -o tmp tmp.s
+  actual=$(./tmp 2>&1 >/dev/null | head -1)
+  ./tmp 2>/dev/null
+  status="$?"
+
+  if [ "$status" = 2 ] && [ "$actual" = "panic: $expected" ]; then
+    echo "$input => $expected"
+  else
+    echo "$input => \"$expected\" expected, but got \"$actual\" ($status)"
+    exit 1
+  fi
+}
+
+tryerr() {
+  expected="$1"
+  input="$2"
+
+  actual=$(./9gc "$input" 2>&1 >/dev/null | head -1)
+
+  if [ "$actual" = "panic: $expected" ]; then
+    echo "$input => $expected"
+  else
+    echo "$input => \"$expected\" expected, but got \"$actual\""
+    exit 1
+  fi
+}
+
+# tryfatal runs the program, which dies of a fatal error of the runtime.
+tryfatal() {
+  expected="$1"
+  input="$2"
+
+  ./9gc "$input" > tmp.s
+  gcc -static -o tmp tmp.s
+  actual=$(./tmp 2>&1 >/dev/null | head -1)
+  ./tmp 2>/dev/null
+  status="$?"
+
+  if [ "$status" = 2 ] && [ "$actual" = "fatal error: $expected" ]; then
+    echo "$input => $expected"
+  else
+    echo "$input => \"$expected\" expected, but got \"$actual\" ($status)"
+    exit 1
+  fi
+}
+
+# trylimit runs the program with its address space limited, which it
+# exceeds unless the garbage is collected.
+trylimit() {
+  expected="$1"
+  input="$2"
+
+  ./9gc "$input" > tmp.s
+  gcc -static -o tmp tmp.s
+  (ulimit -v 65536; ./tmp 2>/dev/null)
+  actual="$?"
+
+  if [ "$actual" = "$expected" ]; then
+    echo "$input => $actual"
+  else
+    echo "$input => $expected expected, but got $actual"
+    exit 1
+  fi
+}
+
+try 0 'func main() {}'
+try 42 'import "os"; func main() {os.Exit(42)}'
+try 41 'import "os"; func main() {os.Exit(12 + 34 - 5)}'
+try 47 'import "os"; func main() {os.Exit(5+6*7)}'
+try 15 'import "os"; func main() {os.Exit(5*(9-6))}'
+try 4 'import "os"; func main() {os.Exit((3+5)/2)}'
+try 10 'import "os"; func main() {os.Exit(-10+20)}'
+try 10 'import "os"; func main() {os.Exit(- -10)}'
+try 10 'import "os"; func main() {os.Exit(- - +10)}'
+try 0 'import "os"; func main() {os.Exit(0==1)}'
+try 1 'import "os"; func main() {os.Exit(42==42)}'
+try 1 'import "os"; func main() {os.Exit(0!=1)}'
+try 0 'import "os"; func main() {os.Exit(42!=42)}'
+
+try 1 'import "os"; func main() {os.Exit(0<1)}'
+try 0 'import "os"; func main() {os.Exit(1<1)}'
+try 0 'import "os"; func main() {os.Exit(2<1)}'
+try 1 'import "os"; func main() {os.Exit(0<=1)}'
+try 1 'import "os"; func main() {os.Exit(1<=1)}'
+try 0 'import "os"; func main() {os.Exit(2<=1)}'
+
+try 1 'import "os"; func main() {os.Exit(1>0)}'
+try 0 'import "os"; func main() {os.Exit(1>1)}'
+try 0 'import "os"; func main() {os.Exit(1>2)}'
+try 1 'import "os"; func main() {os.Exit(1>=0)}'
+try 1 'import "os"; func main() {os.Exit(1>=1)}'
+try 0 'import "os"; func main() {os.Exit(1>=2)}'
+
+try 2 'import "os"; func main() {var a int;a=2;os.Exit(a);}'
+try 10 'import "os"; func main() {var a int;var c int;a=2;c=10os.Exit(c)}'
+try 99 'import "os"; func main() {var a int;var z int;a=1;z=99;os.Exit(z)}'
+try 32 'import "os"; func main() {var a int;var z int;a=1;z=10; os.Exit(32)}'
+try 57 'import "os"; func main() {var triple int;var nineteen int;triple=3; nineteen=19;os.Exit(nineteen*triple)}'
+try 5 'import "os"
+func main() {
+  os.Exit(5)
+  os.Exit(10);
+}'
+try 14 'import "os"
 func main() {
   var a int
   var b int
   a = 3
   b = 5 * 6 - 8
-  return a + b / 2
+  os.Exit(a + b / 2)
 }
-"
-try 1 "
+'
+try 1 'import "os"
 func main() {
   a := 1
   b := 4
   if a == 1 {
-    return a
+    os.Exit(a)
   }
-  return b
+  os.Exit(b)
 }
-"
-try 4 "
+'
+try 4 'import "os"
 func main() { 
   a := 1
   b := 4
   if a != 1 {
-    return a
+    os.Exit(a)
   }
-  return b
+  os.Exit(b)
 }
-"
-try 10 "
+'
+try 10 'import "os"
 func main() {
   if 1>2 {
-    return 0
+    os.Exit(0)
   } else {
-    return 10
+    os.Exit(10)
   }
 }
-"
-try 10 "
+'
+try 10 'import "os"
 func main() {
   if 1>2 {
-    return 0
+    os.Exit(0)
   } else if 1==0 {
-    return -1
+    os.Exit(-1)
   } else {
-    return 10
+    os.Exit(10)
   }
 }
-"
-try 10 "
+'
+try 10 'import "os"
 func main() {
   j := 0
   for i := 0; i < 10; i++ {
     j++
   }
-  return j
+  os.Exit(j)
 }
-"
-try 1 "
+'
+try 1 'import "os"
 func main() {
   i := 10
   for i > 1 {
     i--
   }
-  return i
+  os.Exit(i)
 }
-"
-try 3 "
+'
+try 3 'import "os"
 func main() {
   x:=1;y:=2
   if z:=x+y; z==3 {
-    return z
+    os.Exit(z)
   }
-  return -1
+  os.Exit(-1)
 }
-"
-try 7 "
+'
+try 7 'import "os"
 func main() {
   n := 0
   for i := 0; i < 5; i++ {
@@ -138,71 +208,783 @@ func main() {
     if i == 1 { n++ }
     if i == 3 { n++ }
   }
-  return n
+  os.Exit(n)
 }
-"
-try 3 "
+'
+try 3 'import "os"
 func main() {
-  return add(1, 2)
+  os.Exit(add(1, 2))
 }
 func add(a int, b int) int {
   return a + b
 }
-"
-try 89 '
+'
+try 89 'import "os"
 func main() { 
-  return fib(10)
+  os.Exit(fib(10))
 }
 func fib(x int) int { 
   if x<=1 {return 1}
   return fib(x-1) + fib(x-2)
 }
 '
-try 3 '
+try 3 'import "os"
 func main() {
   x := 3
   y := &x
-  return *y
+  os.Exit(*y)
 }
 '
-try 3 '
+try 3 'import "os"
 func main() {
   var x int
   var y *int
   y = &x;
   *y = 3
-  return x
+  os.Exit(x)
 }
 '
 try 0 '
 func main() {
   var a [2][3]int
+}
+'
+try 15 'import "os"; func main() { var x [2]int; x[0]=3; x[1]=5; os.Exit(x[0] * x[1]); }'
+try 2 'import "os"; func main() { var x [2][3]int; x[1][2]=2; os.Exit(x[1][2]); }'
+try 1 'import "os"; func main() { var x [2][3]int; x[0][0]=1; y:=x; os.Exit(y[0][0]); }'
+try 0 'import "os"; var x int; func main() { os.Exit(x) }'
+try 3 'import "os"; var x int; func main() { x=3; os.Exit(x) }'
+try 0 'import "os"; var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; os.Exit(x[0]) }'
+try 1 'import "os"; var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; os.Exit(x[1]) }'
+try 2 'import "os"; var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; os.Exit(x[2]) }'
+try 3 'import "os"; var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; os.Exit(x[3]) }'
+try 1 'import "os"; func main() { var b byte; b = 1; os.Exit(int(b)) }'
+try 97 'import "os"; func main() { os.Exit(int("abc"[0])) }'
+try 98 'import "os"; func main() { os.Exit(int("abc"[1])) }'
+try 99 'import "os"; func main() { a := "abc"; os.Exit(int(a[2])) }'
+tryerr 'invalid argument: index 3 out of bounds [0:3]' 'func main() { v := "abc"[3] }'
+try 7 'import "os"; func main() { os.Exit(int("\a"[0])); }'
+try 8 'import "os"; func main() { os.Exit(int("\b"[0])); }'
+try 9 'import "os"; func main() { os.Exit(int("\t"[0])); }'
+try 10 'import "os"; func main() { os.Exit(int("\n"[0])); }'
+try 11 'import "os"; func main() { os.Exit(int("\v"[0])); }'
+try 12 'import "os"; func main() { os.Exit(int("\f"[0])); }'
+try 13 'import "os"; func main() { os.Exit(int("\r"[0])); }'
+try 34 'import "os"; func main() { os.Exit(int("\""[0])); }'
+try 92 'import "os"; func main() { os.Exit(int("\\"[0])); }'
+try 98 'import "os"; func main() { os.Exit(int("\abc\n"[1])) }'
+
+try 5 'import "os"; type Celsius int; func main() { var c Celsius; c = 5; os.Exit(int(c)) }'
+try 40 'import "os"; func main() { var c Celsius; c = 20; os.Exit(int(double(c))) }; func double(c Celsius) Celsius { return c * 2 }; type Celsius int'
+try 3 'import "os"; type A = int; func main() { var a A; var b int; a = 1; b = 2; os.Exit(a + b) }'
+try 7 'import "os"; func main() { type T int; var t T; t = 7; os.Exit(int(t)) }'
+try 44 'import "os"; func main() { x := 300; os.Exit(int(byte(x))) }'
+try 200 'import "os"; func main() { var b byte; b = byte(200); os.Exit(int(b)) }'
+try 3 'import "os"; type P *int; func main() { x := 3; var p P; p = &x; os.Exit(*p) }'
+try 2 'import "os"; type Arr [2]int; type Grid [2]Arr; func main() { var g Grid; g[1][1] = 2; os.Exit(g[1][1]) }'
+try 3 'import "os"; type (A int; B = A); func main() { var b B; b = 3; var a A; a = b; os.Exit(int(a)) }'
+try 6 'import "os"; type A B; type B int; func main() { var a A; a = 6; os.Exit(int(a)) }'
+try 4 'import "os"; type A [2]int; func main() { var a A; var b [2]int; b[1] = 4; a = b; os.Exit(a[1]) }'
+try 1 'import "os"; type T int; func main() { var x T; x = 2; { type T byte; var y T; y = 1; os.Exit(int(y)) } }'
+tryerr 'cannot use type int as type Celsius in assignment' 'type Celsius int; func main() { var c Celsius; var i int; c = i }'
+tryerr 'invalid operation: mismatched types Celsius and int' 'type Celsius int; func main() { var c Celsius; var i int; v := c + i }'
+tryerr 'cannot use type int as type C in argument to f' 'type C int; func f(c C) int { return 1 }; func main() { var i int; v := f(i) }'
+tryerr 'cannot use type int as type C in return argument' 'type C int; func f() C { var i int; return i }; func main() {}'
+tryerr 'cannot convert type *int to type C' 'type C int; func main() { var p *int; v := C(p) }'
+tryerr 'invalid recursive type A' 'type A B; type B A; func main() {}'
+tryerr 'invalid recursive type T' 'func main() { type T [2]T }'
+tryerr 'undefined: T' 'func main() { var t T }'
+
+try 3 'import "os"; type Point struct { x int; y int }; func main() { var p Point; p.x = 1; p.y = 2; os.Exit(p.x + p.y) }'
+try 7 'import "os"; type Point struct { x, y int }; func main() { var p Point; q := &p; q.y = 7; os.Exit(p.y) }'
+try 5 'import "os"; type Line struct { a, b Point }; type Point struct { x, y int }; func main() { var l Line; l.b.y = 5; m := l; l.b.y = 1; os.Exit(m.b.y) }'
+try 9 'import "os"; type S struct { b byte; n int; a [2]byte }; func main() { var s S; s.n = 9; s.a[1] = 4; os.Exit(s.n * int(s.a[0] + 1)) }'
+try 24 'import "os"; type S struct { b byte; n int; c byte }; func main() { var s S; os.Exit(int(s.b) + s.n + int(s.c) + 24) }'
+try 4 'import "os"; type Node struct { v int; next *Node }; func main() { var a Node; var b Node; a.next = &b; b.v = 4; os.Exit(a.next.v) }'
+try 0 'import "os"; func main() { var p struct { x int }; os.Exit(p.x) }'
+try 51 'import "os"
+type Point struct { x, y int }
+func (p *Point) Move(dx int, dy int) { p.x = p.x + dx; p.y = p.y + dy }
+func (p Point) Sum() int { return p.x + p.y }
+func (p Point) Scaled(k int) Point { p.x = p.x * k; p.y = p.y * k; return p }
+func main() {
+  var p Point
+  p.x = 1
+  p.y = 2
+  p.Move(3, 4)
+  q := &p
+  q.Move(1, 1)
+  r := q.Scaled(2)
+  os.Exit(r.Sum() + p.Sum() + q.Scaled(3).x)
+}
+'
+try 2 'import "os"; type Point struct { x int }; func (p Point) Set(x int) { p.x = x }; func main() { var p Point; p.x = 2; p.Set(5); os.Exit(p.x) }'
+try 86 'import "os"
+func main() {
+  var c Celsius
+  c = 30
+  os.Exit(int(c.Fahrenheit()))
+}
+func (c Celsius) Fahrenheit() Fahrenheit { return Fahrenheit(c*9/5 + 32) }
+type Celsius int
+type Fahrenheit int
+'
+try 3 'import "os"; type T int; func (t T) add(n int) int { return int(t) + n }; func add(a int, b int) int { return a * b }; func main() { var t T; t = 1; os.Exit(t.add(2) * add(1, 1) + add(0, 1)) }'
+try 6 'import "os"; type C struct { n int }; func (c *C) Inc() int { c.n++; return c.n }; func main() { var cs [2]C; cs[1].Inc(); cs[1].Inc(); os.Exit(cs[1].Inc() * 2) }'
+try 10 'import "os"; type P struct { a, b int }; func mk(a int, b int) P { var p P; p.a = a; p.b = b; return p }; func sum(p P, q P) int { return p.a + p.b + q.a + q.b }; func main() { os.Exit(sum(mk(1, 2), mk(3, 4))) }'
+tryerr 'cannot call pointer method Inc on C' 'type C struct { n int }; func (c *C) Inc() { c.n++ }; func mk() C { var c C; return c }; func main() { mk().Inc() }'
+tryerr 'Point.z undefined (type Point has no field or method z)' 'type Point struct { x int }; func main() { var p Point; v := p.z }'
+tryerr 'method T.f already declared' 'type T int; func (t T) f() {}; func (t *T) f() {}; func main() {}'
+tryerr 'field and method with the same name x' 'type T struct { x int }; func (t T) x() {}; func main() {}'
+tryerr 'invalid receiver type *int' 'func (p *int) f() {}; func main() {}'
+tryerr 'duplicate field x' 'type T struct { x int; x byte }; func main() {}'
+tryerr 'invalid operation: operator + not defined on T' 'type T struct { x int }; func main() { var a T; var b T; v := a + b }'
+
+try 22 'import "os"
+type Shape interface { Area() int; Scale(n int) }
+type Rect struct { w, h int }
+func (r Rect) Area() int { return r.w * r.h }
+func (r Rect) Scale(n int) { r.w = r.w * n }
+type Square struct { s int }
+func (q *Square) Area() int { return q.s * q.s }
+func (q *Square) Scale(n int) { q.s = q.s * n }
+func total(a Shape, b Shape) int { return a.Area() + b.Area() }
+func main() {
+  var r Rect
+  r.w = 2
+  r.h = 3
+  var q Square
+  q.s = 2
+  var s Shape
+  s = r
+  s.Scale(10)
+  r.w = 100
+  var t Shape
+  t = &q
+  t.Scale(2)
+  os.Exit(total(s, t))
+}
+'
+try 7 'import "os"; type I interface { Get() int }; type T int; func (t T) Get() int { return int(t) }; func mk(n int) I { return T(n) }; func main() { var t T; t = 3; var i I; i = &t; t = 4; os.Exit(mk(3).Get() + i.Get()) }'
+try 3 'import "os"; type I interface { M() }; type T int; func (t T) M() {}; func main() { var i I; n := 0; if i == nil { n = n + 1 }; var t T; i = t; if i != nil { n = n + 2 }; os.Exit(n) }'
+try 1 'import "os"; type I interface { M() }; type T int; func (t *T) M() {}; func main() { var p *T; var i I; i = p; os.Exit(i != nil) }'
+try 2 'import "os"; func f(x any) int { if x == nil { return 1 }; return 2 }; func main() { var e interface{}; e = 5; os.Exit(f(e)) }'
+try 5 'import "os"
+type Getter interface { Get() int }
+type GetSetter interface { Getter; Set(n int) }
+type C struct { n int }
+func (c *C) Get() int { return c.n }
+func (c *C) Set(n int) { c.n = n }
+func use(g Getter) int { return g.Get() }
+func main() { var c C; var gs GetSetter; gs = &c; gs.Set(5); os.Exit(use(&c)) }
+'
+try 8 'import "os"; type P struct { x, y int }; type I interface { Move(d int) P }; func (p P) Move(d int) P { p.x = p.x + d; p.y = p.y + d; return p }; func main() { var p P; p.x = 1; var i I; i = p; q := i.Move(3); os.Exit(q.x + q.y + p.x) }'
+tryerr 'cannot use type T as type I in assignment: T does not implement I (missing method M)' 'type I interface { M() }; type T int; func (t *T) M() {}; func main() { var t T; var i I; i = t }'
+tryerr 'cannot use type int as type I in argument to f: int does not implement I (missing method M)' 'type I interface { M() }; func f(i I) {}; func main() { f(1) }'
+tryerr 'duplicate method M' 'type I interface { M(); M() }; func main() {}'
+
+try 12 'import "os"; type T struct { a, b int }; func main() { var x any; x = 5; n := x.(int); var t T; t.a = 3; t.b = 4; x = t; os.Exit(n + x.(T).a + x.(T).b) }'
+try 8 'import "os"
+type I interface { Get() int }
+type T int
+func (t T) Get() int { return int(t) }
+func main() {
+  var x any
+  x = T(4)
+  v, ok := x.(int)
+  t, ok2 := x.(T)
+  i, ok3 := x.(I)
+  os.Exit(v + ok + int(t) + ok2 * 2 + i.Get() - 4 + ok3 * 2)
+}
+'
+try 7 'import "os"; type S struct { a [3]int }; func main() { var x any; var y any; x = 3; s, ok := x.(S); _, ok2 := y.(int); os.Exit(s.a[0] + s.a[1] + s.a[2] + ok + ok2 + 7) }'
+try 42 'import "os"
+type Shape interface { Area() int }
+type Named interface { Name() int }
+type Sq struct { s int }
+func (q Sq) Area() int { return q.s * q.s }
+func (q Sq) Name() int { return 40 }
+func main() {
+  var q Sq
+  q.s = 1
+  var s Shape
+  s = q
+  var x any
+  x = s
+  n := x.(Named)
+  var a any
+  a = n
+  os.Exit(n.Name() + a.(Shape).Area() + a.(Sq).s)
+}
+'
+try 29 'import "os"
+type T struct { x int }
+type I interface { M() int }
+func (t *T) M() int { return t.x }
+func kind(x any) int {
+  switch v := x.(type) {
+  case nil:
+    return 1
+  case int:
+    return v + 1
+  case byte, T:
+    return 3
+  case I:
+    return v.M()
+  default:
+    return 5
+  }
   return 0
 }
+func main() {
+  var t T
+  t.x = 20
+  var b byte
+  var a [2]int
+  os.Exit(kind(nil) + kind(1) + kind(b) + kind(t) + kind(&t) + kind(a) - kind(a))
+}
+'
+try 6 'import "os"; func main() { var x any; x = 6; switch y := x; y.(type) { case int: os.Exit(y.(int)) } }'
+try 3 'import "os"; func main() { var x any; n := 0; switch x.(type) { default: n = n + 1; case int: n = 2 }; x = 1; switch x.(type) { case byte: n = 10; case int: n = n + 2 }; os.Exit(n) }'
+try 1 'import "os"; type I interface { M() }; func main() { var i I; var x any; x = i; os.Exit(x == nil) }'
+trypanic 'interface conversion: interface{} is int, not byte' 'import "os"; func main() { var x any; x = 1; os.Exit(int(x.(byte))) }'
+trypanic 'interface conversion: interface{} is nil, not int' 'import "os"; func main() { var x interface{}; os.Exit(x.(int)) }'
+trypanic 'interface conversion: interface{} is T, not I' 'type T int; type I interface { M() }; func main() { var x any; x = T(1); i := x.(I) }'
+tryerr 'impossible type assertion: T does not implement I (missing method M)' 'type T int; type I interface { M() }; func main() { var i I; v := i.(T) }'
+tryerr 'invalid type assertion: non-interface type int on left' 'func main() { x := 1; v := x.(int) }'
+tryerr 'use of .(type) outside type switch' 'func main() { var x any; y := x.(type) }'
+tryerr 'duplicate case int in type switch' 'func main() { var x any; switch x.(type) { case int: case int: } }'
+tryerr 'multiple defaults in switch' 'func main() { var x any; switch x.(type) { default: default: } }'
+tryerr 'assignment mismatch: 2 variables but 1 value' 'func main() { a, b := 1; v := a }'
+tryerr 'no new variables on left side of :=' 'func main() { var x any; var a int; var ok int; a, ok := x.(int); v := a }'
+
+try 15 'import "os"; func main() { var a [5]int; a[0] = 1; a[1] = 2; a[2] = 3; a[3] = 4; a[4] = 5; s := a[1:4]; s[0] = 10; os.Exit(a[1] + len(s) + cap(s) - 2) }'
+try 6 'import "os"; func main() { var a [5]int; s := a[1:3:4]; t := a[:]; u := a[3:]; v := s[:0]; os.Exit(len(s) + cap(s) - len(u) + len(t) - 5 + cap(v)) }'
+try 55 'import "os"
+func sum(s []int) int {
+  n := 0
+  for i := 0; i < len(s); i++ {
+    n = n + s[i]
+  }
+  return n
+}
+func main() {
+  var s []int
+  for i := 1; i <= 10; i++ {
+    s = append(s, i)
+  }
+  os.Exit(sum(s))
+}
 '
-try 15 'func main() { var x [2]int; x[0]=3; x[1]=5; return x[0] * x[1]; }'
-try 2 'func main() { var x [2][3]int; x[1][2]=2; return x[1][2]; }'
-try 1 'func main() { var x [2][3]int; x[0][0]=1; y:=x; return y[0][0]; }'
-try 0 'var x int; func main() { return x }'
-try 3 'var x int; func main() { x=3; return x }'
-try 0 'var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; return x[0] }'
-try 1 'var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; return x[1] }'
-try 2 'var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; return x[2] }'
-try 3 'var x [4]int; func main() { x[0]=0; x[1]=1; x[2]=2; x[3]=3; return x[3] }'
-try 1 'func main() { var b byte; b = 1; return b }'
-try 97 'func main() { return "abc"[0] }'
-try 98 'func main() { return "abc"[1] }'
-try 99 'func main() { a := "abc"; return a[2] }'
-try 0 'func main() { return "abc"[3] }'
-try 7 'func main() { return "\a"[0]; }'
-try 8 'func main() { return "\b"[0]; }'
-try 9 'func main() { return "\t"[0]; }'
-try 10 'func main() { return "\n"[0]; }'
-try 11 'func main() { return "\v"[0]; }'
-try 12 'func main() { return "\f"[0]; }'
-try 13 'func main() { return "\r"[0]; }'
-try 34 'func main() { return "\""[0]; }'
-try 92 'func main() { return "\\"[0]; }'
-try 98 'func main() { return "\abc\n"[1] }'
-
-echo OK
\ No newline at end of file
+try 28 'import "os"; func main() { var s []int; s = append(s, 1, 2, 3); t := append(s[:1], 5); os.Exit(s[0] + s[1] * 4 + s[2] * 2 + len(t) - cap(s) + 2) }'
+try 10 'import "os"; func main() { var s []int; var t []int; s = append(s, 1, 2); t = append(t, 3, 4); s = append(s, t...); os.Exit(s[0] + s[1] + s[2] + s[3]) }'
+try 31 'import "os"; func main() { var a [4]int; a[0] = 1; a[1] = 2; a[2] = 3; a[3] = 4; s := a[:]; n := copy(s[1:], s); os.Exit(n * 10 + s[3] - a[2] + a[1] - 1) }'
+try 3 'import "os"; func main() { var s []byte; s = append(s, 1, 2); var t []byte; t = append(t, 5, 6, 7); os.Exit(copy(t, s) + int(t[0]) + int(t[2]) - 7) }'
+try 1 'import "os"; func main() { var s []int; var t []int; t = append(t, 1); s = nil; os.Exit((s == nil) + (t == nil)) }'
+try 11 'import "os"; type P struct { x, y int }; func f(n int) []P { var ps []P; var p P; for i := 0; i < n; i++ { p.x = i; p.y = i * 2; ps = append(ps, p) }; return ps }; func main() { ps := f(3); os.Exit(ps[2].x + ps[2].y + len(ps) + ps[1].y) }'
+try 7 'import "os"; func main() { var a [3]int; p := &a; p[2] = 4; s := p[1:]; os.Exit(s[1] + len(p)) }'
+try 2 'import "os"; func main() { var s [][]int; var r []int; r = append(r, 2); s = append(s, r); os.Exit(s[0][0]) }'
+trypanic 'runtime error: index out of range [3] with length 3' 'import "os"; func main() { var s []int; s = append(s, 1, 2, 3); os.Exit(s[len(s)]) }'
+trypanic 'runtime error: index out of range [5] with length 2' 'import "os"; func main() { var a [2]int; i := 5; os.Exit(a[i]) }'
+trypanic 'runtime error: slice bounds out of range [:4] with capacity 3' 'func main() { var s []int; s = append(s, 1, 2, 3); i := 4; t := s[:i] }'
+trypanic 'runtime error: slice bounds out of range [2:1]' 'func main() { var a [3]int; i := 2; t := a[i:1] }'
+trypanic 'runtime error: slice bounds out of range [::5] with length 3' 'func main() { var a [3]int; i := 5; t := a[0:1:i] }'
+tryerr 'invalid argument: index 4 out of bounds [0:4]' 'func main() { var a [3]int; s := a[1:4] }'
+tryerr 'middle index required in 3-index slice' 'func main() { var a [3]int; s := a[1::2] }'
+tryerr 'invalid argument: int is not a slice' 'func main() { x := 1; x = append(x, 1) }'
+tryerr 'cannot use type [1]int as type int in argument to append' 'func main() { var s []int; var a [1]int; s = append(s, a) }'
+tryerr 'invalid argument: arguments to copy have different element types int and byte' 'func main() { var s []int; var t []byte; v := copy(s, t) }'
+tryerr 'invalid operation: operator == not defined on []int' 'func main() { var s []int; var t []int; v := s == t }'
+
+try 5 'import "os"; func main() { s := "hello"; os.Exit(len(s)) }'
+try 0 'import "os"; func main() { os.Exit(len("")) }'
+try 108 'import "os"; func main() { var s string; s = "hello"; t := s; os.Exit(int(t[3])) }'
+try 3 'import "os"; func main() { s := "hello, world"; t := s[7:10]; os.Exit(len(t) + len(s[:0])) }'
+try 114 'import "os"; func main() { s := "hello, world"; t := s[7:]; os.Exit(int(t[2])) }'
+try 12 'import "os"; func main() { s := "hello"; t := ", world"; u := s + t; os.Exit(len(u) + (u[5] == 44) - 1) }'
+try 7 'import "os"; func main() { a := "ab"; b := "ab"; c := "abc"; os.Exit((a == b) + (a != c) * 2 + (a < c) * 4 + (c < a) * 8 + (a <= b) * 0) }'
+try 1 'import "os"; func main() { os.Exit(("b" > "abc") + ("" < "")) }'
+try 6 'import "os"; func cat(a string, b string) string { return a + b }; func main() { s := ""; for i := 0; i < 3; i++ { s = cat(s, "ab") }; os.Exit(len(s)) }'
+try 9 'import "os"; type Name string; type P struct { name Name; age int }; func main() { var p P; p.name = "Gopher"; p.age = 3; q := p; os.Exit(len(q.name) + q.age) }'
+try 4 'import "os"; func main() { var x any; x = "four"; s, ok := x.(string); switch x.(type) { case string: os.Exit(len(s) * ok) } }'
+try 3 'import "os"; func main() { var ss []string; ss = append(ss, "a", "bb"); os.Exit(len(ss[0] + ss[1])) }'
+trypanic 'runtime error: index out of range [3] with length 3' 'import "os"; func main() { s := "abc"; i := 3; os.Exit(int(s[i])) }'
+trypanic 'runtime error: slice bounds out of range [:4] with length 3' 'func main() { s := "abc"; i := 4; t := s[1:i] }'
+tryerr 'invalid argument: index 4 out of bounds [0:4]' 'func main() { t := "abc"[1:4] }'
+tryerr 'invalid operation: 3-index slice of string' 'func main() { s := "abc"; t := s[0:1:2] }'
+tryerr 'cannot assign to value of type byte (neither addressable nor a map index expression)' 'func main() { s := "abc"; s[0] = 1 }'
+tryerr 'invalid operation: mismatched types untyped int and untyped string' 'func main() { x := 1 + "a" }'
+tryerr 'invalid operation: mismatched types string and untyped int' 'func main() { s := "a"; t := s + 1 }'
+tryerr 'invalid operation: operator - not defined on string' 'func main() { s := "a"; t := s - s }'
+
+try 8 'import "os"; func main() { b := []byte("hello"); b[0] = 106; s := string(b); b[1] = 0; os.Exit(len(s) + (s == "jello") * 3) }'
+try 7 'import "os"; func main() { s := "héllo"; r := []rune(s); os.Exit(len(s) + len(r) - 4 + (r[1] == 233) - 1 + int(r[4] - 110) - 1) }'
+try 1 'import "os"; func main() { s := "日本語"; r := []rune(s); os.Exit((len(s) == 9) * (len(r) == 3) * (r[2] == 35486)) }'
+try 3 'import "os"; func main() { var r rune; r = 26085; s := string(r); os.Exit(len(s) * (s == "日")) }'
+try 1 'import "os"; func main() { var rs []rune; rs = append(rs, 72, 233, 128512, 97); s := string(rs); os.Exit((s == "Hé😀a") * (len(s) == 8)) }'
+try 4 'import "os"; func main() { os.Exit(len(string(65)) + len(string(-1))) }'
+try 1 'import "os"; func main() { var b []byte; b = append(b, 255, 97); r := []rune(string(b)); os.Exit((r[0] == 65533) * (r[1] == 97) * (len(r) == 2)) }'
+try 5 'import "os"; func main() { var b []byte; b = append(b, "abc"...); b = append(b, "de"...); n := copy(b, "xy"); os.Exit(len(b) + n - int(b[1] - 120) - 1) }'
+try 3 'import "os"; type Bytes []byte; type Str string; func main() { var b Bytes; b = Bytes("abc"); s := Str(b); os.Exit(len(s)) }'
+try 68 'import "os"; func main() { var x int32; x = 1000000; var y int; y = int(x) + 4; var b byte; b = byte(y); os.Exit(int(b)) }'
+try 2 'import "os"; func f(r rune) rune { r++; return r }; func main() { os.Exit(int(f(1))) }'
+tryerr 'cannot convert type []int to type string' 'func main() { var s []int; t := string(s) }'
+
+
+try 3 'import "os"; func main() { m := make(map[int]int); m[1] = 2; m[5] = 1; os.Exit(m[1] + m[5] + m[7]) }'
+try 6 'import "os"; func main() { m := map[string]int{"a": 1, "b": 2, "c": 3}; os.Exit(m["a"] + m["b"] + m["c"] + m["d"]) }'
+try 21 'import "os"; func main() { m := make(map[int]int, 4); for i := 0; i < 100; i++ { m[i] = i * 2 }; os.Exit(len(m) - 100 + m[99] - 177) }'
+try 12 'import "os"; func main() { m := map[int]string{1: "one", 2: "two"}; v, ok := m[2]; w, ok2 := m[3]; os.Exit(len(v) * 4 * ok + len(w) + ok2) }'
+try 2 'import "os"; func main() { m := map[string]int{"x": 1, "y": 2, "z": 3}; delete(m, "y"); delete(m, "w"); _, ok := m["y"]; os.Exit(len(m) + ok) }'
+try 45 'import "os"; func main() { m := make(map[int]int); for i := 0; i < 10; i++ { m[i] = i }; s := 0; for k, v := range m { s = s + k * (k == v) }; os.Exit(s) }'
+try 7 'import "os"; func main() { m := map[string]int{"a": 1, "bb": 2, "ccc": 4}; n := 0; for k := range m { n = n + m[k] }; os.Exit(n) }'
+try 3 'import "os"; func main() { m := map[string]int{"a": 1, "bb": 2, "ccc": 4}; n := 0; for range m { n++ }; os.Exit(n) }'
+try 0 'import "os"; func main() { var m map[string]int; n := 0; for k, v := range m { n = n + v + len(k) }; os.Exit(len(m) + m["a"] + n + (m == nil) - 1) }'
+try 11 'import "os"; type P struct { x, y int }; func main() { m := make(map[P]string); var p P; p.x = 1; p.y = 2; m[p] = "a"; var q P; q.x = 1; q.y = 2; m[q] = m[q] + "bc"; p.y = 3; m[p] = "defgh"; os.Exit(len(m[q]) * 2 + len(m[p]) + len(m) - 2) }'
+try 5 'import "os"; type K struct { name string; n int }; func main() { m := make(map[K]int); var k K; k.name = "go"; k.n = 1; m[k] = 5; var j K; j.name = string([]byte("go")); j.n = 1; os.Exit(m[j]) }'
+try 3 'import "os"; func main() { a := 1; b := 2; m := make(map[*int]int); m[&a] = 1; m[&b] = 2; os.Exit(m[&a] + m[&b]) }'
+try 10 'import "os"; func main() { m := make(map[string][]int); m["a"] = append(m["a"], 1, 2); m["a"] = append(m["a"], 3); m["b"] = append(m["b"], 4); os.Exit(m["a"][0] + m["a"][1] + m["a"][2] + m["b"][0]) }'
+try 5 'import "os"; func main() { m := map[int]int{}; m[1]++; m[1]++; m[2] = m[1] * 2 - len(m) + 1; os.Exit(m[2] + len(m) - 1) }'
+try 1 'import "os"; func main() { m := map[string]int{}; m["a"] = len(m); os.Exit(len(m) - m["a"]) }'
+try 0 'import "os"; func main() { m := make(map[int]int); for i := 0; i < 1000; i++ { m[i] = i; delete(m, i - 1) }; os.Exit(len(m) - 1 + m[998] + m[999] - 999) }'
+try 3 'import "os"; type M map[string]int; func count(m M, k string) { m[k]++ }; func main() { m := M{}; count(m, "a"); count(m, "a"); count(m, "b"); os.Exit(m["a"] + m["b"]) }'
+try 6 'import "os"; func main() { var x any; x = map[int]int{1: 6}; m := x.(map[int]int); os.Exit(m[1]) }'
+try 4 'import "os"; func main() { m := map[byte]map[byte]int{}; m[1] = map[byte]int{2: 4}; os.Exit(m[1][2] + m[2][3]) }'
+trypanic 'assignment to entry in nil map' 'func main() { var m map[int]int; m[1] = 1 }'
+tryerr 'invalid map key type []int' 'func main() { var m map[[]int]int }'
+tryerr 'duplicate key "a" in map literal' 'func main() { m := map[string]int{"a": 1, "a": 2} }'
+tryerr 'cannot use type string as type int in map index' 'func main() { m := map[int]int{}; v := m["a"] }'
+tryerr 'invalid operation: operator == not defined on map[int]int' 'func main() { m := map[int]int{}; n := m; v := m == n }'
+tryerr 'cannot take the address of value' 'func main() { m := map[int]int{}; p := &m[1] }'
+tryerr 'invalid argument: []int is not a map' 'func main() { var s []int; delete(s, 1) }'
+
+
+try 45 'import "os"; func main() { s := 0; for i := range 10 { s = s + i }; os.Exit(s) }'
+try 3 'import "os"; func main() { n := 0; var b byte; b = 3; for range b { n++ }; os.Exit(n) }'
+try 0 'import "os"; func main() { n := 0; for i := range -2 { n = n + 1 + i }; os.Exit(n) }'
+try 38 'import "os"; func main() { var a [4]int; a[0] = 5; a[1] = 6; a[2] = 7; a[3] = 8; s := 0; for i, v := range a { s = s + i * v }; os.Exit(s - 6) }'
+try 6 'import "os"; func main() { var a [3]int; for i := range a { a[i] = i + 1 }; s := 0; for _, v := range a { s = s + v }; os.Exit(s) }'
+try 3 'import "os"; func main() { var a [3]int; a[0] = 1; a[1] = 1; a[2] = 1; s := 0; for i, v := range a { a[2] = 10; s = s + v + i * 0 }; os.Exit(s) }'
+try 30 'import "os"; func main() { var a [3]int; a[0] = 1; a[1] = 1; a[2] = 1; p := &a; s := 0; for _, v := range p { a[2] = 28; s = s + v }; os.Exit(s) }'
+try 10 'import "os"; func main() { var s []int; s = append(s, 1, 2, 3, 4); t := 0; for _, v := range s { t = t + v }; os.Exit(t) }'
+try 4 'import "os"; func main() { var s []int; s = append(s, 1, 2); n := 0; for range s { s = append(s, 0); n++ }; os.Exit(n + len(s) - 2) }'
+try 6 'import "os"; func main() { s := []byte("abc"); for i, b := range s { s[i] = b - 96 }; os.Exit(int(s[0] + s[1] + s[2])) }'
+try 1 'import "os"; func main() { s := "aé日😀"; n := 0; var idx []int; var rs []rune; for i, r := range s { idx = append(idx, i); rs = append(rs, r); n++ }; os.Exit((n == 4) * (idx[1] == 1) * (idx[2] == 3) * (idx[3] == 6) * (rs[2] == 26085) * (rs[3] == 128512)) }'
+try 3 'import "os"; func main() { var b []byte; b = append(b, 97, 255, 98); n := 0; var r rune; for _, c := range string(b) { r = c; n++ }; os.Exit(n * (r == 98)) }'
+try 6 'import "os"; func main() { n := 0; for i := range "héllo" { n = i }; os.Exit(n + 1) }'
+try 3 'import "os"; func main() { var ps []*int; for i := range 3 { ps = append(ps, &i) }; os.Exit(*ps[0] + *ps[1] + *ps[2]) }'
+try 12 'import "os"; type P struct { x int }; func main() { var ps []*P; var a [3]P; a[0].x = 2; a[1].x = 4; a[2].x = 6; for _, p := range a { ps = append(ps, &p) }; os.Exit(ps[0].x + ps[1].x + ps[2].x) }'
+try 5 'import "os"; func main() { var i int; var v rune; for i, v = range "abcde" { }; os.Exit(i + int(v - 100)) }'
+try 8 'import "os"; func main() { var a [2]int; var b []int; b = append(b, 3, 4); n := 0; for i := range a { for j := range b { n = n + i + b[j] - 3 } }; os.Exit(n + 4) }'
+tryerr 'range over int permits only one iteration variable' 'func main() { for i, v := range 10 { } }'
+tryerr 'cannot range over *int' 'func main() { x := 1; for i := range &x { } }'
+tryerr 'range clause permits at most two iteration variables' 'func main() { var s []int; for i, j, k := range s { } }'
+
+try 10 'import "os"; func main() { i := 0; for { i++; if i == 10 { break } }; os.Exit(i) }'
+try 25 'import "os"; func main() { s := 0; for i := 0; i < 10; i++ { if i / 2 * 2 == i { continue }; s = s + i }; os.Exit(s) }'
+try 12 'import "os"; func main() { s := 0; for i := range 10 { if i == 3 { continue }; if i == 6 { break }; s = s + i }; os.Exit(s) }'
+try 6 'import "os"; func main() { n := 0; outer: for i := 0; i < 5; i++ { for j := 0; j < 5; j++ { if j == 2 { continue outer }; if i == 3 { break outer }; n++ } }; os.Exit(n) }'
+try 9 'import "os"; func main() { m := map[int]int{1: 1, 2: 2, 3: 3}; s := 0; for k := range m { if k == 2 { continue }; s = s + k }; os.Exit(s + 5) }'
+try 3 'import "os"; func main() { n := 0; for _, c := range "abcdef" { if c == 100 { break }; n++ }; os.Exit(n) }'
+try 7 'import "os"; func main() { var x any; x = 1; n := 0; for i := 0; i < 3; i++ { switch x.(type) { case int: if i == 1 { break }; n = n + 3 }; n++ }; os.Exit(n - 2) }'
+try 1 'import "os"; func main() { var x any; x = 1; n := 0; L: for { switch x.(type) { case int: n++; break L } }; os.Exit(n) }'
+try 55 'import "os"; func main() { i := 0; s := 0; loop: i++; s = s + i; if i < 10 { goto loop }; os.Exit(s) }'
+try 3 'import "os"; func main() { n := 1; goto end; n = 2; end: os.Exit(n + 2) }'
+try 4 'import "os"; func main() { n := 0; { n = 3; goto out }; n = 9; out: n++; os.Exit(n) }'
+try 2 'import "os"; func main() { n := 0; for i := 0; i < 3; i++ { if i == 2 { goto done }; n++ }; done: os.Exit(n) }'
+tryerr '1:15: label L defined and not used' 'func main() { L: for { } }'
+tryerr '1:26: label L already defined at 1:15' 'func main() { L: goto L; L: return 0 }'
+tryerr '1:15: break is not in a loop, switch, or select' 'func main() { break }'
+tryerr '1:53: continue is not in a loop' 'func main() { var x any; switch x.(type) { default: continue } }'
+try 3 'import "os"; func main() { n := 0; for i := 0; i < 5; i++ { var x any; switch x.(type) { default: if i < 2 { continue } }; n++ }; os.Exit(n) }'
+tryerr '1:30: break label not defined: M' 'func main() { L: for { break M } }'
+tryerr '1:38: invalid break label L' 'func main() { L: goto L; for { break L } }'
+tryerr '1:71: invalid continue label L' 'func main() { var x any; for { L: switch x.(type) { default: continue L } } }'
+try 3 'import "os"; func main() { n := 0; for i := 0; i < 3; i++ { if i < 10 { n++ } else { break } }; os.Exit(n) }'
+try 4 'import "os"; func main() { n := 0; for i := 0; i < 10; i++ { if i < 4 { n++ } else { break } }; os.Exit(n) }'
+try 11 'import "os"; func main() { n := 0; for i := 0; i < 5; i++ { if i == 2 { n = n + 10 } else { continue }; n++ }; os.Exit(n) }'
+try 6 'import "os"; func main() { n := 0; L: for i := 0; i < 3; i++ { for j := 0; j < 3; j++ { if j < 2 { n++ } else { continue L } } }; os.Exit(n) }'
+tryerr '1:20: label M not defined' 'func main() { goto M }'
+tryerr '1:15: goto L jumps into block starting at 1:25' 'func main() { goto L; { L: return } }'
+tryerr '1:15: goto L jumps over declaration of x' 'func main() { goto L; x := 1; L: x++ }'
+
+try 20 'import "os"; func f(x int) int { switch x { case 1: return 10; case 2, 3: return 20; default: return 30 }; return 0 }; func main() { os.Exit(f(3)) }'
+try 30 'import "os"; func f(x int) int { switch x { case 1: return 10; case 2, 3: return 20; default: return 30 }; return 0 }; func main() { os.Exit(f(5)) }'
+try 7 'import "os"; func main() { n := 0; switch { case n > 0: n = 1; case n == 0: n = 7 }; os.Exit(n) }'
+try 5 'import "os"; func main() { switch x := 5; x { case 4: os.Exit(4); case 5: os.Exit(5) } }'
+try 3 'import "os"; func main() { n := 0; switch x := 2; { case x > 1: n = 3; default: n = 4 }; os.Exit(n) }'
+try 4 'import "os"; func main() { n := 0; switch 9 { default: n = 4; case 1: n = 1 }; os.Exit(n) }'
+try 111 'import "os"; func main() { n := 0; switch 1 { case 1: n = n + 1; fallthrough; case 2: n = n + 10; fallthrough; default: n = n + 100 }; os.Exit(n) }'
+try 6 'import "os"; func main() { n := 0; switch 2 { case 1: n = 1; case 2: n = 2; if n == 2 { break }; n = 9 }; os.Exit(n + 4) }'
+try 2 'import "os"; func main() { s := "go"; switch s { case "c": os.Exit(1); case "go": os.Exit(2) }; os.Exit(3) }'
+try 99 'import "os"; func main() { var b byte; b = 200; switch b { case 100: os.Exit(1); case 200: os.Exit(99) } }'
+try 42 'import "os"; func f(x int) int { switch x { case 0: return 40; case 1: return 41; case 2: return 42; case 4: return 44; case 5: return 45 }; return 1 }; func main() { os.Exit(f(2)) }'
+try 1 'import "os"; func f(x int) int { switch x { case 0: return 40; case 1: return 41; case 2: return 42; case 4: return 44; case 5: return 45 }; return 1 }; func main() { os.Exit(f(3) + f(-1) * f(6) - 1) }'
+try 15 'import "os"; func f(x int) int { switch x { case 10, 11: return 1; case 12, 13: fallthrough; case 14: return 2; default: return 3 }; return 0 }; func main() { os.Exit(f(11) + f(13) * 2 + f(20) * 2 + f(14) * 2) }'
+try 5 'import "os"; func g(n *int) int { *n = *n + 1; return *n }; func main() { n := 0; switch g(&n) { case 1, g(&n): n = n + 3 }; os.Exit(n + 1) }'
+tryerr '1:39: duplicate case 1 in expression switch, previous case at 1:31' 'func main() { switch 1 { case 1: case 1: } }'
+tryerr '1:46: fallthrough statement out of place' 'func main() { switch 1 { case 1: if 1 == 1 { fallthrough } ; case 2: } }'
+tryerr '1:34: cannot fallthrough final case in switch' 'func main() { switch 1 { case 1: fallthrough } }'
+tryerr '1:43: multiple defaults in switch, first default at 1:26' 'func main() { switch 1 { default: return; default: return } }'
+tryerr '1:39: cannot use type string as type int in switch case' 'func main() { x := 1; switch x { case "a": } }'
+
+try 3 'import "os"; const N = 3; func main() { os.Exit(N) }'
+try 11 'import "os"; const ( A = iota; B; C; D ); func main() { os.Exit(A + B * 2 + C + D * 2 + 1) }'
+try 22 'import "os"; func main() { const ( a = iota * 10; b; c; _; e ); os.Exit(e / 2 + c / 10) }'
+try 5 'import "os"; const ( X, Y = iota + 3, iota * 2; Z, W ); func main() { os.Exit(X + W + Y) }'
+try 16 'import "os"; var a [N * 2]int; const N = 8; func main() { os.Exit(len(a)) }'
+try 6 'import "os"; func main() { const n = 3; var a [n + n]int; os.Exit(len(a)) }'
+try 2 'import "os"; type Grid [Size][Size]int; const Size = 2; func main() { var g Grid; os.Exit(len(g)) }'
+try 5 'import "os"; const Big = 1000000000000000000000; func main() { os.Exit(Big / 200000000000000000000) }'
+try 3 'import "os"; const Huge = 123456789012345678901234567890 * 10; func main() { os.Exit(Huge / 1234567890123456789012345678900 + 2) }'
+try 1 'import "os"; func main() { x := 10000000000; os.Exit(x / 10000000000) }'
+try 200 'import "os"; const b byte = 200; func main() { var x byte; x = b; os.Exit(int(x)) }'
+try 255 'import "os"; func main() { os.Exit(int(byte(255))) }'
+try 4 'import "os"; const s = "ab" + "cd"; func main() { os.Exit(len(s)) }'
+try 99 'import "os"; const s string = "abc"; func main() { os.Exit(int(s[2])) }'
+try 5 'import "os"; type Weekday int; const ( Sunday Weekday = iota; Monday; Tuesday; Wednesday; Thursday; Friday ); func main() { d := Friday; os.Exit(int(d)) }'
+try 6 'import "os"; func main() { const x = 5; { const x = 6; os.Exit(x) } }'
+try 2 'import "os"; const a = b; const b = 2; func main() { os.Exit(a) }'
+try 1 'import "os"; func main() { n := 0; switch 3 { case -1: n = 2; case 1 + 2: n = 1 }; os.Exit(n) }'
+tryerr 'constant 256 overflows byte' 'func main() { var b byte; b = 256 }'
+tryerr 'constant 300 overflows byte' 'func main() { v := int(byte(300)) }'
+tryerr 'constant 300 overflows byte' 'const b byte = 200; func main() { v := int(b + 100) }'
+tryerr 'constant -1 overflows byte' 'const b byte = 1; const c = b - 2; func main() {}'
+tryerr 'constant 10000000000000000000 overflows int' 'func main() { x := 10000000000000000000 }'
+tryerr 'constant 2147483648 overflows int32' 'func main() { var x int32; x = 2147483648 }'
+tryerr 'invalid operation: division by zero' 'const z = 0; func main() { v := 1 / z }'
+tryerr '1:38: value of type int is not constant' 'func f() int { return 1 }; const x = f(); func main() {}'
+tryerr '1:30: array length must be constant' 'func main() { n := 2; var a [n]int }'
+tryerr '1:22: invalid array length -1' 'func main() { var a [-1]int }'
+tryerr '1:7: invalid recursive constant a' 'const a = b; const b = a; func main() {}'
+tryerr '1:7: missing init expr for const declaration' 'const a, b = 1; func main() {}'
+tryerr '1:14: extra init expr' 'const a = 1, 2; func main() {}'
+tryerr '1:20: cannot use iota outside constant declaration' 'func main() { v := iota }'
+tryerr '1:9: invalid constant type []int' 'const a []int = nil; func main() {}'
+
+try 5 'import "os"; var x = 5; func main() { os.Exit(x) }'
+try 7 'import "os"; var x int = 3 + 4; func main() { os.Exit(x) }'
+try 3 'import "os"; var s = "abc"; func main() { os.Exit(len(s)) }'
+try 98 'import "os"; var s string = "abc"; func main() { os.Exit(int(s[1])) }'
+try 200 'import "os"; var b byte = 200; func main() { os.Exit(int(b)) }'
+try 10 'import "os"; func main() { os.Exit(a) }; var a = b * 2; var b = f(); func f() int { return 5 }'
+try 21 'import "os"; var a = c + b; var b = f(); var c = 1; func f() int { return d * 2 }; var d = 10; func main() { os.Exit(a) }'
+try 6 'import "os"; var x = g(); var n int; func g() int { n = n + 3; return n }; var y = g(); func main() { os.Exit(x + y - 3) }'
+try 3 'import "os"; var p = &v; var v = 3; func main() { os.Exit(*p) }'
+try 4 'import "os"; var s = "ab" + t; var t = string([]byte("cd")); func main() { os.Exit(len(s)) }'
+try 1 'import "os"; var m = map[int]int{1: 1}; func main() { os.Exit(m[1]) }'
+tryerr '1:5: initialization cycle: a refers to b, b refers to a' 'var a = b; var b = a; func main() {}'
+tryerr '1:5: initialization cycle: x refers to f, f refers to x' 'var x = f(); func f() int { return x }; func main() {}'
+tryerr '1:5: initialization cycle: x refers to f, f refers to g, g refers to y, y refers to x' 'var x = f(); func f() int { return g() }; func g() int { return y }; var y = x; func main() {}'
+tryerr '1:16: x redeclared in this block' 'var x int; var x int; func main() {}'
+tryerr 'constant 256 overflows byte' 'var b byte = 256; func main() {}'
+tryerr 'cannot use type string as type int in variable declaration' 'var x int = "a"; func main() {}'
+
+try 0 'var x = 3; func main() { x = x + 1 }'
+try 12 'import "os"; var x = 1; func init() { x = x * 3 }; func main() { os.Exit(x) }; func init() { x = x * 4 }'
+try 21 'import "os"; var n int; func init() { n = n*10 + 1 }; var v = f(); func f() int { n = 2; return 0 }; func main() { os.Exit(n) }'
+try 7 'import "os"; func main() { f(); os.Exit(9) }; func f() { os.Exit(7) }'
+try 3 'import ( "os" ); func f() { return }; func main() { f(); os.Exit(3) }'
+tryerr '1:6: func main must have no arguments and no return values' 'func main() int { return 1 }'
+tryerr '1:6: func init must have no arguments and no return values' 'func init(x int) {}; func main() {}'
+tryerr 'function main is undeclared in the main package' 'func f() {}'
+tryerr '1:22: main redeclared in this block' 'func main() {}; func main() {}'
+tryerr '1:15: undefined: init' 'func main() { init() }; func init() {}'
+tryerr '1:8: "os" imported and not used' 'import "os"; func main() {}'
+tryerr '1:8: package fmt is not in std' 'import "fmt"; func main() {}'
+tryerr '1:12: too many return values' 'func f() { return 1 }; func main() {}'
+tryerr '1:16: not enough return values' 'func f() int { return }; func main() {}'
+
+try 6 'import "os"; func main() { a := [3]int{1, 2, 3}; os.Exit(a[0] + a[1] + a[2]) }'
+try 4 'import "os"; func main() { a := [...]int{5, 6, 7, 8}; os.Exit(len(a)) }'
+try 7 'import "os"; func main() { a := [5]int{2: 7}; os.Exit(a[0] + a[1] + a[2] + a[3] + a[4]) }'
+try 23 'import "os"; func main() { a := [...]byte{4: 1, 2, 1: 3}; os.Exit(len(a) * 3 + int(a[5]) + int(a[1]) - int(a[0])) }'
+try 9 'import "os"; type Point struct { X, Y int }; func main() { p := Point{X: 1}; q := Point{4, 5}; os.Exit(p.X + p.Y + q.X + q.Y - 1) }'
+try 12 'import "os"; type Point struct { X, Y int }; func f() *Point { return &Point{Y: 3} }; func main() { p := f(); q := f(); p.X = 9; os.Exit(p.X + q.Y + q.X) }'
+try 10 'import "os"; func main() { s := []int{1, 2, 3, 4}; os.Exit(s[0] + s[1] + s[2] + s[3] + len(s) - cap(s)) }'
+try 1 'import "os"; func main() { s := []int{}; if s != nil { os.Exit(len(s) + 1) }; os.Exit(9) }'
+try 21 'import "os"; type P struct { x, y int }; func main() { ps := []P{{1, 2}, {3, 4}, {y: 5}}; s := [][]int{{6}, {}}; os.Exit(ps[0].x + ps[0].y + ps[1].x + ps[1].y + ps[2].y + s[0][0] + len(s[1])) }'
+try 7 'import "os"; type P struct { x int }; func main() { ps := []*P{{3}, {4}}; ps[0].x = ps[0].x + ps[1].x; os.Exit(ps[0].x) }'
+try 11 'import "os"; type P struct { x, y int }; func main() { m := map[string]P{"a": {1, 2}}; k := map[P]int{{1, 2}: 8}; os.Exit(m["a"].x + m["a"].y + k[P{1, 2}]) }'
+try 6 'import "os"; func main() { n := 0; for i := 0; i < 3; i++ { a := [2]int{1: i}; n = n + a[0] + a[1] }; os.Exit(n * 2) }'
+try 11 'import "os"; func main() { var ps []*[2]int; for i := range 3 { ps = append(ps, &[2]int{i, i}) }; os.Exit(ps[0][0] + ps[1][1] + ps[2][0] * 2 + len(ps) * 3 - 3) }'
+try 3 'import "os"; func main() { n := 0; for _, v := range []int{1, 2} { n = n + v }; os.Exit(n) }'
+try 5 'import "os"; type S struct { a [2]int; s string }; func main() { x := S{[2]int{1, 2}, "ab"}; os.Exit(x.a[0] + x.a[1] + len(x.s)) }'
+try 8 'import "os"; func main() { p := struct { a, b int }{3, 5}; os.Exit(p.a + p.b) }'
+try 4 'import "os"; type A [2]int; func main() { a := A{1, 3}; os.Exit(a[0] + a[1]) }'
+try 3 'import "os"; type Point struct { X, Y int }; func main() { os.Exit(Point{1, 2}.Y + [2]int{0, 1}[1]) }'
+try 42 'import "os"; type P struct { b byte; n int; s string }; var g = [2]P{{1, 20, "abc"}, {b: 2, s: "hello"}}; func main() { os.Exit(int(g[0].b) + g[0].n + len(g[0].s) + int(g[1].b) + len(g[1].s) + g[1].n + 11) }'
+try 6 'import "os"; var a = [...]int{1, 2, 3}; func main() { a[0] = a[0] + a[1] + a[2]; os.Exit(a[0]) }'
+try 9 'import "os"; var s = []int{2, 3, 4}; func main() { os.Exit(s[0] + s[1] + s[2]) }'
+try 5 'import "os"; var n = f(); var a = [2]int{n, 1}; func f() int { return 4 }; func main() { os.Exit(a[0] + a[1]) }'
+tryerr '1:36: array index 3 out of bounds [0:3]' 'func main() { a := [3]int{1, 2, 3, 4} }'
+tryerr '1:33: duplicate index 1 in array or slice literal' 'func main() { a := [3]int{1: 1, 1: 2} }'
+tryerr '1:26: index must be non-negative integer constant' 'func main() { s := []int{-1: 1} }'
+tryerr 'cannot use type string as type int in array or slice literal' 'func main() { s := []int{"a"} }'
+tryerr '1:47: unknown field z in struct literal of type P' 'type P struct { x int }; func main() { p := P{z: 1} }'
+tryerr '1:53: duplicate field name x in struct literal' 'type P struct { x int }; func main() { p := P{x: 1, x: 2} }'
+tryerr '1:56: mixture of field:value and value elements in struct literal' 'type P struct { x, y int }; func main() { p := P{x: 1, 2} }'
+tryerr 'too few values in struct literal of type P' 'type P struct { x, y int }; func main() { p := P{1} }'
+tryerr '1:50: too many values in struct literal of type P' 'type P struct { x int }; func main() { p := P{1, 2} }'
+tryerr 'cannot use type string as type int in struct literal' 'type P struct { x int }; func main() { p := P{"a"} }'
+
+try 7 'import "os"; func f() *int { x := 7; return &x }; func g() int { y := 100; return y }; func main() { p := f(); g(); os.Exit(*p) }'
+try 3 'import "os"; func f(n int) *int { return &n }; func main() { p := f(1); q := f(2); os.Exit(*p + *q) }'
+try 5 'import "os"; type P struct { x, y int }; func f(p P) *P { p.x++; return &p }; func main() { a := P{1, 2}; b := f(a); os.Exit(a.x + b.x + b.y) }'
+try 3 'import "os"; func main() { var ps []*int; for i := 0; i < 3; i++ { x := i; ps = append(ps, &x) }; os.Exit(*ps[0] + *ps[1] + *ps[2]) }'
+try 15 'import "os"; func main() { var a *int; var b *int; n := 0; for i := 0; i < 2; i++ { x := i + 1; if i == 0 { a = &x } else { b = &x; n++ } }; os.Exit(*a*10 + *b + n*3) }'
+try 5 'import "os"; func main() { n := 0; if 1 == 1 { n = 5 } else { n = 7 }; os.Exit(n) }'
+try 5 'import "os"; var g *int; func set() { x := 5; g = &x }; func clobber() { y := 9; y++ }; func main() { set(); clobber(); os.Exit(*g) }'
+try 42 'import "os"; func main() { p := new(int); *p = 42; os.Exit(*p) }'
+try 3 'import "os"; type P struct { x, y int }; func mk() *P { p := new(P); p.y = 3; return p }; func main() { a := mk(); b := mk(); a.y = 1; os.Exit(a.x + b.y) }'
+try 10 'import "os"; func main() { s := make([]int, 3, 10); os.Exit(len(s) + cap(s) - 3) }'
+try 12 'import "os"; func main() { s := make([]int, 4); for i := range s { s[i] = i }; s = append(s, 6); os.Exit(s[1] + s[2] + s[3] + s[4]) }'
+try 0 'import "os"; func main() { n := 0; s := make([]byte, n); if s == nil { os.Exit(1) }; os.Exit(len(s)) }'
+try 4 'import "os"; func f() []int { var a [4]int; a[3] = 4; return a[:] }; func g() { var b [8]int; b[3] = 9 }; func main() { s := f(); g(); os.Exit(s[3]) }'
+try 11 'import "os"; type S struct { p *int }; func f() S { x := 11; return S{&x} }; func main() { s := f(); os.Exit(*s.p) }'
+try 8 'import "os"; type C struct { n int }; func (c *C) Add(k int) { c.n = c.n + k }; func main() { var c C; c.Add(3); c.Add(5); os.Exit(c.n) }'
+trypanic 'runtime error: makeslice: len out of range' 'func main() { n := -1; s := make([]int, n) }'
+trypanic 'runtime error: makeslice: cap out of range' 'func main() { n := 3; s := make([]int, n, 2) }'
+tryerr 'invalid operation: make([]int) expects 2 or 3 arguments; found 1' 'func main() { s := make([]int) }'
+tryerr 'invalid argument: length and capacity swapped' 'func main() { s := make([]int, 3, 2) }'
+tryerr 'invalid argument: index -1 must not be negative' 'func main() { s := make([]int, -1) }'
+tryerr 'invalid argument: cannot make int; type must be slice, map, or channel' 'func main() { s := make(int) }'
+
+trylimit 0 'func main() { for i := 0; i < 1000; i++ { s := make([]int, 100000); s[i] = i } }'
+trylimit 44 'import "os"; type N struct { v int; next *N }; var head *N; func main() { for i := 0; i < 1000; i++ { head = &N{i, head}; s := make([]byte, 1000000); s[i] = 1 }; sum := 0; for n := head; n != nil; n = n.next { sum = sum + n.v }; os.Exit(sum - sum/256*256) }'
+trylimit 45 'import "os"; type N struct { v int; next *N }; func main() { var head *N; for i := 0; i < 10; i++ { head = &N{i, head}; s := make([]int, 1000000); s[i] = 1 }; sum := 0; for n := head; n != nil; n = n.next { sum = sum + n.v }; os.Exit(sum) }'
+trylimit 99 'import "os"; func main() { m := map[int][]string{}; for i := 0; i < 20; i++ { m[i] = append(m[i], string(rune(65 + i))); b := make([]*int, 1000000); b[i] = &i }; s := ""; for i := 0; i < 20; i++ { s = s + m[i][0] }; os.Exit(int(s[0]) + int(s[19]) - 50) }'
+trylimit 7 'import "os"; type I interface { Get() int }; type T struct { a, b int }; func (t T) Get() int { return t.a + t.b }; func main() { var is []I; for i := 0; i < 100; i++ { is = append(is, T{i, 7}); s := make([]I, 100000); s[0] = T{} }; os.Exit(is[0].Get()) }'
+trylimit 3 'import ("os"; "runtime"); type P struct { x *int }; var ps []P; func main() { for i := 0; i < 4; i++ { x := i; ps = append(ps, P{&x}); runtime.GC() }; os.Exit(*ps[3].x) }'
+try 6 'import ("os"; "runtime"); func main() { m := map[string]*int{}; for i := 0; i < 4; i++ { x := i * 2; m[string(rune(97 + i))] = &x; runtime.GC() }; os.Exit(*m["d"]) }'
+GOGC=off trylimit 2 'func main() { for i := 0; i < 1000; i++ { s := make([]int, 100000); s[i] = i } }'
+GOGC=50 trylimit 0 'func main() { for i := 0; i < 1000; i++ { s := make([]int, 100000); s[i] = i } }'
+tryerr '1:41: undefined: runtime.Collect' 'import "runtime"; func main() { runtime.Collect() }'
+
+try 9 'import "os"; func add(a, b int) int { return a + b }; func apply(f func(int, int) int, x int) int { return f(x, x) }; func main() { f := add; os.Exit(apply(f, 3) + f(1, 2)) }'
+try 32 'import "os"; func counter() func() int { n := 0; return func() int { n++; return n } }; func main() { c := counter(); c(); c(); d := counter(); d(); os.Exit(c() * 10 + d()) }'
+try 21 'import "os"; func main() { x := 1; f := func() { x = x + 10 }; f(); f(); os.Exit(x) }'
+try 5 'import "os"; func main() { var fs []func() int; for i := 0; i < 3; i++ { fs = append(fs, func() int { return i * i }) }; os.Exit(fs[0]() + fs[1]() + fs[2]()) }'
+try 12 'import "os"; func main() { var ps []*int; for i := 0; i < 3; i++ { ps = append(ps, &i) }; os.Exit(*ps[0] * 100 + *ps[1] * 10 + *ps[2]) }'
+try 20 'import "os"; func main() { n := 0; for i := 0; i < 10; i = i + 2 { f := func() { n = n + i }; f() }; os.Exit(n) }'
+try 15 'import "os"; func main() { a := 2; f := func(b int) func() int { return func() int { return a * b } }; g := f(5); a = 3; os.Exit(g()) }'
+try 12 'import "os"; var op func(int) int; func main() { op = func(x int) int { return x * 3 }; if op == nil { os.Exit(1) }; var g func(); if g != nil { os.Exit(2) }; os.Exit(op(4)) }'
+try 9 'import "os"; type T struct { a, b int }; func main() { mk := func(x int) T { return T{x, x + 1} }; t := mk(4); os.Exit(t.a + t.b) }'
+try 42 'import "os"; func main() { os.Exit(func(x int) int { return x + 1 }(41)) }'
+try 7 'import "os"; type Op func(int) int; func twice(f Op) Op { return func(x int) int { return f(f(x)) } }; func main() { inc := func(x int) int { return x + 1 }; os.Exit(twice(twice(inc))(3)) }'
+try 6 'import "os"; var double = func(x int) int { return x * 2 }; var six = double(3); func main() { os.Exit(six) }'
+try 3 'import "os"; func main() { f := main; if f == nil { os.Exit(1) }; m := map[string]func() int{"a": func() int { return 1 }, "b": func() int { return 2 }}; os.Exit(m["a"]() + m["b"]()) }'
+trylimit 50 'import "os"; func adder() func(int) int { sum := 0; return func(x int) int { g := func() { sum = sum + x }; g(); return sum } }; func main() { var fs []func(int) int; for i := 0; i < 100; i++ { fs = append(fs, adder()); s := make([]int, 100000); s[0] = 1 }; t := 0; for i := 0; i < 100; i++ { fs[i](i); t = t + fs[i](1) }; os.Exit(t - 5000) }'
+trylimit 56 'import "os"; type S struct { f func() int }; func main() { var ss []S; for i := 0; i < 50; i++ { k := i; ss = append(ss, S{func() int { return k }}); s := make([]*int, 200000); s[0] = &k }; os.Exit(ss[49].f() + ss[7].f()) }'
+tryerr 'invalid operation: cannot call non-function (type int)' 'func main() { x := 1; x() }'
+tryerr 'invalid operation: operator == not defined on func()' 'func f() {}; func main() { g := f; if f == g {} }'
+tryerr 'wrong number of arguments in call to g' 'func f(a int) {}; func main() { g := f; g() }'
+tryerr 'cannot use type func(int) as type func() in assignment' 'func f(a int) {}; func main() { var g func(); g = f }'
+tryerr '1:33: not enough return values' 'func main() { f := func() int { return }; f() }'
+try 65 'import "os"; var s int; func add(d int) { s = s * 10 + d }; func f() { defer add(1); defer add(2); add(3) }; func main() { f(); os.Exit(s) }'
+try 51 'import "os"; var s int; func add(d int) { s = s * 10 + d }; func f() { x := 1; defer add(x); x = 5; defer add(x) }; func main() { f(); os.Exit(s) }'
+try 65 'import "os"; var s int; func add(d int) { s = s * 10 + d }; func f() { for i := 1; i < 4; i++ { defer add(i); if i == 3 { return } } }; func main() { f(); os.Exit(s) }'
+try 126 'import "os"; var s int; func add(d int) { s = s * 10 + d }; func g(n int) int { defer add(n); if n > 0 { return g(n - 1) + 1 }; return 0 }; func main() { r := g(3); os.Exit(s + r) }'
+try 42 'import "os"; func f() (r int) { defer func() { r = r * 2 }(); return 21 }; func main() { os.Exit(f()) }'
+try 51 'import "os"; func f() (r int) { r = 4; defer func() { r++ }(); return }; func g() int { x := 1; defer func() { x = 50 }(); return x }; func main() { os.Exit(f() * 10 + g()) }'
+try 21 'import "os"; type P struct { x, y int }; func f() (p P) { defer func() { p.y = p.x * 2 }(); p.x = 7; return p }; func main() { q := f(); os.Exit(q.x + q.y) }'
+try 3 'import "os"; type T struct { a, b int }; var s int; func (t T) add() { s = s * 10 + t.a + t.b }; func (t *T) inc() { t.a++ }; func main() { t := T{1, 2}; defer func() { os.Exit(s) }(); defer t.add(); defer t.inc(); t.a = 5; t.b = 1 }'
+try 12 'import "os"; type I interface { F(int) }; type T struct{ n int }; var s int; func (t T) F(x int) { s = s + t.n * x }; func main() { var i I; i = T{3}; func() { defer i.F(4); i = T{100} }(); os.Exit(s) }'
+try 7 'import "os"; var s string; func add(t string) { s = s + t }; func main() { defer func() { os.Exit(len(s)) }(); for i := 0; i < 3; i++ { defer add("ab") }; defer add("c") }'
+try 6 'import "os"; func f() (int) { return 3 }; func g(h func() (r int)) int { return h() }; func main() { os.Exit(f() + g(f)) }'
+trylimit 156 'import "os"; var total int; func add(s []int, k int) { total = total + s[0] + k }; func work(n int) { for i := 0; i < n; i++ { s := make([]int, 1000); s[0] = i; defer add(s, 1) }; for i := 0; i < 100; i++ { t := make([]int, 10000); t[1] = i } }; func main() { for j := 0; j < 20; j++ { work(50) }; os.Exit(total - total / 256 * 256) }'
+tryerr '1:28: expression in defer must be function call' 'import "os"; func main() { defer 1 }'
+tryerr 'multiple return values are not supported' 'func g() (a, b int) { return 1 }; func main() {}'
+trypanic 'boom' 'func main() { panic("boom") }'
+trypanic '42' 'func main() { panic(42) }'
+trypanic 'T(7)' 'type T int; func main() { panic(T(7)) }'
+trypanic 'S("x")' 'type S string; func main() { panic(S("x")) }'
+trypanic 'panic called with nil argument' 'func main() { panic(nil) }'
+trypanic 'runtime error: invalid memory address or nil pointer dereference' 'func main() { var p *int; x := *p; x++ }'
+trypanic 'runtime error: invalid memory address or nil pointer dereference' 'type T struct { a int }; func main() { var p *T; p.a = 1 }'
+trypanic 'runtime error: invalid memory address or nil pointer dereference' 'func main() { var f func(); f() }'
+trypanic 'runtime error: invalid memory address or nil pointer dereference' 'func main() { var p *[3]int; s := p[:]; s[0]++ }'
+try 6 'import "os"; func main() { a := [3]int{1, 2, 3}; p := &a; s := p[1:]; os.Exit(len(s) + s[0] + s[1] - 1) }'
+trypanic 'runtime error: invalid memory address or nil pointer dereference' 'type I interface { M() }; func main() { var i I; i.M() }'
+trypanic 'runtime error: integer divide by zero' 'func main() { a := 1; b := 0; a = a / b }'
+trypanic 'again [recovered]' 'func main() { defer func() { r := recover(); panic(r) }(); panic("again") }'
+trypanic 'first' 'func main() { defer func() { panic("second") }(); panic("first") }'
+try 5 'import "os"; func main() { a := -9223372036854775807 - 1; b := -1; c := a / b; if c == a { os.Exit(5) } }'
+try 7 'import "os"; func f() (r int) { defer func() { if recover() != nil { r = 7 } }(); var s []int; i := 3; return s[i] }; func main() { os.Exit(f()) }'
+try 254 'import "os"; var s int; func f() int { defer func() { s = s + 1 }(); defer func() { recover(); s = s * 10 }(); defer func() { s = 5 }(); panic("x") }; func main() { r := f(); os.Exit(s * 10 + r) }'
+try 121 'import "os"; var log int; func g(d int) { defer func() { log = log * 10 + d }(); if d == 3 { panic("deep") }; g(d + 1) }; func f() (ok int) { defer func() { if r := recover(); r != nil { ok = 1 } }(); g(1); return 2 }; func main() { os.Exit(f() * 100 + log - 300) }'
+try 12 'import "os"; var got int; func handler() { if r := recover(); r != nil { got = r.(int) } }; func f() { defer handler(); panic(12) }; func main() { f(); os.Exit(got) }'
+try 5 'import "os"; func f() int { defer func() { r := recover(); os.Exit(len(r.(string))) }(); panic("hello") }; func main() { f() }'
+try 3 'import "os"; func f() { defer func() { r := recover(); switch r.(type) { case string: os.Exit(1); case int: os.Exit(2) }; os.Exit(3) }(); var m map[int]int; m[1] = 2 }; func main() { f() }'
+try 10 'import "os"; func helper() int { if recover() != nil { return 1 }; return 0 }; var n int; func f() { defer func() { n = helper() }(); panic(1) }; func main() { defer func() { recover(); os.Exit(n + 10) }(); f() }'
+try 4 'import "os"; func main() { if recover() != nil { os.Exit(1) }; defer func() { os.Exit(4) }(); defer func() { func() { if recover() != nil { os.Exit(9) } }() }(); panic(1) }'
+try 6 'import "os"; type P struct { a, b int }; func f() P { defer func() { recover() }(); panic(1) }; func main() { p := f(); os.Exit(p.a + p.b + 6) }'
+try 1 'import "os"; var s int; func f() { defer func() { s = s + 1; recover() }(); defer func() { s = s * 10; panic("second") }(); panic("first") }; func main() { f(); os.Exit(s) }'
+try 5 'import "os"; func f(n int) int { defer func() { recover() }(); return 10 / n }; func main() { os.Exit(f(0) + f(2)) }'
+trylimit 8 'import "os"; func inner(n int) { defer func() { recover() }(); s := make([]int, 10000); s[0] = n; var p *int; if n > 0 { *p = 1 } }; func main() { for i := 0; i < 3000; i++ { inner(i) }; os.Exit(8) }'
+try 12 'import "os"; import "runtime"; var s int; func add(d int) { s = s * 10 + d }; func main() { go add(1); go add(2); runtime.Gosched(); os.Exit(s) }'
+try 50 'import "os"; import "runtime"; var n int; func main() { for i := 0; i < 100; i++ { go func(k int) { n = n + k }(i) }; runtime.Gosched(); os.Exit(n - 4900) }'
+try 232 'import "os"; import "runtime"; var s int; func f(d int) { defer func() { s = s * 10 + d }(); runtime.Gosched(); s = s * 10 + 9 }; func main() { go f(1); go f(2); runtime.Gosched(); runtime.Gosched(); runtime.Gosched(); os.Exit(s) }'
+try 3 'import "os"; var done int; func spin() int { return 1 }; func worker() { for done == 0 { spin() } }; func main() { go worker(); go func() { done = 1 }(); for done == 0 { spin() }; os.Exit(3) }'
+try 4 'import "os"; import "runtime"; var r int; func f() { defer func() { if recover() != nil { r = 4 } }(); var p *int; *p = 1 }; func main() { go f(); runtime.Gosched(); os.Exit(r) }'
+try 0 'func f() {}; func main() { go f() }'
+trypanic 'in goroutine' 'import "runtime"; func main() { go func() { panic("in goroutine") }(); runtime.Gosched() }'
+trylimit 7 'import "os"; import "runtime"; var bad int; var finished int; func worker(id int) { s := make([]int, 1000); s[0] = id; p := &id; for i := 0; i < 50; i++ { t := make([]int, 20000); t[0] = i; runtime.Gosched(); if s[0] + *p != id * 2 { bad++ } }; finished++ }; func main() { for i := 0; i < 20; i++ { go worker(i) }; for finished < 20 { runtime.Gosched() }; os.Exit(bad + 7) }'
+trylimit 9 'import "os"; import "runtime"; var n int; func main() { for i := 0; i < 10000; i++ { go func() { n++ }(); if i - i / 100 * 100 == 0 { for j := 0; j < 100; j++ { runtime.Gosched() } } }; for n < 10000 { runtime.Gosched() }; os.Exit(n - 10000 + 9) }'
+tryerr '1:23: expression in go must be function call' 'func main() { x := 1; go x }'
+try 42 'import "os"; func main() { c := make(chan int); go func() { c <- 42 }(); os.Exit(<-c) }'
+try 33 'import "os"; func main() { c := make(chan int, 3); c <- 1; c <- 2; c <- 3; os.Exit(len(c) * 10 + cap(c) + <-c * 100 - 100) }'
+try 55 'import "os"; func main() { c := make(chan int); go func() { for i := 1; i <= 10; i++ { c <- i }; close(c) }(); s := 0; for v := range c { s = s + v }; os.Exit(s) }'
+try 157 'import "os"; func main() { c := make(chan int, 1); c <- 5; close(c); v, ok := <-c; w, ok2 := <-c; os.Exit(v * 10 + ok * 100 + w + ok2 + 7) }'
+try 12 'import "os"; func main() { out := make(chan int); in := make(chan int); go func() { for v := range in { out <- v * 2 }; close(out) }(); go func() { for i := 0; i < 4; i++ { in <- i }; close(in) }(); s := 0; for v := range out { s = s + v }; os.Exit(s) }'
+try 10 'import "os"; func gen(n int) <-chan int { c := make(chan int); go func() { for i := 0; i < n; i++ { c <- i }; close(c) }(); return c }; func main() { s := 0; for v := range gen(5) { s = s + v }; os.Exit(s) }'
+try 34 'import "os"; type P struct { a, b int }; func main() { c := make(chan P); go func() { c <- P{3, 4} }(); p := <-c; var d chan P; os.Exit(p.a * 10 + p.b + len(d)) }'
+try 6 'import "os"; func main() { c := make(chan *int, 3); for i := 0; i < 3; i++ { x := i * 2; c <- &x }; s := 0; for j := 0; j < 3; j++ { p := <-c; s = s + *p }; os.Exit(s) }'
+try 5 'import "os"; func main() { c := make(chan int); d := c; var e chan int; if c == d { if e == nil { os.Exit(5) } } }'
+try 3 'import "os"; func main() { c := make(chan int); select { case v := <-c: os.Exit(v); default: os.Exit(3) } }'
+try 8 'import "os"; func main() { a := make(chan int); b := make(chan int); go func() { b <- 7 }(); select { case v := <-a: os.Exit(v); case v := <-b: os.Exit(v + 1) } }'
+try 10 'import "os"; func main() { a := make(chan int); done := make(chan int); go func() { for i := 0; i < 5; i++ { a <- i }; close(done) }(); s := 0; for { select { case v := <-a: s = s + v; case <-done: os.Exit(s) } } }'
+try 41 'import "os"; func main() { a := make(chan int, 1); select { case a <- 4: default: os.Exit(1) }; v := 0; ok := 0; select { case v, ok = <-a: }; os.Exit(v * 10 + ok) }'
+try 6 'import "os"; func main() { c := make(chan int); close(c); select { case v, ok := <-c: os.Exit(v + ok + 6) } }'
+try 21 'import "os"; func main() { a := make(chan int); go func() { a <- 1 }(); select { case a <- 4: os.Exit(1); case v := <-a: os.Exit(v + 20) } }'
+try 2 'import "os"; func main() { a := make(chan int); b := make(chan int); go func() { select { case a <- 1: case b <- 2: } }(); os.Exit(<-b) }'
+try 2 'import "os"; func main() { var c chan int; select { case <-c: os.Exit(1); default: os.Exit(2) } }'
+trylimit 9 'import "os"; type Msg struct { id int; data []int }; func producer(out chan *Msg, n int) { for i := 0; i < n; i++ { m := &Msg{id: i, data: make([]int, 5000)}; m.data[4999] = i; out <- m }; close(out) }; func main() { c := make(chan *Msg, 4); res := make(chan int); go producer(c, 2000); go func() { s := 0; for m := range c { if m.data[4999] != m.id { s = s + 1000 }; s = s + 1 }; res <- s }(); os.Exit(<-res - 2000 + 9) }'
+trypanic 'send on closed channel' 'func main() { c := make(chan int); close(c); c <- 1 }'
+trypanic 'send on closed channel' 'func main() { a := make(chan int); go func() { close(a) }(); select { case a <- 1: } }'
+trypanic 'close of nil channel' 'func main() { var c chan int; close(c) }'
+trypanic 'close of closed channel' 'func main() { c := make(chan int); close(c); close(c) }'
+trypanic 'makechan: size out of range' 'func main() { n := -1; c := make(chan int, n); c <- 1 }'
+tryfatal 'all goroutines are asleep - deadlock!' 'func main() { c := make(chan int); <-c }'
+tryfatal 'all goroutines are asleep - deadlock!' 'func main() { c := make(chan int, 1); go func() { c <- 1 }(); c <- 2; c <- 3 }'
+tryfatal 'all goroutines are asleep - deadlock!' 'func main() { select {} }'
+tryfatal 'all goroutines are asleep - deadlock!' 'func main() { var c chan int; go func() { c <- 1 }(); <-c }'
+tryerr 'invalid operation: cannot receive from send-only channel of type chan<- int' 'func main() { c := make(chan<- int); <-c }'
+tryerr 'invalid operation: cannot send to receive-only channel of type <-chan int' 'func main() { c := make(<-chan int); c <- 1 }'
+tryerr 'invalid operation: cannot send to non-channel of type int' 'func main() { x := 1; x <- 1 }'
+tryerr 'invalid argument: int is not a channel' 'func main() { x := 1; close(x) }'
+tryerr 'range over chan int permits only one iteration variable' 'func main() { c := make(chan int); for a, b := range c {} }'
+tryerr '1:50: select case must be receive, send or assign recv' 'func main() { c := make(chan int); select { case c: } }'
+tryerr '1:54: multiple defaults in select, first default at 1:45' 'func main() { c := make(chan int); select { default: default: } }'
+tryerr 'cannot use type chan string as type chan int in assignment' 'func main() { c := make(chan int); var d chan string; c = d }'
+try 100 'import "os"; import "runtime"; import "sync"; type counter struct { mu sync.Mutex; n int }; func (c *counter) inc() { c.mu.Lock(); defer c.mu.Unlock(); n := c.n; runtime.Gosched(); c.n = n + 1 }; func main() { var wg sync.WaitGroup; c := &counter{}; for i := 0; i < 10; i++ { wg.Add(1); go func() { defer wg.Done(); for j := 0; j < 10; j++ { c.inc() } }() }; wg.Wait(); os.Exit(c.n) }'
+try 110 'import "os"; import "sync/atomic"; func main() { var t int64; var s int32; for i := 0; i < 10; i++ { atomic.AddInt64(&t, 3); atomic.AddInt32(&s, -2) }; os.Exit(int(atomic.LoadInt64(&t)) + int(atomic.LoadInt32(&s)) + 100) }'
+try 60 'import "os"; import "runtime"; import "sync"; import "sync/atomic"; func main() { var n int64; var wg sync.WaitGroup; for i := 0; i < 20; i++ { wg.Add(1); go func() { v := atomic.LoadInt64(&n); runtime.Gosched(); for atomic.CompareAndSwapInt64(&n, v, v+3) == 0 { v = atomic.LoadInt64(&n) }; wg.Done() }() }; wg.Wait(); os.Exit(int(n)) }'
+try 111 'import "os"; import "sync/atomic"; func main() { var x int64; x = 5; a := atomic.CompareAndSwapInt64(&x, 5, 7); b := atomic.CompareAndSwapInt64(&x, 5, 9); var y int32; atomic.StoreInt32(&y, 4); os.Exit(a*100 + b*10 + int(x) + int(y)) }'
+try 110 'import "os"; import "sync"; func main() { var mu sync.Mutex; a := mu.TryLock(); b := mu.TryLock(); mu.Unlock(); os.Exit(a*10 + b + mu.TryLock()*100) }'
+try 3 'import "os"; import "sync"; func main() { wg := &sync.WaitGroup{}; c := make(chan int, 3); for i := 0; i < 3; i++ { wg.Add(1); go func(k int) { defer wg.Done(); c <- k }(i) }; wg.Wait(); os.Exit(len(c)) }'
+trypanic 'runtime error: invalid memory address or nil pointer dereference' 'import "sync/atomic"; func main() { var p *int32; atomic.AddInt32(p, 1) }'
+trypanic 'sync: negative WaitGroup counter' 'import "sync"; func main() { var wg sync.WaitGroup; wg.Done() }'
+tryfatal 'sync: unlock of unlocked mutex' 'import "sync"; func main() { var mu sync.Mutex; mu.Unlock() }'
+tryfatal 'all goroutines are asleep - deadlock!' 'import "sync"; func main() { var wg sync.WaitGroup; wg.Add(1); wg.Wait() }'
+tryfatal 'all goroutines are asleep - deadlock!' 'import "sync"; func main() { mu := sync.Mutex{}; mu.Lock(); mu.Lock() }'
+tryerr 'sync.Mutex.state undefined (cannot refer to unexported field state)' 'import "sync"; func main() { var mu sync.Mutex; mu.state = 1 }'
+tryerr '1:41: undefined: sync.Cond' 'import "sync"; func main() { var x sync.Cond; x = x }'
+tryerr 'cannot use type *int as type *int64 in argument to atomic.AddInt64' 'import "sync/atomic"; func main() { n := 0; atomic.AddInt64(&n, 1) }'
+try 76 'import "os"; func Map[T, U any](xs []T, f func(T) U) []U { var ys []U; for _, x := range xs { ys = append(ys, f(x)) }; return ys }; func main() { ys := Map([]int{1, 2, 3}, func(x int) string { return "abc"[:x] }); zs := Map(ys, func(s string) int { return len(s) }); os.Exit(len(ys)*10 + len(ys[1]) + zs[2]*100) }'
+try 241 'import "os"; func Max[T ~int | ~byte](a, b T) T { if a > b { return a }; return b }; type MyInt int; func main() { var m MyInt; m = 7; os.Exit(int(Max(m, 3)) + int(Max[byte](2, 9))*10 + Max(4, 1)*100) }'
+try 16 'import "os"; func Max[T ~int | ~byte](a, b T) T { if a > b { return a }; return b }; func main() { x := Max(1, 2) + Max(5, 3); f := Max[int]; os.Exit(x + f(9, 4)) }'
+try 15 'import "os"; type Number interface { ~int | ~int64 }; func Sum[T Number](xs []T) T { var s T; for _, x := range xs { s = s + x }; return s }; func main() { os.Exit(Sum([]int{1, 2, 3}) + int(Sum([]int64{4, 5}))) }'
+try 13 'import "os"; type Shape interface { Area() int }; type Sq struct { s int }; func (q Sq) Area() int { return q.s * q.s }; func Total[S Shape](xs []S) int { t := 0; for _, x := range xs { t = t + x.Area() }; return t }; func main() { os.Exit(Total([]Sq{Sq{2}, Sq{3}})) }'
+try 215 'import "os"; type Stack[T any] struct { items []T }; func (s *Stack[T]) Push(v T) { s.items = append(s.items, v) }; func (s *Stack[T]) Pop() T { v := s.items[len(s.items)-1]; s.items = s.items[:len(s.items)-1]; return v }; func (s Stack[T]) Len() int { return len(s.items) }; func main() { var s Stack[int]; s.Push(1); s.Push(2); t := &Stack[string]{}; t.Push("hello"); os.Exit(s.Pop()*100 + s.Len()*10 + len(t.Pop())) }'
+try 21 'import "os"; type Set[T comparable] struct { m map[T]int }; func NewSet[T comparable]() *Set[T] { return &Set[T]{m: make(map[T]int)} }; func (s *Set[T]) Add(v T) { s.m[v] = 1 }; func (s *Set[T]) Has(v T) int { return s.m[v] }; func main() { s := NewSet[string](); s.Add("a"); s.Add("b"); s.Add("a"); os.Exit(len(s.m)*10 + s.Has("b") + s.Has("c")) }'
+try 2 'import "os"; type List[T any] struct { next *List[T]; v T }; func (l *List[T]) Len() int { if l == nil { return 0 }; return 1 + l.next.Len() }; func Push[T any](l *List[T], v T) *List[T] { return &List[T]{next: l, v: v} }; func main() { var l *List[string]; l = Push(l, "a"); l = Push(l, "b"); os.Exit(l.Len()) }'
+try 13 'import "os"; type Stack[T any] struct { items []T }; func (s *Stack[T]) Push(v T) { s.items = append(s.items, v) }; func Fill[T any](v T, n int) *Stack[T] { s := &Stack[T]{}; for i := 0; i < n; i++ { s.Push(v) }; return s }; func Wrap[T any](v T) Stack[Stack[T]] { var s Stack[Stack[T]]; s.Push(*Fill(v, 3)); return s }; func main() { s := Wrap("x"); os.Exit(len(s.items)*10 + len(s.items[0].items)) }'
+try 3 'import "os"; type Lener interface { Len() int }; type Box[T any] struct { v []T }; func (b Box[T]) Len() int { return len(b.v) }; func main() { var l Lener; l = Box[byte]{v: []byte("abc")}; os.Exit(l.Len()) }'
+try 123 'import "os"; type Box[T any] struct { v T }; func Kind(x any) int { switch x.(type) { case Box[int]: return 1; case Box[string]: return 2 }; return 3 }; func main() { os.Exit(Kind(Box[int]{1})*100 + Kind(Box[string]{"a"})*10 + Kind(1)) }'
+try 35 'import "os"; func Id[T any](v T) T { return v }; func a() int { type point struct { x int }; return Id(point{3}).x }; func b() int { type point struct { x, y int }; return Id(point{4, 5}).y }; func main() { os.Exit(a()*10 + b()) }'
+try 7 'import "os"; import "runtime"; var n int; func Add[T ~int](v T) { n = n + int(v) }; func main() { defer func() { os.Exit(n) }(); go Add(3); defer Add(4); runtime.Gosched() }'
+try 64 'import "os"; var total = Sum([]int{1, 2, 3}); func Sum[T ~int](xs []T) T { var s T; for _, x := range xs { s = s + x }; return s }; type IntStack = Stack[int]; type Stack[T any] struct { items []T }; func (s *Stack[T]) Push(v T) { s.items = append(s.items, v) }; var stack IntStack; func main() { stack.Push(4); os.Exit(total*10 + stack.items[0]) }'
+try 3 'import "os"; import "runtime"; type Pair[K comparable, V any] struct { k K; v V }; type Cache[K comparable, V any] struct { m map[K]*Pair[K, V] }; func (c *Cache[K, V]) Put(k K, v V) { c.m[k] = &Pair[K, V]{k, v} }; func Filter[T any](xs []T, keep func(T) int) []T { var r []T; for _, x := range xs { if keep(x) == 1 { r = append(r, x) } }; return r }; func main() { c := &Cache[string, []int]{m: make(map[string]*Pair[string, []int])}; for i := 0; i < 2000; i++ { c.Put("k", Filter([]int{i, 1, 2, 3}, func(x int) int { if x > 1 { return 1 }; return 0 })); runtime.GC() }; os.Exit(len(c.m["k"].v)) }'
+tryerr 'string does not satisfy Number (string missing in ~int | ~int64)' 'type Number interface { ~int | ~int64 }; func Sum[T Number](a, b T) T { return a + b }; func main() { Sum("a", "b") }'
+tryerr 'int does not satisfy Stringer (missing method String)' 'type Stringer interface { String() string }; func F[T Stringer](x T) {}; func main() { F(1) }'
+tryerr '[]int does not satisfy comparable' 'func F[T comparable](x T) {}; func main() { F([]int{}) }'
+tryerr 'cannot use type Number outside a type constraint: interface contains type constraints' 'type Number interface { ~int | ~int64 }; func main() { var x Number; x = x }'
+tryerr 'in call to F, cannot infer U' 'func F[T any, U any](x T) U { var u U; return u }; func main() { F(1) }'
+tryerr 'cannot use type string as type int in argument to F' 'func F[T any](x T, y T) {}; func main() { F(1, "a") }'
+tryerr 'cannot use generic function F without instantiation' 'func F[T any](x T) {}; func main() { f := F; f = f }'
+tryerr 'got 2 type arguments but F[T any] has 1 type parameters' 'func F[T any](x T) {}; func main() { F[int, int](1) }'
+tryerr 'cannot use generic type S[T any] without instantiation' 'type S[T any] struct { v T }; func main() { var s S; s = s }'
+tryerr 'not enough type arguments for type S: have 1, want 2' 'type S[T any, U any] struct { v T }; func main() { var s S[int]; s = s }'
+tryerr 'invalid recursive type B[T]' 'type B[T any] struct { b B[T] }; func main() { var b B[int]; b = b }'
+tryerr 'invalid use of ~ (underlying type of MyInt is int)' 'type MyInt int; func F[T ~MyInt](x T) {}; func main() {}'
+tryerr 'syntax error: method must have no type parameters' 'type S[T any] struct { v T }; func (s S[T]) M[U any]() {}; func main() {}'
+tryerr '1:6: func main must have no type parameters' 'func main[T any]() {}'
+tryerr 'F redeclared in this block' 'func F[T any](x T) {}; func F(x int) {}; func main() {}'
+try 21 'import "os"; type Point struct { x, y int }; func (p Point) Sum() int { return p.x + p.y }; func (p *Point) Move(d int) { p.x = p.x + d }; type Named struct { Point; name string }; func main() { var n Named; n.x = 3; n.Point.y = 4; n.Move(10); os.Exit(n.Sum() + n.y) }'
+try 52 'import "os"; type A struct { v int }; type B struct { A; v int }; type C struct { B }; func main() { var c C; c.v = 5; c.A.v = 2; os.Exit(c.v*10 + c.B.A.v) }'
+try 64 'import "os"; type S struct { T; k int }; type T struct { v int }; func (t T) Get() int { return t.v }; func (s S) Get() int { return s.k }; func main() { s := S{T{4}, 6}; os.Exit(s.Get()*10 + s.T.Get()) }'
+try 5 'import "os"; type A struct{}; func (A) X() int { return 1 }; type B struct { A }; type C struct { B; X int }; func main() { var c C; c.X = 4; os.Exit(c.X + c.B.X()) }'
+try 33 'import "os"; type Inner struct { n int }; func (i *Inner) Inc() { i.n++ }; type Outer struct { *Inner; k int }; func main() { o := Outer{&Inner{1}, 2}; o.Inc(); p := &o; p.Inc(); os.Exit(o.n*10 + p.Inner.n) }'
+try 3 'import "os"; type Node struct { *Node; v int }; func main() { n := Node{&Node{nil, 3}, 1}; os.Exit(n.Node.v) }'
+try 243 'import "os"; type Speaker interface { Speak() int }; type Dog struct { legs int }; func (d Dog) Speak() int { return d.legs }; type Loud struct { pad byte; Dog }; type Cat struct { lives int }; func (c *Cat) Speak() int { return c.lives }; type Home struct { x int; Cat }; type Shelter struct { y int; *Cat }; type Cow int; func (c Cow) Speak() int { return int(c) }; type Farm struct { z byte; *Cow }; func main() { var s Speaker; var t Speaker; var u Speaker; var v Speaker; s = Loud{1, Dog{4}}; t = &Home{0, Cat{9}}; u = Shelter{0, &Cat{7}}; c := Cow(2); v = &Farm{1, &c}; os.Exit(s.Speak()*100 + t.Speak()*10 + u.Speak() + v.Speak()) }'
+try 55 'import "os"; type Reader interface { Read() int }; type R struct { n int }; func (r R) Read() int { return r.n }; type Wrap struct { Reader; k int }; type Pair struct { a int; b string }; func (w Wrap) Twice() Pair { return Pair{w.Read() * 2, "x"} }; type Twicer interface { Twice() Pair }; type Outer struct { *Wrap }; func main() { w := Wrap{R{5}, 1}; var r Reader; r = w; var tw Twicer; tw = Outer{&w}; os.Exit(w.Read() + r.Read()*10 + tw.Twice().a*100 - 1000) }'
+try 14 'import "os"; type Speaker interface { Speak() int }; type Cat struct { lives int }; func (c *Cat) Speak() int { if c == nil { return 7 }; return c.lives }; type Shelter struct { *Cat }; type Zoo struct { Shelter }; func main() { var z Zoo; var s Speaker; s = z; os.Exit(z.Speak() + s.Speak()) }'
+try 5 'import "os"; type Speaker interface { Speak() int }; type Cow int; func (c Cow) Speak() int { return int(c) }; type Farm struct { *Cow }; func main() { var u Speaker; u = Farm{}; defer func() { if recover() != nil { os.Exit(5) } }(); u.Speak() }'
+try 2 'import "os"; import "sync"; type Counter struct { sync.Mutex; n int }; func main() { var c Counter; var wg sync.WaitGroup; for i := 0; i < 2; i++ { wg.Add(1); go func() { c.Lock(); c.n++; c.Unlock(); wg.Done() }() }; wg.Wait(); os.Exit(c.n) }'
+try 18 'import "os"; type Box[T any] struct { v T }; func (b Box[T]) Get() T { return b.v }; type S struct { Box[int]; k int }; func main() { s := S{Box[int]{6}, 1}; os.Exit(s.Get() + s.v + s.Box.v) }'
+try 8 'import "os"; type Box[T any] struct { v T }; func (b *Box[T]) Set(v T) { b.v = v }; type W[T any] struct { Box[T]; n int }; func main() { var w W[int]; w.Set(8); os.Exit(w.v) }'
+try 2 'import "os"; const N = 2; type S struct { a [N]int; b [3]int }; func main() { var s S; os.Exit(len(s.a)) }'
+tryerr 'ambiguous selector C.x' 'import "os"; type A struct { x int }; type B struct { x int }; type C struct { A; B }; func main() { var c C; os.Exit(c.x) }'
+tryerr 'ambiguous selector C.X' 'import "os"; type A struct{}; func (A) X() int { return 1 }; type B struct { X int }; type C struct { A; B }; func main() { var c C; os.Exit(c.X) }'
+tryerr 'ambiguous selector D.x' 'type P struct { x int }; type A struct { P }; type B struct { P }; type D struct { A; B }; func main() { var d D; d.x = 1 }'
+tryerr 'cannot use type Home as type Speaker in assignment: Home does not implement Speaker (missing method Speak)' 'type Speaker interface { Speak() int }; type Cat struct{}; func (c *Cat) Speak() int { return 1 }; type Home struct { Cat }; func main() { var s Speaker; s = Home{} }'
+tryerr 'cannot call pointer method Speak on Cat' 'type Cat struct{}; func (c *Cat) Speak() int { return 1 }; type Home struct { Cat }; func main() { Home{}.Speak() }'
+tryerr 'Counter.state undefined (cannot refer to unexported field state)' 'import "sync"; type Counter struct { sync.Mutex }; func main() { var c Counter; c.state = 1 }'
+tryerr '1:68: unknown field x in struct literal of type S' 'type P struct { x int }; type S struct { P }; func main() { s := S{x: 1}; s.x = 2 }'
+tryerr 'duplicate field P' 'type P struct{}; type S struct { P; P int }; func main() {}'
+tryerr 'invalid recursive type T' 'type T struct { T }; func main() {}'
+tryerr 'cannot use type int as type struct{P; *Q; y int} in assignment' 'type P struct{}; type Q struct{}; func main() { var v struct { P; *Q; y int }; v = 1 }'
+tryerr 'embedded field type cannot be a (pointer to a) type parameter' 'type W[T any] struct { *T }; var w W[int]; func main() {}'
+try 31 'import "os"; func main() { b := byte(255); r := int32(2147483647); x := 0; if b + 1 == 0 { x = x + 1 }; if r + 1 < 0 { x = x + 2 }; if b * 2 == 254 { x = x + 4 }; q := int32(-2147483647); if q - 2 > 0 { x = x + 8 }; if b / 3 == 85 { x = x + 16 }; os.Exit(x) }'
+trypanic 'runtime error: index out of range [1000000] with length 1' 'func main() { s := []int{1}; i := 1000000; s[i] = 1 }'
+trypanic 'runtime error: index out of range [-1] with length 1' 'func main() { s := []int{1}; i := -1; s[i] = 1 }'
+trypanic 'runtime error: index out of range [5000000000] with length 1' 'func main() { s := []int{1}; i := 5000000000; s[i] = 1 }'
+trypanic 'runtime error: slice bounds out of range [:5000000000] with capacity 1' 'func main() { s := []int{1}; i := 5000000000; s = s[:i] }'
+try 5 'import "os"; type Tree struct { v int; kids []Tree }; type L []L; func main() { t := Tree{1, []Tree{{2, nil}, {3, nil}}}; var l L; l = append(l, L{}, nil); os.Exit(t.kids[1].v + len(l)) }'
+tryerr 'invalid recursive type T' 'type T [2]T; func main() {}'
+try 8 'import "os"; type A struct { f func(A) int; n int }; func main() { a := A{func(a A) int { return a.n * 2 }, 4}; os.Exit(a.f(a)) }'
+try 7 'import "os"; type Node interface { Clone() Node; Val() int }; type Leaf int; func (l Leaf) Clone() Node { return l + 1 }; func (l Leaf) Val() int { return int(l) }; func main() { var n Node; n = Leaf(5); os.Exit(n.Clone().Clone().Val()) }'
+try 127 'import "os"; type P struct { x int; s string }; type E string; func main() { var a any; var b any; x := 0; a = 3; b = 3; if a == b { x = x + 1 }; b = int32(3); if a != b { x = x + 2 }; a = P{1, "h" + "i"}; b = P{1, "hi"}; if a == b { x = x + 4 }; if a == 3 { x = x + 100 }; var p *int; a = p; b = p; if a == b { x = x + 8 }; a = nil; if a != b { x = x + 16 }; b = nil; if a == b { x = x + 32 }; a = E("x"); if a == E("x") { x = x + 64 }; os.Exit(x) }'
+try 3 'import "os"; type I interface { M() int }; type T int; func (t T) M() int { return int(t) }; func main() { var i I; i = T(2); var j I; j = T(2); x := 0; if i == j { x = 1 }; switch i { case T(2): x = x + 2 }; os.Exit(x) }'
+try 3 'import "os"; func main() { defer func() { r := recover(); var s any; s = r; if r == s { os.Exit(3) } }(); var a []int; i := 5; a[i] = 1 }'
+trypanic 'runtime error: comparing uncomparable type []int' 'func main() { var a any; var b any; a = []int{1}; b = []int{1}; if a == b { } }'
+tryerr 'invalid operation: operator == not defined on []int' 'func main() { var a any; if a == []int{1} { } }'
+try 6 'import "os"; func getenv(x int) int { os.Exit(77); return x }; func free(p int) int { return p + 1 }; var calloc = 5; func main() { os.Exit(free(calloc)) }'
+try 4 'import "os"; type runtime struct{}; func (runtime) GC() int { return 4 }; func main() { var r runtime; os.Exit(r.GC()) }'
+tryerr 'undefined: foo' 'func main() { foo() }'
+try 11 'import "os"; func main() { c := make(chan int, 3); m := map[string]int{"a": 1, "b": 2}; func() { defer close(c); defer delete(m, "a"); c <- 1 }(); n := 0; for v := range c { n = n + v }; os.Exit(n*10 + len(m)) }'
+try 4 'import "os"; func main() { defer func() { r := recover(); if r == "boom" { os.Exit(4) } }(); defer panic("boom") }'
+try 5 'import "os"; func main() { defer func() { if recover() != nil { os.Exit(5) } }(); func() { defer recover(); panic(1) }(); os.Exit(1) }'
+try 9 'import "os"; func main() { a := []int{0, 0}; b := []int{7, 8}; func() { defer copy(a, b); b[0] = 9 }(); os.Exit(a[0]) }'
+tryerr '1:30: expression in defer must be function call' 'func main() { s := []int{1}; defer len(s) }'
+try 3 'import "os"; import "sync"; func main() { c := make(chan int); var wg sync.WaitGroup; wg.Add(1); go close(c); go func() { <-c; wg.Done() }(); wg.Wait(); os.Exit(3) }'
+try 7 'import "os"; func main() { a := make(chan int, 1); b := make(chan int, 1); na := 0; nb := 0; for i := 0; i < 200; i++ { a <- 1; b <- 1; select { case <-a: na++; <-b; case <-b: nb++; <-a } }; if na > 50 { if nb > 50 { os.Exit(7) } }; os.Exit(1) }'
+try 10 'import "os"; import "sync"; func f(n int) int { if n == 0 { return 0 }; return f(n-1) + 1 }; func main() { var wg sync.WaitGroup; wg.Add(1); r := 0; go func() { r = f(100000); wg.Done() }(); wg.Wait(); os.Exit(r - 99990) }'
+try 10 'import "os"; func f(n int) int { if n == 0 { return 0 }; return f(n-1) + 1 }; func main() { os.Exit(f(1000000) - 999990) }'
+try 14 'import "os"; func g(n int) int { if n == 0 { panic("deep") }; return g(n-1) + 1 }; func f() (r int) { defer func() { if recover() != nil { r = 7 } }(); return g(50000) }; func main() { c := make(chan int); go func() { c <- f() + f() }(); os.Exit(<-c) }'
+try 5 'import "os"; func r() (v int) { defer func() { if recover() != nil { v = 1 } }(); panic(2) }; func d(n int) int { if n == 0 { return r() }; return d(n-1) }; func main() { c := make(chan int); go func() { s := 0; for i := 0; i < 8000; i++ { s = s + d(i) }; c <- s }(); os.Exit(<-c - 8000 + 5) }'
+try 10 'import "os"; import "runtime"; type N struct { v int; next *N }; func build(n int, l *N) int { if n == 0 { runtime.GC(); s := 0; for p := l; p != nil; p = p.next { s = s + p.v }; return s }; x := make([]int, 10); x[0] = 1; return build(n-1, &N{x[0], l}) }; func main() { c := make(chan int); go func() { c <- build(20000, nil) }(); os.Exit(<-c - 19990) }'
+try 30 'import "os"; func f(n int) int { var a [40000]int; a[n] = n; if n == 0 { return 0 }; return f(n-1) + a[n] - n + 1 }; func main() { c := make(chan int); go func() { c <- f(30) }(); os.Exit(<-c) }'
+tryfatal 'stack overflow' 'import "sync"; func f(n int) int { return f(n+1) + 1 }; func main() { var wg sync.WaitGroup; wg.Add(1); go func() { f(0); wg.Done() }(); wg.Wait() }'
+try 17 'import "os"; var a byte = 1; var b int32 = 2; var c int = 3; var d byte = 4; var e string = "xy"; var f int = 5; func main() { os.Exit(int(a) + int(b) + c + int(d) + len(e) + f) }'
+tryerr 'invalid operation: operator + not defined on T' 'func Add[T any](a, b T) T { return a + b }; func main() {}'
+tryerr 'invalid operation: operator < not defined on T' 'func Less[T any](a, b T) int { if a < b { return 1 }; return 0 }; func main() {}'
+tryerr 'invalid argument: T for built-in len' 'func Len[T any](a T) int { return len(a) }; func main() {}'
+tryerr 'undefined: undefinedThing' 'func Bad[T any](a T) { undefinedThing(a) }; func main() {}'
+tryerr 'invalid operation: operator + not defined on T' 'type S[T any] struct { v T }; func (s S[T]) Bad() T { return s.v + s.v }; func main() {}'
+tryerr 'floating-point type float64 is not supported' 'type Num interface { ~int | ~float64 }; func main() {}'
+try 27 'import "os"; type Int interface { ~int | ~byte }; type MyInt int; func Sum[T Int](s []T) T { var t T; for _, v := range s { t = t + v }; return t }; func Max[T Int](a, b T) T { if a < b { return b }; return a }; func Idx[T comparable](s []T, x T) int { for i, v := range s { if v == x { return i } }; return -1 }; type Str interface { String() int }; type K int; func (k K) String() int { return int(k) * 2 }; func Call[T Str](x T) int { return x.String() }; type Box[T any] struct { v T }; func (b Box[T]) Get() T { return b.v }; func Unbox[T any](b Box[T]) T { return b.Get() }; func main() { os.Exit(int(Sum([]MyInt{1, 2, 3})) + Max(4, 9) + Idx([]string{"a", "b"}, "b") + Call(K(3)) + Unbox(Box[int]{5})) }'
+try 31 'import "os"; type P struct { x int; s string }; func Eq[T comparable](a, b T) int { if a == b { return 1 }; return 0 }; func Idx[T comparable](s []T, x T) int { for i, v := range s { if v == x { return i } }; return -1 }; func main() { var a any; a = 2; os.Exit(Eq(P{1, "a"}, P{1, "a"}) + Eq(P{1, "a"}, P{1, "b"})*10 + Idx([]P{P{1, "x"}, P{2, "y"}}, P{2, "y"})*2 + Eq([2]int{1, 2}, [2]int{1, 2})*4 + Eq[any](1, 1)*8 + Idx([]any{1, 2}, a)*16 + Eq[any](1, "1")*32) }'
+try 7 'import "os"; type P struct { x, y int }; func main() { a := [2]P{P{1, 2}, P{3, 4}}; b := a; n := 0; if a == b { n++ }; b[1].y = 5; if a != b { n = n + 2 }; if a[0] == b[0] { n = n + 4 }; os.Exit(n) }'
+trypanic 'runtime error: comparing uncomparable type []int' 'func Eq[T comparable](a, b T) int { if a == b { return 1 }; return 0 }; func main() { Eq[any]([]int{1}, []int{1}) }'
+tryerr 'invalid operation: operator == not defined on struct{s []int}' 'func main() { var a struct { s []int }; b := a; if a == b { } }'
+tryerr 'instantiation cycle: Inf[L[T]]' 'type L[T any] struct { next *L[T] }; func Inf[T any](n int) int { if n == 0 { return 0 }; return Inf[L[T]](n-1) + 1 }; func main() { Inf[int](3) }'
+tryerr 'instantiation cycle: M[[]T]' 'type M[T any] struct { x *M[[]T] }; func main() { var m M[int]; m.x = nil }'
+tryerr 'instantiation cycle: F[T]' 'func F[T any](n int) int { return G[[]T](n) }; func G[T any](n int) int { return F[T](n) }; func main() { F[int](1) }'
+try 3 'import "os"; type A[T any] struct { b *B[T]; v T }; type B[T any] struct { a *A[[]int] }; func F[T any, U any](n int) int { if n == 0 { return 0 }; return F[U, T](n-1) + 1 }; func main() { var a A[int]; a.v = 3 - F[int, []int](2) + 2; os.Exit(a.v) }'
+try 14 'import "os"; var s = []int{4, 5}; func main() { s[0]++; os.Exit(s[0] + s[1] + len(s) + cap(s)) }'
+try 6 'import "os"; type P struct { v int; next *P }; var l = &P{1, &P{2, nil}}; func main() { l.next.v = l.next.v + 3; os.Exit(l.v + l.next.v) }'
+try 18 'import "os"; import "runtime"; type P struct { v int; next *P }; var l = &P{1, &P{2, nil}}; var s = []*P{&P{3, nil}, nil}; func main() { l.next.next = &P{7, nil}; s[1] = &P{8, nil}; for i := 0; i < 100000; i++ { x := make([]int, 100); x[0] = i }; runtime.GC(); x := make([]int, 1000); x[0] = 99; os.Exit(l.next.next.v + s[1].v + s[0].v) }'
+try 3 'import "os"; var e = []int{}; var m = [2][]string{[]string{"ab"}, nil}; func main() { n := 0; if e != nil { n++ }; os.Exit(n + len(m[0][0]) + len(m[1])*5) }'
+echo OK
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -24,11 +25,26 @@ type Token struct {
 	val  int       // The value of TK_NUM
 	kind TokenKind // The kind of the token
 	next *Token    // The next token
+	pos  int       // Offset of the token in the input
 }
 
 // Current token
 var token *Token
 
+// The input being compiled
+var userInput string
+
+// position returns the position of the token in the input as line:column.
+func (t *Token) position() string {
+	before := userInput[:t.pos]
+	return fmt.Sprintf("%d:%d", strings.Count(before, "\n")+1, t.pos-strings.LastIndex(before, "\n"))
+}
+
+// errorf panics with the message prefixed by the position of the token.
+func (t *Token) errorf(format string, a ...interface{}) {
+	panic(t.position() + ": " + fmt.Sprintf(format, a...))
+}
+
 type Var struct {
 	Name    string
 	Type    *Type
@@ -36,10 +52,21 @@ type Var struct {
 
 	// Local variables
 	Offset uint // Offset from RBP
+	OnHeap bool // Allocated on the heap, with the address held at Offset
+
+	// The variable of the enclosing function which a variable captured by
+	// a function literal refers to. Its address is held at Offset.
+	Outer *Var
 
-	// Global variables
+	// String literals
 	Content string
 	Len     int
+
+	// Local constants
+	Const *Node
+
+	// The constant initializer of a global variable
+	Init *Node
 }
 
 type VarList struct {
@@ -50,30 +77,85 @@ type VarList struct {
 var locals *VarList         // Local variables
 var globals map[string]*Var // Global variables
 
+// Scope is a block holding the local names declared in it.
+type Scope struct {
+	Parent *Scope
+	Vars   map[string]*Var
+	Types  map[string]*typedef
+	Decls  []string // Names of the variables in the order of declaration
+	Start  *Token
+	Lit    *funcLit // The function literal the scope is in, or nil
+}
+
+var scope *Scope // The innermost local scope
+
+func enterScope() {
+	scope = &Scope{
+		Parent: scope,
+		Vars:   make(map[string]*Var),
+		Types:  make(map[string]*typedef),
+		Start:  token,
+		Lit:    curLit,
+	}
+}
+
+func leaveScope() {
+	scope = scope.Parent
+}
+
+// findLVar returns the local variable named by the token, searching from
+// the innermost scope outwards.
 func (t *Token) findLVar() *Var {
-	for v := locals; v != nil; v = v.Next {
-		if t.str == v.Var.Name {
-			return v.Var
+	for sc := scope; sc != nil; sc = sc.Parent {
+		if v := sc.Vars[t.str]; v != nil {
+			return v
+		}
+		if sc.Types[t.str] != nil {
+			return nil
 		}
 	}
 	return nil
 }
 
+// findTypedef returns the type named by the token, searching from the
+// innermost scope outwards and then the package scope.
+func (t *Token) findTypedef() *typedef {
+	for sc := scope; sc != nil; sc = sc.Parent {
+		if def := sc.Types[t.str]; def != nil {
+			return def
+		}
+		if sc.Vars[t.str] != nil {
+			return nil
+		}
+	}
+	if globals[t.str] != nil {
+		return nil
+	}
+	return typedefs[t.str]
+}
+
 // atEof returns true if the token is EOF
 func (t *Token) atEof() bool {
 	return t.kind == TK_EOF
 }
 
+// offset returns the offset in the input of the rest of it, rest.
+func offset(rest string) int {
+	return len(userInput) - len(rest)
+}
+
 func startswitch(s1, s2 string) bool {
 	return strings.HasPrefix(s1, s2)
 }
 
-// newToken creates a new token and joins it.
-func (t *Token) newToken(kind TokenKind, str string, len int) *Token {
+// newToken creates a new token and joins it. The token begins the rest of
+// the input, rest.
+func (t *Token) newToken(kind TokenKind, str string, len int, rest string) *Token {
 	tok := &Token{
 		kind: kind,
 		str:  str,
 		len:  len,
+		pos:  offset(rest),
 	}
 	t.next = tok
 	return tok
@@ -160,11 +242,12 @@ func getEscapeCharactor(c byte) byte {
 
 func readStringLiteral(cur *Token, str string) (*Token, string) {
 	var content []byte
+	start := str
 	str = str[1:] // read the first double quotation
 	for i := 0; len(str) > i; i++ {
 		s := str[i]
 		if s == '"' {
-			cur = cur.newToken(TK_STR, string(content), len(content))
+			cur = cur.newToken(TK_STR, string(content), len(content), start)
 			str = str[i+1:]
 			return cur, str
 		}
@@ -181,6 +264,7 @@ func readStringLiteral(cur *Token, str string) (*Token, string) {
 
 // tokenize tokenizes a string and returns it
 func tokenize(str string) error {
+	userInput = str
 	var head Token
 	cur := &head
 
@@ -198,17 +282,22 @@ func tokenize(str string) error {
 		}
 
 		// Multi-letter punctuator
+		if startswitch(str, "...") {
+			cur = cur.newToken(TK_RESERVED, str[:3], 3, str)
+			str = str[len(cur.str):]
+			continue
+		}
 		if startswitch(str, "==") || startswitch(str, "!=") ||
 			startswitch(str, "<=") || startswitch(str, ">=") ||
 			startswitch(str, "++") || startswitch(str, "--") ||
-			startswitch(str, ":=") {
-			cur = cur.newToken(TK_RESERVED, str[:2], 2)
+			startswitch(str, ":=") || startswitch(str, "<-") {
+			cur = cur.newToken(TK_RESERVED, str[:2], 2, str)
 			str = str[len(cur.str):]
 			continue
 		}
 
-		if strings.Contains("+-*/()<>;={},&[]", str[0:1]) {
-			cur = cur.newToken(TK_RESERVED, str[:1], 1)
+		if strings.Contains("+-*/()<>;={},&[].:~|", str[0:1]) {
+			cur = cur.newToken(TK_RESERVED, str[:1], 1, str)
 			str = next(str)
 			continue
 		}
@@ -225,7 +314,7 @@ func tokenize(str string) error {
 
 		if k := startWithReserved(str); k != "" {
 			len := len(k)
-			cur = cur.newToken(TK_RESERVED, str[:len], len)
+			cur = cur.newToken(TK_RESERVED, str[:len], len, str)
 			str = str[len:]
 			continue
 		}
@@ -239,7 +328,7 @@ func tokenize(str string) error {
 		return fmt.Errorf("Couldn't tokenize. '%s'", str[:1])
 	}
 
-	cur.newToken(TK_EOF, str, len(str))
+	cur.newToken(TK_EOF, str, len(str), str)
 	token = head.next
 	return nil
 }
@@ -253,7 +342,10 @@ func isSpace(s byte) bool {
 }
 
 var keywords = []string{
-	"return", "if", "else", "for", "func", "var", "int", "byte",
+	"return", "if", "else", "for", "func", "var", "type", "struct", "interface",
+	"nil", "int", "byte", "any", "string", "int32", "int64", "rune", "switch", "case",
+	"default", "map", "range", "break", "continue", "goto",
+	"fallthrough", "const", "import", "defer", "go", "chan", "select",
 }
 
 func startWithReserved(str string) string {
@@ -270,25 +362,27 @@ func isDigit(s byte) bool {
 	return '0' <= s && s <= '9'
 }
 
+// readDigit reads an integer literal. The value of a literal too large for
+// an int is evaluated by the parser.
 func (t *Token) readDigit(str string) (*Token, error) {
 	for i := 0; i < len(str); i++ {
 		if isDigit(str[i]) {
 			continue
 		}
 		dig, err := strconv.Atoi(str[:i])
-		if err != nil {
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
 			return nil, err
 		}
-		tok := t.newToken(TK_NUM, str[:i], i)
+		tok := t.newToken(TK_NUM, str[:i], i, str)
 		tok.val = dig
 		return tok, nil
 	}
 
 	dig, err := strconv.Atoi(str)
-	if err != nil {
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
 		return nil, err
 	}
-	tok := t.newToken(TK_NUM, str, len(str))
+	tok := t.newToken(TK_NUM, str, len(str), str)
 	tok.val = dig
 	return tok, nil
 }
@@ -299,13 +393,13 @@ func isIdent(s byte) bool {
 
 func (t *Token) readIdent(str string) *Token {
 	for i := 0; i < len(str); i++ {
-		if isIdent(str[i]) {
+		if isIdent(str[i]) || i > 0 && isDigit(str[i]) {
 			continue
 		}
-		return t.newToken(TK_IDENT, str[:i], i)
+		return t.newToken(TK_IDENT, str[:i], i, str)
 	}
 
-	return t.newToken(TK_IDENT, str, len(str))
+	return t.newToken(TK_IDENT, str, len(str), str)
 }
 
 func (t *Token) isReserved() bool {
@@ -6,7 +6,10 @@ import (
 	"testing"
 )
 
-var tokenEof = &Token{kind: TK_EOF}
+// eof returns the EOF token of an input of length pos.
+func eof(pos int) *Token {
+	return &Token{kind: TK_EOF, pos: pos}
+}
 
 func TestTokenize(t *testing.T) {
 	testCases := []struct {
@@ -15,162 +18,162 @@ func TestTokenize(t *testing.T) {
 	}{
 		{
 			" 1 ",
-			[]*Token{{"1", 1, 1, TK_NUM, tokenEof}},
+			[]*Token{{"1", 1, 1, TK_NUM, eof(3), 1}},
 		},
 		{
 			"0 + 45 - 5 ",
 			[]*Token{
-				{"0", 1, 0, TK_NUM, nil},
-				{"+", 1, 0, TK_RESERVED, nil},
-				{"45", 2, 45, TK_NUM, nil},
-				{"-", 1, 0, TK_RESERVED, nil},
-				{"5", 1, 5, TK_NUM, nil},
-				tokenEof,
+				{"0", 1, 0, TK_NUM, nil, 0},
+				{"+", 1, 0, TK_RESERVED, nil, 2},
+				{"45", 2, 45, TK_NUM, nil, 4},
+				{"-", 1, 0, TK_RESERVED, nil, 7},
+				{"5", 1, 5, TK_NUM, nil, 9},
+				eof(11),
 			},
 		},
 		{
 			"5*(9-6)",
 			[]*Token{
-				{"5", 1, 5, TK_NUM, nil},
-				{"*", 1, 0, TK_RESERVED, nil},
-				{"(", 1, 0, TK_RESERVED, nil},
-				{"9", 1, 9, TK_NUM, nil},
-				{"-", 1, 0, TK_RESERVED, nil},
-				{"6", 1, 6, TK_NUM, nil},
-				{")", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"5", 1, 5, TK_NUM, nil, 0},
+				{"*", 1, 0, TK_RESERVED, nil, 1},
+				{"(", 1, 0, TK_RESERVED, nil, 2},
+				{"9", 1, 9, TK_NUM, nil, 3},
+				{"-", 1, 0, TK_RESERVED, nil, 4},
+				{"6", 1, 6, TK_NUM, nil, 5},
+				{")", 1, 0, TK_RESERVED, nil, 6},
+				eof(7),
 			},
 		},
 		{
 			"42!=42",
 			[]*Token{
-				{"42", 2, 42, TK_NUM, nil},
-				{"!=", 2, 0, TK_RESERVED, nil},
-				{"42", 2, 42, TK_NUM, nil},
-				tokenEof,
+				{"42", 2, 42, TK_NUM, nil, 0},
+				{"!=", 2, 0, TK_RESERVED, nil, 2},
+				{"42", 2, 42, TK_NUM, nil, 4},
+				eof(6),
 			},
 		},
 		{
 			"abc=1;abc",
 			[]*Token{
-				{"abc", 3, 0, TK_IDENT, nil},
-				{"=", 1, 0, TK_RESERVED, nil},
-				{"1", 1, 1, TK_NUM, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				{"abc", 3, 0, TK_IDENT, nil},
-				tokenEof,
+				{"abc", 3, 0, TK_IDENT, nil, 0},
+				{"=", 1, 0, TK_RESERVED, nil, 3},
+				{"1", 1, 1, TK_NUM, nil, 4},
+				{";", 1, 0, TK_RESERVED, nil, 5},
+				{"abc", 3, 0, TK_IDENT, nil, 6},
+				eof(9),
 			},
 		},
 		{
 			"return 5;",
 			[]*Token{
-				{"return", 6, 0, TK_RESERVED, nil},
-				{"5", 1, 5, TK_NUM, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"return", 6, 0, TK_RESERVED, nil, 0},
+				{"5", 1, 5, TK_NUM, nil, 7},
+				{";", 1, 0, TK_RESERVED, nil, 8},
+				eof(9),
 			},
 		},
 		{
 			"returned;",
 			[]*Token{
-				{"returned", 8, 0, TK_IDENT, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"returned", 8, 0, TK_IDENT, nil, 0},
+				{";", 1, 0, TK_RESERVED, nil, 8},
+				eof(9),
 			},
 		},
 		{
 			"if a := 0; a==1 { return a } else { return 0 }",
 			[]*Token{
-				{"if", 2, 0, TK_RESERVED, nil},
-				{"a", 1, 0, TK_IDENT, nil},
-				{":=", 2, 0, TK_RESERVED, nil},
-				{"0", 1, 0, TK_NUM, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				{"a", 1, 0, TK_IDENT, nil},
-				{"==", 2, 0, TK_RESERVED, nil},
-				{"1", 1, 1, TK_NUM, nil},
-				{"{", 1, 0, TK_RESERVED, nil},
-				{"return", 6, 0, TK_RESERVED, nil},
-				{"a", 1, 0, TK_IDENT, nil},
-				{"}", 1, 0, TK_RESERVED, nil},
-				{"else", 4, 0, TK_RESERVED, nil},
-				{"{", 1, 0, TK_RESERVED, nil},
-				{"return", 6, 0, TK_RESERVED, nil},
-				{"0", 1, 0, TK_NUM, nil},
-				{"}", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"if", 2, 0, TK_RESERVED, nil, 0},
+				{"a", 1, 0, TK_IDENT, nil, 3},
+				{":=", 2, 0, TK_RESERVED, nil, 5},
+				{"0", 1, 0, TK_NUM, nil, 8},
+				{";", 1, 0, TK_RESERVED, nil, 9},
+				{"a", 1, 0, TK_IDENT, nil, 11},
+				{"==", 2, 0, TK_RESERVED, nil, 12},
+				{"1", 1, 1, TK_NUM, nil, 14},
+				{"{", 1, 0, TK_RESERVED, nil, 16},
+				{"return", 6, 0, TK_RESERVED, nil, 18},
+				{"a", 1, 0, TK_IDENT, nil, 25},
+				{"}", 1, 0, TK_RESERVED, nil, 27},
+				{"else", 4, 0, TK_RESERVED, nil, 29},
+				{"{", 1, 0, TK_RESERVED, nil, 34},
+				{"return", 6, 0, TK_RESERVED, nil, 36},
+				{"0", 1, 0, TK_NUM, nil, 43},
+				{"}", 1, 0, TK_RESERVED, nil, 45},
+				eof(46),
 			},
 		},
 		{
 			"for i = 1; i < 10; i++ { 1 }",
 			[]*Token{
-				{"for", 3, 0, TK_RESERVED, nil},
-				{"i", 1, 0, TK_IDENT, nil},
-				{"=", 1, 0, TK_RESERVED, nil},
-				{"1", 1, 1, TK_NUM, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				{"i", 1, 0, TK_IDENT, nil},
-				{"<", 1, 0, TK_RESERVED, nil},
-				{"10", 2, 10, TK_NUM, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				{"i", 1, 0, TK_IDENT, nil},
-				{"++", 2, 0, TK_RESERVED, nil},
-				{"{", 1, 0, TK_RESERVED, nil},
-				{"1", 1, 1, TK_NUM, nil},
-				{"}", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"for", 3, 0, TK_RESERVED, nil, 0},
+				{"i", 1, 0, TK_IDENT, nil, 4},
+				{"=", 1, 0, TK_RESERVED, nil, 6},
+				{"1", 1, 1, TK_NUM, nil, 8},
+				{";", 1, 0, TK_RESERVED, nil, 9},
+				{"i", 1, 0, TK_IDENT, nil, 11},
+				{"<", 1, 0, TK_RESERVED, nil, 13},
+				{"10", 2, 10, TK_NUM, nil, 15},
+				{";", 1, 0, TK_RESERVED, nil, 17},
+				{"i", 1, 0, TK_IDENT, nil, 19},
+				{"++", 2, 0, TK_RESERVED, nil, 20},
+				{"{", 1, 0, TK_RESERVED, nil, 23},
+				{"1", 1, 1, TK_NUM, nil, 25},
+				{"}", 1, 0, TK_RESERVED, nil, 27},
+				eof(28),
 			},
 		},
 		{
 			"func main() {}",
 			[]*Token{
-				{"func", 4, 0, TK_RESERVED, nil},
-				{"main", 4, 0, TK_IDENT, nil},
-				{"(", 1, 0, TK_RESERVED, nil},
-				{")", 1, 0, TK_RESERVED, nil},
-				{"{", 1, 0, TK_RESERVED, nil},
-				{"}", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"func", 4, 0, TK_RESERVED, nil, 0},
+				{"main", 4, 0, TK_IDENT, nil, 5},
+				{"(", 1, 0, TK_RESERVED, nil, 9},
+				{")", 1, 0, TK_RESERVED, nil, 10},
+				{"{", 1, 0, TK_RESERVED, nil, 12},
+				{"}", 1, 0, TK_RESERVED, nil, 13},
+				eof(14),
 			},
 		},
 		{
 			"var i int",
 			[]*Token{
-				{"var", 3, 0, TK_RESERVED, nil},
-				{"i", 1, 0, TK_IDENT, nil},
-				{"int", 3, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"var", 3, 0, TK_RESERVED, nil, 0},
+				{"i", 1, 0, TK_IDENT, nil, 4},
+				{"int", 3, 0, TK_RESERVED, nil, 6},
+				eof(9),
 			},
 		},
 		{
 			"var i [10]int",
 			[]*Token{
-				{"var", 3, 0, TK_RESERVED, nil},
-				{"i", 1, 0, TK_IDENT, nil},
-				{"[", 1, 0, TK_RESERVED, nil},
-				{"10", 2, 10, TK_NUM, nil},
-				{"]", 1, 0, TK_RESERVED, nil},
-				{"int", 3, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"var", 3, 0, TK_RESERVED, nil, 0},
+				{"i", 1, 0, TK_IDENT, nil, 4},
+				{"[", 1, 0, TK_RESERVED, nil, 6},
+				{"10", 2, 10, TK_NUM, nil, 7},
+				{"]", 1, 0, TK_RESERVED, nil, 9},
+				{"int", 3, 0, TK_RESERVED, nil, 10},
+				eof(13),
 			},
 		},
 		{
 			`var str := "string";`,
 			[]*Token{
-				{"var", 3, 0, TK_RESERVED, nil},
-				{"str", 3, 0, TK_IDENT, nil},
-				{":=", 2, 0, TK_RESERVED, nil},
-				{"string", 6, 0, TK_STR, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"var", 3, 0, TK_RESERVED, nil, 0},
+				{"str", 3, 0, TK_IDENT, nil, 4},
+				{":=", 2, 0, TK_RESERVED, nil, 8},
+				{"string", 6, 0, TK_STR, nil, 11},
+				{";", 1, 0, TK_RESERVED, nil, 19},
+				eof(20),
 			},
 		},
 		{
 			`"\a";`,
 			[]*Token{
-				{"\a", 1, 0, TK_STR, nil},
-				{";", 1, 0, TK_RESERVED, nil},
-				tokenEof,
+				{"\a", 1, 0, TK_STR, nil, 0},
+				{";", 1, 0, TK_RESERVED, nil, 4},
+				eof(5),
 			},
 		},
 	}
@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
 
 type TypeKind int
 
@@ -9,18 +13,54 @@ const (
 	TY_INT
 	TY_POINTER
 	TY_ARRAY
+	TY_STRUCT
+	TY_FUNC
+	TY_INTERFACE
+	TY_NIL
+	TY_SLICE
+	TY_STRING
+	TY_INT32
+	TY_MAP
+	TY_CHAN
+	TY_INT64
+	TY_PARAM
 )
 
 var typeKindString = map[TypeKind]string{
-	TY_BYTE:    "byte",
-	TY_INT:     "int",
-	TY_POINTER: "pointer",
-	TY_ARRAY:   "array",
+	TY_BYTE:      "byte",
+	TY_INT:       "int",
+	TY_POINTER:   "pointer",
+	TY_ARRAY:     "array",
+	TY_STRUCT:    "struct",
+	TY_FUNC:      "func",
+	TY_INTERFACE: "interface",
+	TY_NIL:       "nil",
+	TY_SLICE:     "slice",
+	TY_STRING:    "string",
+	TY_INT32:     "int32",
+	TY_MAP:       "map",
+	TY_CHAN:      "chan",
+	TY_INT64:     "int64",
+	TY_PARAM:     "type parameter",
 }
 
 var typeNames = map[string]TypeKind{
-	"int":  TY_INT,
-	"byte": TY_BYTE,
+	"int":    TY_INT,
+	"byte":   TY_BYTE,
+	"any":    TY_INTERFACE,
+	"string": TY_STRING,
+	"int32":  TY_INT32,
+	"int64":  TY_INT64,
+	"rune":   TY_INT32,
+}
+
+// unsupportedTypes are the predeclared types the compiler does not
+// implement, by their names.
+var unsupportedTypes = map[string]string{
+	"float32":    "floating-point",
+	"float64":    "floating-point",
+	"complex64":  "complex",
+	"complex128": "complex",
 }
 
 func (tk TypeKind) String() string {
@@ -29,25 +69,197 @@ func (tk TypeKind) String() string {
 
 type Type struct {
 	Kind     TypeKind
-	Ref      *Type // The pointer's reference
+	Ref      *Type // The pointer's reference, or the element type of an array or a slice
 	ArrayLen uint
+
+	// Struct
+	Fields []*Field
+
+	// Function
+	Params []*Type
+	Result *Type
+
+	// Interface
+	IMethods []*Field // Methods sorted by name, in the order of the itable
+
+	// Constraint interface, whose type set is restricted to the types of
+	// its terms if it has any, and to comparable types if Comparable is set
+	Terms      []*Term
+	Comparable bool
+
+	// Map, whose element type is Ref
+	Key *Type
+
+	// Channel, whose element type is Ref
+	Dir ChanDir
+
+	// Named types. A named type carries a copy of its underlying type's
+	// structure, so that it can be used wherever the underlying type can,
+	// and is only identical to itself.
+	Name    string
+	Methods map[string]*Node // Methods declared with the type as receiver base type
+
+	// Instance of a generic type, with its type arguments, which may
+	// depend on the type parameters of a generic declaration.
+	Generic *generic
+	TArgs   []*Type
+
+	// Type parameter, whose type set and methods are those of its
+	// constraint. The methods are copied to IMethods.
+	Constraint *Type
+}
+
+// Term is a term of the union of a constraint. A term with a tilde stands
+// for all the types whose underlying type is its type.
+type Term struct {
+	Tilde bool
+	Type  *Type
+}
+
+func (t *Term) String() string {
+	if t.Tilde {
+		return "~" + t.Type.String()
+	}
+	return t.Type.String()
+}
+
+// ChanDir is the direction of a channel type, in which values may be sent
+// and received.
+type ChanDir int
+
+const (
+	CHAN_BOTH ChanDir = iota
+	CHAN_SEND
+	CHAN_RECV
+)
+
+// Field is a field of a struct.
+type Field struct {
+	Name     string
+	Type     *Type
+	Offset   uint // Offset from the beginning of the struct
+	Embedded bool // Whether the field is declared with its type only
+}
+
+func alignTo(n, align uint) uint {
+	return (n + align - 1) / align * align
 }
 
 func (t *Type) size() uint {
 	switch t.Kind {
 	case TY_BYTE:
 		return 1
-	case TY_INT, TY_POINTER:
+	case TY_INT32:
+		return 4
+	case TY_INT, TY_INT64, TY_POINTER, TY_FUNC, TY_MAP, TY_CHAN:
+		return 8
+	case TY_PARAM:
+		// A type parameter is laid out as a word in the instances of
+		// generic types type-checked with it, which are never emitted.
 		return 8
+	case TY_INTERFACE, TY_STRING:
+		return 16
+	case TY_SLICE:
+		return 24
 	case TY_ARRAY:
 		return t.Ref.size() * t.ArrayLen
+	case TY_STRUCT:
+		var end uint
+		for _, f := range t.Fields {
+			end = f.Offset + f.Type.size()
+		}
+		return alignTo(end, t.align())
 	default:
 		panic("unknown type")
 	}
 }
 
+func (t *Type) align() uint {
+	switch t.Kind {
+	case TY_ARRAY:
+		return t.Ref.align()
+	case TY_STRUCT:
+		var align uint = 1
+		for _, f := range t.Fields {
+			if a := f.Type.align(); a > align {
+				align = a
+			}
+		}
+		return align
+	case TY_INTERFACE, TY_SLICE, TY_STRING:
+		return 8
+	default:
+		return t.size()
+	}
+}
+
 func (t *Type) String() string {
-	return fmt.Sprintf("%+v", *t)
+	if t.Name != "" {
+		return t.Name
+	}
+	switch t.Kind {
+	case TY_POINTER:
+		return "*" + t.Ref.String()
+	case TY_ARRAY:
+		return fmt.Sprintf("[%d]%s", t.ArrayLen, t.Ref)
+	case TY_SLICE:
+		return "[]" + t.Ref.String()
+	case TY_MAP:
+		return "map[" + t.Key.String() + "]" + t.Ref.String()
+	case TY_CHAN:
+		switch t.Dir {
+		case CHAN_SEND:
+			return "chan<- " + t.Ref.String()
+		case CHAN_RECV:
+			return "<-chan " + t.Ref.String()
+		}
+		return "chan " + t.Ref.String()
+	case TY_STRUCT:
+		var fields []string
+		for _, f := range t.Fields {
+			if f.Embedded {
+				fields = append(fields, f.Type.String())
+				continue
+			}
+			fields = append(fields, f.Name+" "+f.Type.String())
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	case TY_FUNC:
+		var params []string
+		for _, p := range t.Params {
+			params = append(params, p.String())
+		}
+		s := "func(" + strings.Join(params, ", ") + ")"
+		if t.Result != nil {
+			s += " " + t.Result.String()
+		}
+		return s
+	case TY_INTERFACE:
+		if len(t.IMethods) == 0 && !t.Comparable && len(t.Terms) > 0 {
+			// The implicit interface of a union in a type parameter list
+			return termsString(t.Terms)
+		}
+		var elems []string
+		if t.Comparable {
+			elems = append(elems, "comparable")
+		}
+		if len(t.Terms) > 0 {
+			elems = append(elems, termsString(t.Terms))
+		}
+		for _, m := range t.IMethods {
+			elems = append(elems, m.Name+strings.TrimPrefix(m.Type.String(), "func"))
+		}
+		return "interface{" + strings.Join(elems, "; ") + "}"
+	default:
+		return t.Kind.String()
+	}
+}
+
+func pointerTo(ty *Type) *Type {
+	return &Type{
+		Kind: TY_POINTER,
+		Ref:  ty,
+	}
 }
 
 func arrayOf(ty *Type, len uint) *Type {
@@ -58,13 +270,84 @@ func arrayOf(ty *Type, len uint) *Type {
 	}
 }
 
+// sliceOf returns the type of slices of elements of type ty. A slice is a
+// header of the pointer to its underlying array, its length and its capacity.
+func sliceOf(ty *Type) *Type {
+	return &Type{
+		Kind: TY_SLICE,
+		Ref:  ty,
+	}
+}
+
+// mapOf returns the type of maps from keys of type key to elements of type
+// elem. A map is a pointer to a hash table of the runtime.
+func mapOf(key, elem *Type) *Type {
+	return &Type{
+		Kind: TY_MAP,
+		Key:  key,
+		Ref:  elem,
+	}
+}
+
+// chanOf returns the type of channels of elements of type elem in the
+// direction dir. A channel is a pointer to a channel of the runtime.
+func chanOf(elem *Type, dir ChanDir) *Type {
+	return &Type{
+		Kind: TY_CHAN,
+		Ref:  elem,
+		Dir:  dir,
+	}
+}
+
+// structOf returns a struct type with the fields laid out in order.
+func structOf(fields []*Field) *Type {
+	var offset uint
+	for _, f := range fields {
+		offset = alignTo(offset, f.Type.align())
+		f.Offset = offset
+		offset += f.Type.size()
+	}
+	return &Type{
+		Kind:   TY_STRUCT,
+		Fields: fields,
+	}
+}
+
+// namedType turns the placeholder ty into a named type whose underlying
+// type is u. Methods are not inherited from u.
+func namedType(ty *Type, u *Type) {
+	name := ty.Name
+	*ty = *u
+	ty.Name = name
+	ty.Methods = nil
+}
+
 var intType = &Type{Kind: TY_INT}
 var byteType = &Type{Kind: TY_BYTE}
+var nilType = &Type{Kind: TY_NIL}
+var stringType = &Type{Kind: TY_STRING}
+var runeType = &Type{Kind: TY_INT32}
+var anyType = &Type{Kind: TY_INTERFACE}
+var int64Type = &Type{Kind: TY_INT64}
+var comparableType = &Type{Kind: TY_INTERFACE, Name: "comparable", Comparable: true}
 
 func (t *Type) isInt() bool {
 	return t.Kind == TY_INT
 }
 
+func (t *Type) isInteger() bool {
+	return t.Kind == TY_INT || t.Kind == TY_BYTE || t.Kind == TY_INT32 || t.Kind == TY_INT64
+}
+
+func (t *Type) isByte() bool {
+	return t.Kind == TY_BYTE
+}
+
+// isRune reports whether t is int32, of which rune is an alias.
+func (t *Type) isRune() bool {
+	return t.Kind == TY_INT32
+}
+
 func (t *Type) isPointer() bool {
 	return t.Kind == TY_POINTER
 }
@@ -73,6 +356,905 @@ func (t *Type) isArray() bool {
 	return t.Kind == TY_ARRAY
 }
 
+// isString reports whether t is a string type, which is a header of the
+// pointer to its immutable bytes and its length.
+func (t *Type) isString() bool {
+	return t.Kind == TY_STRING
+}
+
+func (t *Type) isSlice() bool {
+	return t.Kind == TY_SLICE
+}
+
+func (t *Type) isMap() bool {
+	return t.Kind == TY_MAP
+}
+
+func (t *Type) isChan() bool {
+	return t.Kind == TY_CHAN
+}
+
+func (t *Type) isFunc() bool {
+	return t.Kind == TY_FUNC
+}
+
+// isHashable reports whether t can be the key type of a map, whose keys are
+// hashed and compared by their bytes and the contents of their strings.
+func (t *Type) isHashable() bool {
+	switch t.Kind {
+	case TY_ARRAY:
+		return t.Ref.isHashable()
+	case TY_STRUCT:
+		for _, f := range t.Fields {
+			if !f.Type.isHashable() {
+				return false
+			}
+		}
+		return true
+	case TY_PARAM:
+		return t.Constraint.Comparable || every(t, (*Type).isHashable)
+	}
+	return t.isInteger() || t.isPointer() || t.isString() || t.isChan()
+}
+
+// every reports whether pred holds for t, or for all the types in the type
+// set of t if it is a type parameter. The type set of a type parameter
+// whose constraint has no terms is not known to hold any type.
+func every(t *Type, pred func(*Type) bool) bool {
+	if t.Kind != TY_PARAM {
+		return pred(t)
+	}
+	terms := t.Constraint.Terms
+	for _, term := range terms {
+		if !pred(term.Type) {
+			return false
+		}
+	}
+	return len(terms) > 0
+}
+
+// coreType returns the underlying type of all the types in the type set of
+// t if it is a type parameter, or nil if they have none in common. Any
+// other type is its own core type.
+func (t *Type) coreType() *Type {
+	if t.Kind != TY_PARAM {
+		return t
+	}
+	var core *Type
+	for _, term := range t.Constraint.Terms {
+		if u := term.Type.underlying(); core == nil {
+			core = u
+		} else if !identical(core, u) {
+			return nil
+		}
+	}
+	return core
+}
+
+// arrayType returns the array type of t, which may be an array or a
+// pointer to an array, or nil.
+func (t *Type) arrayType() *Type {
+	if t.isPointer() {
+		t = t.Ref
+	}
+	if !t.isArray() {
+		return nil
+	}
+	return t
+}
+
+func (t *Type) isStruct() bool {
+	return t.Kind == TY_STRUCT
+}
+
+func (t *Type) isInterface() bool {
+	return t.Kind == TY_INTERFACE
+}
+
+func (t *Type) isNil() bool {
+	return t.Kind == TY_NIL
+}
+
+// isConstraint reports whether t is an interface which can only be used as
+// a type constraint, as its type set is restricted.
+func (t *Type) isConstraint() bool {
+	return t.isInterface() && (len(t.Terms) > 0 || t.Comparable)
+}
+
+// isAggregate reports whether values of t are stored in memory and
+// referred to by their address rather than held in a register.
+func (t *Type) isAggregate() bool {
+	return t.isArray() || t.isStruct() || t.isInterface() || t.isSlice() || t.isString()
+}
+
+func (t *Type) isNamed() bool {
+	return t.Name != ""
+}
+
+// isForeign reports whether t is a type of another package, or a pointer to
+// one, whose name is qualified by the name of the package.
+func (t *Type) isForeign() bool {
+	if t.isPointer() && !t.isNamed() {
+		t = t.Ref
+	}
+	return t.Generic == nil && strings.Contains(t.Name, ".")
+}
+
+// isExported reports whether the name of a field or a method can be
+// referred to from other packages.
+func isExported(name string) bool {
+	return 'A' <= name[0] && name[0] <= 'Z'
+}
+
+// isDefined reports whether t is a named or a predeclared type, as
+// opposed to a type literal such as [2]int.
+func (t *Type) isDefined() bool {
+	return t.isNamed() || t.isInteger() || t.isString()
+}
+
+// underlying returns the type with the name stripped off.
+func (t *Type) underlying() *Type {
+	if !t.isNamed() {
+		return t
+	}
+	u := *t
+	u.Name = ""
+	return &u
+}
+
+// identical reports whether t1 and t2 are the same type. A named type is
+// only identical to itself, while unnamed types are identical if they are
+// structurally equivalent.
+func identical(t1, t2 *Type) bool {
+	if t1 == t2 {
+		return true
+	}
+	if t1.isNamed() || t2.isNamed() || t1.Kind != t2.Kind {
+		return false
+	}
+	switch t1.Kind {
+	case TY_POINTER, TY_SLICE:
+		return identical(t1.Ref, t2.Ref)
+	case TY_ARRAY:
+		return t1.ArrayLen == t2.ArrayLen && identical(t1.Ref, t2.Ref)
+	case TY_MAP:
+		return identical(t1.Key, t2.Key) && identical(t1.Ref, t2.Ref)
+	case TY_CHAN:
+		return t1.Dir == t2.Dir && identical(t1.Ref, t2.Ref)
+	case TY_STRUCT:
+		if len(t1.Fields) != len(t2.Fields) {
+			return false
+		}
+		for i, f := range t1.Fields {
+			if f.Name != t2.Fields[i].Name || f.Embedded != t2.Fields[i].Embedded || !identical(f.Type, t2.Fields[i].Type) {
+				return false
+			}
+		}
+		return true
+	case TY_FUNC:
+		if len(t1.Params) != len(t2.Params) || (t1.Result == nil) != (t2.Result == nil) {
+			return false
+		}
+		for i, p := range t1.Params {
+			if !identical(p, t2.Params[i]) {
+				return false
+			}
+		}
+		return t1.Result == nil || identical(t1.Result, t2.Result)
+	case TY_INTERFACE:
+		if len(t1.IMethods) != len(t2.IMethods) {
+			return false
+		}
+		for i, m := range t1.IMethods {
+			if m.Name != t2.IMethods[i].Name || !identical(m.Type, t2.IMethods[i].Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// findField returns the field of the struct type t, or of the struct t points to.
+func (t *Type) findField(name string) *Field {
+	if t.isPointer() {
+		t = t.Ref
+	}
+	if !t.isStruct() {
+		return nil
+	}
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// findIMethod returns the index of the method in the interface type t, or -1.
+func (t *Type) findIMethod(name string) int {
+	for i, m := range t.IMethods {
+		if m.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// methodType returns the type of the method m without its receiver.
+func methodType(m *Node) *Type {
+	ty := funcTypeOf(m)
+	ty.Params = ty.Params[1:]
+	return ty
+}
+
+// funcTypeOf returns the type of the function fn.
+func funcTypeOf(fn *Node) *Type {
+	ty := &Type{Kind: TY_FUNC, Result: fn.Type}
+	for _, a := range fn.Args {
+		ty.Params = append(ty.Params, a.Type)
+	}
+	return ty
+}
+
+// A selection is the field or the method a selector x.f denotes. It is
+// declared on Owner, which is the type of x itself or is reached from it
+// through the embedded fields of Path.
+type selection struct {
+	Path    []*Field
+	Owner   *Type
+	Field   *Field
+	Method  *Node // A method declared on the named type Owner
+	IMethod int   // The index of the method of the interface type Owner, or -1
+}
+
+// indirect reports whether the path of the selection goes through an
+// embedded pointer, beyond which the promoted receiver is addressable.
+func (sel *selection) indirect() bool {
+	for _, f := range sel.Path {
+		if f.Type.isPointer() {
+			return true
+		}
+	}
+	return false
+}
+
+// methodType returns the type of the selected method without its receiver.
+func (sel *selection) methodType() *Type {
+	if sel.Method != nil {
+		return methodType(sel.Method)
+	}
+	return sel.Owner.IMethods[sel.IMethod].Type
+}
+
+// lookup returns the field or the method of t, or of the struct type t
+// points to, named name. The fields and methods of embedded fields are
+// promoted, with those at a shallower depth of embedding shadowing the
+// deeper ones. lookup reports whether more than one are found at the
+// shallowest depth, in which case the selector is ambiguous.
+func (t *Type) lookup(name string) (sel *selection, ambiguous bool) {
+	// A named pointer type has no methods, nor does the type it points to
+	// lend it any.
+	methods := !t.isPointer() || !t.isNamed()
+	if t.isPointer() {
+		t = t.Ref
+	}
+	type entry struct {
+		Type *Type
+		Path []*Field
+	}
+	level := []entry{{t, nil}}
+	seen := make(map[*Type]bool)
+	for len(level) > 0 {
+		var next []entry
+		for _, e := range level {
+			ty := e.Type
+			if seen[ty] {
+				continue
+			}
+			var found *selection
+			if m := ty.Methods[name]; m != nil && (methods || len(e.Path) > 0) {
+				found = &selection{Path: e.Path, Owner: ty, Method: m, IMethod: -1}
+			} else if i := ty.findIMethod(name); i >= 0 && len(e.Path) > 0 {
+				found = &selection{Path: e.Path, Owner: ty, IMethod: i}
+			}
+			for _, f := range ty.Fields {
+				if f.Name == name {
+					found = &selection{Path: e.Path, Owner: ty, Field: f, IMethod: -1}
+				}
+				if f.Embedded {
+					ft := f.Type
+					if ft.isPointer() {
+						ft = ft.Ref
+					}
+					path := append(e.Path[:len(e.Path):len(e.Path)], f)
+					next = append(next, entry{ft, path})
+				}
+			}
+			if found != nil {
+				if sel != nil {
+					return nil, true
+				}
+				sel = found
+			}
+		}
+		if sel != nil {
+			return sel, false
+		}
+		// A type embedded at a shallower depth shadows itself deeper.
+		for _, e := range level {
+			seen[e.Type] = true
+		}
+		level = next
+	}
+	return nil, false
+}
+
+// methodSet returns the method named name in the method set of t, or nil.
+// The method set of a type T consists of the methods declared with
+// receiver type T, and that of *T also includes those declared with
+// receiver type *T. The methods of embedded fields are promoted, those
+// with a pointer receiver only when the embedded value is addressable
+// through *T or an embedded pointer.
+func (t *Type) methodSet(name string) *selection {
+	sel, _ := t.lookup(name)
+	if sel == nil || sel.Field != nil {
+		return nil
+	}
+	if m := sel.Method; m != nil && m.Args[0].Type.isPointer() && !t.isPointer() && !sel.indirect() {
+		return nil
+	}
+	return sel
+}
+
+// missingMethod returns the name of a method of the interface type iface
+// that t does not implement, or "" if t implements iface.
+func missingMethod(t *Type, iface *Type) string {
+	for _, im := range iface.IMethods {
+		if t.isInterface() || t.Kind == TY_PARAM {
+			if i := t.findIMethod(im.Name); i < 0 || !identical(t.IMethods[i].Type, im.Type) {
+				return im.Name
+			}
+			continue
+		}
+		sel := t.methodSet(im.Name)
+		if sel == nil || !identical(sel.methodType(), im.Type) {
+			return im.Name
+		}
+	}
+	return ""
+}
+
+// termsString returns the union of the terms as written in a constraint.
+func termsString(terms []*Term) string {
+	var s []string
+	for _, t := range terms {
+		s = append(s, t.String())
+	}
+	return strings.Join(s, " | ")
+}
+
+// inTypeSet reports whether t is one of the types the terms stand for.
+func inTypeSet(t *Type, terms []*Term) bool {
+	for _, term := range terms {
+		if identical(t, term.Type) || term.Tilde && identical(t.underlying(), term.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectTerms returns the terms standing for the types that both the
+// terms a and b stand for.
+func intersectTerms(a, b []*Term) []*Term {
+	var terms []*Term
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case !identical(x.Type.underlying(), y.Type.underlying()):
+			case x.Tilde && y.Tilde:
+				terms = append(terms, x)
+			case x.Tilde && inTypeSet(y.Type, a):
+				terms = append(terms, y)
+			case y.Tilde && inTypeSet(x.Type, b), identical(x.Type, y.Type):
+				terms = append(terms, x)
+			}
+		}
+	}
+	return terms
+}
+
+// subsetTerms reports whether the types the terms a stand for are all among
+// those the terms b stand for.
+func subsetTerms(a, b []*Term) bool {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if identical(x.Type, y.Type) && (y.Tilde || !x.Tilde) || y.Tilde && !x.Tilde && identical(x.Type.underlying(), y.Type) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// unsatisfied returns why the type argument t does not satisfy the
+// constraint c, or "" if it does. A type parameter satisfies c if all the
+// types in its type set do.
+func unsatisfied(t *Type, c *Type) string {
+	if t.Kind == TY_PARAM && len(c.Terms) > 0 {
+		if terms := t.Constraint.Terms; len(terms) == 0 || !subsetTerms(terms, c.Terms) {
+			return fmt.Sprintf("%s does not satisfy %s (%s missing in %s)", t, c, t, termsString(c.Terms))
+		}
+	} else if len(c.Terms) > 0 && !inTypeSet(t, c.Terms) {
+		return fmt.Sprintf("%s does not satisfy %s (%s missing in %s)", t, c, t, termsString(c.Terms))
+	}
+	// Interfaces are comparable, although comparing them may panic.
+	if c.Comparable && !t.isHashable() && !t.isInterface() {
+		return fmt.Sprintf("%s does not satisfy comparable", t)
+	}
+	if m := missingMethod(t, c); m != "" {
+		return fmt.Sprintf("%s does not satisfy %s (missing method %s)", t, c, m)
+	}
+	return ""
+}
+
+// containsParam reports whether t depends on a type parameter of a generic
+// signature.
+func containsParam(t *Type) bool {
+	switch {
+	case t == nil:
+		return false
+	case t.Kind == TY_PARAM:
+		return true
+	case t.Generic != nil:
+		return containsParams(t.TArgs)
+	case t.isNamed():
+		return false
+	}
+	if containsParam(t.Ref) || containsParam(t.Key) || containsParam(t.Result) {
+		return true
+	}
+	for _, p := range t.Params {
+		if containsParam(p) {
+			return true
+		}
+	}
+	for _, f := range t.Fields {
+		if containsParam(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// paramsOf appends the type parameters t depends on to params.
+func paramsOf(params []*Type, t *Type) []*Type {
+	switch {
+	case t == nil:
+		return params
+	case t.Kind == TY_PARAM:
+		return append(params, t)
+	case t.Generic != nil:
+		for _, a := range t.TArgs {
+			params = paramsOf(params, a)
+		}
+		return params
+	case t.isNamed():
+		return params
+	}
+	params = paramsOf(paramsOf(paramsOf(params, t.Ref), t.Key), t.Result)
+	for _, p := range t.Params {
+		params = paramsOf(params, p)
+	}
+	for _, f := range t.Fields {
+		params = paramsOf(params, f.Type)
+	}
+	return params
+}
+
+// unify matches the type of a parameter of a generic signature with the
+// type of an argument, binding the type parameters it depends on. It
+// reports whether they match.
+func unify(param, arg *Type, bound map[*Type]*Type) bool {
+	switch {
+	case !containsParam(param):
+		// The argument is checked to be assignable to the parameter.
+		return true
+	case param.Generic != nil:
+		if arg.Generic != param.Generic {
+			return false
+		}
+		for i, p := range param.TArgs {
+			if !unify(p, arg.TArgs[i], bound) {
+				return false
+			}
+		}
+		return true
+	case param.Kind == TY_PARAM:
+		if ty := bound[param]; ty != nil {
+			return identical(ty, arg)
+		}
+		bound[param] = arg
+		return true
+	case param.Kind != arg.Kind:
+		return false
+	}
+	switch param.Kind {
+	case TY_ARRAY:
+		return param.ArrayLen == arg.ArrayLen && unify(param.Ref, arg.Ref, bound)
+	case TY_MAP:
+		return unify(param.Key, arg.Key, bound) && unify(param.Ref, arg.Ref, bound)
+	case TY_FUNC:
+		if len(param.Params) != len(arg.Params) || (param.Result == nil) != (arg.Result == nil) {
+			return false
+		}
+		for i, p := range param.Params {
+			if !unify(p, arg.Params[i], bound) {
+				return false
+			}
+		}
+		return param.Result == nil || unify(param.Result, arg.Result, bound)
+	case TY_STRUCT:
+		if len(param.Fields) != len(arg.Fields) {
+			return false
+		}
+		for i, f := range param.Fields {
+			if f.Name != arg.Fields[i].Name || f.Embedded != arg.Fields[i].Embedded || !unify(f.Type, arg.Fields[i].Type, bound) {
+				return false
+			}
+		}
+		return true
+	}
+	return unify(param.Ref, arg.Ref, bound)
+}
+
+// inferTypeArgs returns the type arguments of the call n of the generic
+// function g, inferring those not given explicitly from the types of the
+// arguments. Untyped constants only determine the type parameters the
+// typed arguments leave unbound, with their default types.
+func inferTypeArgs(g *generic, n *Node) []*Type {
+	if len(n.TypeArgs) > len(g.TParams) {
+		panic(fmt.Sprintf("got %d type arguments but %s has %d type parameters", len(n.TypeArgs), g, len(g.TParams)))
+	}
+	if len(n.TypeArgs) == len(g.TParams) {
+		return n.TypeArgs
+	}
+	if len(n.Args) != len(g.Sig.Args) {
+		panic(fmt.Sprintf("wrong number of arguments in call to %s", g.Name))
+	}
+	bound := make(map[*Type]*Type)
+	for i, ty := range n.TypeArgs {
+		bound[g.Params[i]] = ty
+	}
+	for _, untyped := range []bool{false, true} {
+		for i, a := range n.Args {
+			param := g.Sig.Args[i].Type
+			if isUntyped(a) != untyped || a.Type.isNil() || untyped && (param.Kind != TY_PARAM || bound[param] != nil) {
+				continue
+			}
+			if !unify(param, a.Type, bound) {
+				panic(fmt.Sprintf("in call to %s, type %s of argument %d does not match %s", g.Name, a.Type, i+1, param))
+			}
+		}
+	}
+	targs := make([]*Type, len(g.Params))
+	for i, p := range g.Params {
+		if targs[i] = bound[p]; targs[i] == nil {
+			panic(fmt.Sprintf("in call to %s, cannot infer %s", g.Name, p))
+		}
+	}
+	return targs
+}
+
+// addressable reports whether n denotes a location in memory whose address can be taken.
+func addressable(n *Node) bool {
+	switch n.Kind {
+	case ND_VAR, ND_DEREF:
+		return true
+	case ND_INDEX:
+		// The elements of a slice are always addressable, while the
+		// bytes of a string and the elements of a map never are.
+		switch ty := n.Lhs.Type; {
+		case ty.isSlice(), ty.isPointer():
+			return true
+		case ty.isString(), ty.isMap():
+			return false
+		}
+		return addressable(n.Lhs)
+	case ND_MEMBER:
+		return addressable(n.Lhs)
+	}
+	return false
+}
+
+// isMapIndex reports whether n is a map index expression, which can be
+// assigned to although it is not addressable.
+func isMapIndex(n *Node) bool {
+	return n.Kind == ND_INDEX && n.Lhs.Type.isMap()
+}
+
+// isUntyped reports whether n is an untyped constant, which takes the
+// type of the context it is used in.
+func isUntyped(n *Node) bool {
+	switch n.Kind {
+	case ND_NUM, ND_STR:
+		return !n.Typed
+	case ND_ADD, ND_SUB, ND_MUL, ND_DIV:
+		return isUntyped(n.Lhs) && isUntyped(n.Rhs)
+	}
+	return false
+}
+
+// isConst reports whether n is a constant, to which constant expressions
+// are folded.
+func isConst(n *Node) bool {
+	return n.Kind == ND_NUM || n.Kind == ND_STR
+}
+
+// constOf returns the value of the integer constant n.
+func constOf(n *Node) *big.Int {
+	if n.Const != nil {
+		return n.Const
+	}
+	return big.NewInt(int64(n.Val))
+}
+
+// typedConst returns the constant n with the type ty.
+func typedConst(n *Node, ty *Type) *Node {
+	c := n.clone()
+	c.Type, c.Typed = ty, true
+	if c.Kind == ND_NUM {
+		checkOverflow(c, ty)
+	}
+	return c
+}
+
+// checkOverflow panics unless the value of the integer constant n is
+// representable by the integer type ty.
+func checkOverflow(n *Node, ty *Type) {
+	bits := ty.size() * 8
+	min := new(big.Int).Lsh(big.NewInt(-1), bits-1)
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits-1), big.NewInt(1))
+	if ty.isByte() {
+		min, max = big.NewInt(0), big.NewInt(255)
+	}
+	if v := constOf(n); v.Cmp(min) < 0 || v.Cmp(max) > 0 {
+		panic(fmt.Sprintf("constant %s overflows %s", v, ty))
+	}
+}
+
+// foldConst replaces the arithmetic operation n on constants by its value.
+// The value of a typed constant must be representable by its type.
+func (n *Node) foldConst() {
+	ty, typed := n.Type, n.Lhs.Typed || n.Rhs.Typed
+	if ty.isString() {
+		*n = Node{Kind: ND_STR, Var: stringLiteral(n.Lhs.Var.Content + n.Rhs.Var.Content)}
+		n.Type, n.Typed = ty, typed
+		return
+	}
+	x, y := constOf(n.Lhs), constOf(n.Rhs)
+	v := new(big.Int)
+	switch n.Kind {
+	case ND_ADD:
+		v.Add(x, y)
+	case ND_SUB:
+		v.Sub(x, y)
+	case ND_MUL:
+		v.Mul(x, y)
+	case ND_DIV:
+		v.Quo(x, y)
+	}
+	*n = *newNodeConst(v)
+	n.Type, n.Typed = ty, typed
+	if typed {
+		checkOverflow(n, ty)
+	}
+}
+
+// assignable reports whether the value of n may be assigned to a variable of type ty.
+func assignable(ty *Type, n *Node) bool {
+	if ty.Kind == TY_PARAM && (isUntyped(n) || n.Type.isNil()) {
+		// An untyped constant or nil must be assignable to all the types
+		// in the type set.
+		return every(ty, func(t *Type) bool { return assignable(t, n) })
+	}
+	if isUntyped(n) {
+		// An untyped constant takes its default type in an interface.
+		if ty.isInterface() {
+			return missingMethod(n.Type, ty) == ""
+		}
+		if n.Type.isString() {
+			return ty.isString()
+		}
+		return ty.isInteger()
+	}
+	if n.Type.isNil() {
+		return ty.isPointer() || ty.isInterface() || ty.isSlice() || ty.isMap() || ty.isFunc() || ty.isChan()
+	}
+	if identical(ty, n.Type) {
+		return true
+	}
+	if ty.isInterface() {
+		return missingMethod(n.Type, ty) == ""
+	}
+	// A bidirectional channel may be used as a directional one.
+	if ty.isChan() && n.Type.isChan() && n.Type.Dir == CHAN_BOTH && identical(ty.Ref, n.Type.Ref) {
+		return !ty.isNamed() || !n.Type.isNamed()
+	}
+	// Identical underlying types, and at least one of them is not named.
+	return (!ty.isDefined() || !n.Type.isDefined()) && identical(ty.underlying(), n.Type.underlying())
+}
+
+// convert checks that the value of n can be assigned to a variable of type
+// ty and returns n, converted to ty if it is a concrete value stored into
+// an interface.
+func convert(n *Node, ty *Type, context string) *Node {
+	n.addType()
+	if !assignable(ty, n) {
+		msg := fmt.Sprintf("cannot use type %s as type %s in %s", n.Type, ty, context)
+		if ty.isInterface() && !n.Type.isNil() {
+			if m := missingMethod(n.Type, ty); m != "" {
+				msg += fmt.Sprintf(": %s does not implement %s (missing method %s)", n.Type, ty, m)
+			}
+		}
+		panic(msg)
+	}
+	if n.Kind == ND_NUM && ty.isInteger() {
+		checkOverflow(n, ty)
+	} else if n.Kind == ND_NUM && !n.Typed {
+		// An untyped constant takes its default type in an interface.
+		checkOverflow(n, intType)
+	}
+	if ty.isInterface() && !identical(ty.underlying(), n.Type.underlying()) {
+		return toInterface(n, ty)
+	}
+	if n.Type.isNil() && ty.isAggregate() {
+		// nil stands for the zero value of the aggregate.
+		return &Node{Kind: ND_CONV, Lhs: n, Type: ty, Var: newTemp(ty)}
+	}
+	return n
+}
+
+// toInterface returns the node converting the value of n to the interface
+// type ty. A concrete value is paired with the itable of its type, while
+// the itable of an interface value is looked up at run time.
+func toInterface(n *Node, ty *Type) *Node {
+	node := &Node{Kind: ND_CONV, Lhs: n, Type: ty, Var: newTemp(ty)}
+	if !n.Type.isNil() && !n.Type.isInterface() {
+		node.Itab = itabOf(n.Type, ty)
+	}
+	return node
+}
+
+// Itab is the table of the methods implementing an interface type for a concrete type.
+type Itab struct {
+	Label string
+	Type  *Type
+	Iface *Type
+}
+
+var itabs []*Itab
+
+func itabOf(ty *Type, iface *Type) *Itab {
+	for _, it := range itabs {
+		if identical(it.Type, ty) && identical(it.Iface, iface) {
+			return it
+		}
+	}
+	it := &Itab{
+		Label: fmt.Sprintf(".L.itab.%d", len(itabs)),
+		Type:  ty,
+		Iface: iface,
+	}
+	itabs = append(itabs, it)
+	return it
+}
+
+// TypeDesc is the runtime descriptor of a type, which identifies the
+// dynamic type of an interface value. The first word of an itable points
+// to the descriptor of its type, which holds the name and the size of the
+// type, how a panic prints its values, and how they are compared.
+type TypeDesc struct {
+	Label string
+	Type  *Type
+}
+
+var typeDescs []*TypeDesc
+
+func typeDescOf(ty *Type) *TypeDesc {
+	for _, d := range typeDescs {
+		if identical(d.Type, ty) {
+			return d
+		}
+	}
+	d := &TypeDesc{
+		Label: fmt.Sprintf(".L.type.%d", len(typeDescs)),
+		Type:  ty,
+	}
+	typeDescs = append(typeDescs, d)
+	return d
+}
+
+// The ways a panic prints the values of a type, which are known to the
+// runtime
+const (
+	printOther = iota
+	printInt
+	printNamedInt
+	printString
+	printNamedString
+)
+
+// printKind returns how a panic prints the values of type ty.
+func printKind(ty *Type) int {
+	switch {
+	case ty.isInteger() && ty.isNamed():
+		return printNamedInt
+	case ty.isInteger():
+		return printInt
+	case ty.isString() && ty.isNamed():
+		return printNamedString
+	case ty.isString():
+		return printString
+	}
+	return printOther
+}
+
+// The ways the runtime compares the dynamic values of interface values
+const (
+	eqNone  = iota // Not comparable, which panics
+	eqWord         // Compared by the data words holding them
+	eqBoxed        // Compared by the spans of the values the data words point to
+)
+
+// eqKind returns how the runtime compares the values of type ty.
+func eqKind(ty *Type) int {
+	switch {
+	case !ty.isHashable():
+		return eqNone
+	case ty.isAggregate():
+		return eqBoxed
+	}
+	return eqWord
+}
+
+// convertible reports whether the value of n may be converted to type ty.
+func convertible(ty *Type, n *Node) bool {
+	if assignable(ty, n) {
+		return true
+	}
+	from := n.Type
+	// A conversion from or to a type parameter must be valid for all the
+	// types in its type set.
+	if from.Kind == TY_PARAM {
+		return every(from, func(t *Type) bool { return convertible(ty, &Node{Kind: ND_VAR, Type: t}) })
+	}
+	if ty.Kind == TY_PARAM {
+		return every(ty, func(t *Type) bool { return convertible(t, n) })
+	}
+	if from.isInteger() && (ty.isInteger() || ty.isString()) {
+		return true
+	}
+	if isStringConv(from, ty) || isStringConv(ty, from) {
+		return true
+	}
+	if identical(ty.underlying(), from.underlying()) {
+		return true
+	}
+	return from.isPointer() && ty.isPointer() && identical(ty.Ref.underlying(), from.Ref.underlying())
+}
+
+// isStringConv reports whether a string can be converted to t, which is the
+// case if t is a slice of bytes or runes, or if s is a string type.
+func isStringConv(s, t *Type) bool {
+	return s.isString() && t.isSlice() && (t.Ref.isByte() || t.Ref.isRune())
+}
+
 func (n *Node) addType() {
 	if n == nil || n.Type != nil {
 		return
@@ -94,17 +1276,400 @@ func (n *Node) addType() {
 	}
 
 	switch n.Kind {
-	case ND_ADD, ND_SUB, ND_MUL, ND_DIV, ND_EQ, ND_NE, ND_LT, ND_LE, ND_FUNCALL, ND_NUM:
+	case ND_ADD, ND_SUB, ND_MUL, ND_DIV:
+		n.Type = binaryType(n)
+		if n.Kind == ND_DIV && n.Rhs.Kind == ND_NUM && constOf(n.Rhs).Sign() == 0 {
+			panic("invalid operation: division by zero")
+		}
+		if isConst(n.Lhs) && isConst(n.Rhs) && (n.Kind == ND_ADD || n.Type.isInteger()) {
+			n.foldConst()
+		} else if n.Kind == ND_ADD && n.Type.isString() {
+			// The concatenation is stored in a temporary.
+			n.Var = newTemp(n.Type)
+		} else if !every(n.Type, func(t *Type) bool { return t.isInteger() || n.Kind == ND_ADD && t.isString() }) {
+			errorOperator(n, n.Type)
+		}
+	case ND_LT, ND_LE:
+		if ty := binaryType(n); !every(ty, func(t *Type) bool { return t.isInteger() || t.isString() }) {
+			errorOperator(n, ty)
+		}
 		n.Type = intType
+	case ND_EQ, ND_NE:
+		// A value compared with an interface value is converted to the
+		// interface type, and compared as its dynamic value.
+		if l, r := n.Lhs.Type, n.Rhs.Type; l.isInterface() && !r.isInterface() && !r.isNil() {
+			if !isUntyped(n.Rhs) && !r.isHashable() {
+				errorOperator(n, r)
+			}
+			n.Rhs = convert(n.Rhs, l, "comparison")
+		} else if r.isInterface() && !l.isInterface() && !l.isNil() {
+			if !isUntyped(n.Lhs) && !l.isHashable() {
+				errorOperator(n, l)
+			}
+			n.Lhs = convert(n.Lhs, r, "comparison")
+		}
+		ty := binaryType(n)
+		nilable := ty.isInterface() || ty.isSlice() || ty.isMap() || ty.isFunc() || ty.isChan()
+		comparable := !ty.isAggregate() && !ty.isMap() && !ty.isFunc() || ty.isString() || ty.isInterface() || ty.isHashable()
+		if ty.Kind == TY_PARAM {
+			comparable = ty.isHashable()
+		}
+		if ty.isNil() || !comparable && !(nilable && (n.Lhs.Type.isNil() || n.Rhs.Type.isNil())) {
+			errorOperator(n, ty)
+		}
+		n.Type = intType
+	case ND_ASSERT:
+		panic("use of .(type) outside type switch")
+	case ND_ICALL:
+		m := n.Lhs.Type.IMethods[n.Val].Type
+		n.Type = checkArgs(n, m.Params, m.Result, n.FunctionName)
+	case ND_NUM:
+		n.Type = intType
+	case ND_STR:
+		n.Type = stringType
+	case ND_FUNCALL:
+		n.Type = callType(n)
+	case ND_ASSIGN:
+		if !addressable(n.Lhs) && !isMapIndex(n.Lhs) {
+			panic(fmt.Sprintf("cannot assign to value of type %s (neither addressable nor a map index expression)", n.Lhs.Type))
+		}
+		n.Rhs = convert(n.Rhs, n.Lhs.Type, "assignment")
 	case ND_ADDR:
-		n.Type = &Type{TY_POINTER, n.Lhs.Type, 0}
-	case ND_DEREF, ND_INDEX:
+		// The address of a composite literal may be taken as well.
+		if !addressable(n.Lhs) && n.Lhs.Kind != ND_COMPLIT {
+			panic("cannot take the address of value")
+		}
+		n.Type = pointerTo(n.Lhs.Type)
+	case ND_MEMBER:
+		n.Type = n.Member.Type
+	case ND_DEREF:
 		ref := n.Lhs.Type.Ref
 		if ref == nil {
 			errorRef(n)
 		}
 		n.Type = ref
+	case ND_INDEX:
+		ty := n.Lhs.Type
+		if core := ty.coreType(); core != nil {
+			ty = core
+		}
+		if ty.isMap() {
+			n.Rhs = convert(n.Rhs, ty.Key, "map index")
+			n.Var = keyTemp(ty.Key)
+			n.Type = ty.Ref
+			return
+		}
+		arr := ty.arrayType()
+		if arr == nil && !ty.isSlice() && !ty.isString() {
+			errorRef(n)
+		}
+		bound := -1
+		if arr != nil {
+			ty = arr
+			bound = int(arr.ArrayLen)
+		} else if n.Lhs.Kind == ND_STR {
+			bound = n.Lhs.Var.Len
+		}
+		checkIndex(n.Rhs, bound)
+		if ty.isString() {
+			n.Type = byteType
+		} else {
+			n.Type = ty.Ref
+		}
+	case ND_SLICE:
+		ty := n.Lhs.Type
+		if core := ty.coreType(); core != nil {
+			ty = core
+		}
+		switch {
+		case ty.isArray():
+			if !addressable(n.Lhs) {
+				panic(fmt.Sprintf("invalid operation: slice of unaddressable value of type %s", ty))
+			}
+		case ty.arrayType() != nil:
+			ty = ty.Ref
+		case ty.isString():
+			if n.Args[2] != nil {
+				panic("invalid operation: 3-index slice of string")
+			}
+		case !ty.isSlice():
+			panic(fmt.Sprintf("cannot slice type %s", ty))
+		}
+		bound := -1
+		if ty.isArray() {
+			bound = int(ty.ArrayLen) + 1
+		} else if n.Lhs.Kind == ND_STR {
+			bound = n.Lhs.Var.Len + 1
+		}
+		for _, i := range n.Args {
+			if i != nil {
+				checkIndex(i, bound)
+			}
+		}
+		if ty.isString() {
+			n.Type = n.Lhs.Type
+		} else {
+			n.Type = sliceOf(ty.Ref)
+		}
+		n.Var = newTemp(n.Type)
+	case ND_LEN, ND_CAP:
+		ty := n.Lhs.Type
+		if !every(ty, func(t *Type) bool {
+			return t.arrayType() != nil || t.isSlice() || t.isChan() || n.Kind == ND_LEN && (t.isString() || t.isMap())
+		}) {
+			panic(fmt.Sprintf("invalid argument: %s for built-in %s", ty, builtinName[n.Kind]))
+		}
+		n.Type = intType
+	case ND_APPEND:
+		ty := n.Lhs.Type.coreType()
+		if ty == nil || !ty.isSlice() {
+			panic(fmt.Sprintf("invalid argument: %s is not a slice", n.Lhs.Type))
+		}
+		for i, a := range n.Args {
+			n.Args[i] = convert(a, ty.Ref, "argument to append")
+		}
+		// The bytes of a string can be appended to a slice of bytes.
+		if n.Rhs != nil && !(n.Rhs.Type.isString() && ty.Ref.isByte()) {
+			n.Rhs = convert(n.Rhs, sliceOf(ty.Ref), "argument to append")
+		}
+		n.Type = n.Lhs.Type
+		n.Var = newTemp(n.Type)
+	case ND_COPY:
+		dst, src := n.Lhs.Type, n.Rhs.Type
+		if dst.isSlice() && dst.Ref.isByte() && src.isString() {
+			n.Type = intType
+			return
+		}
+		if !dst.isSlice() || !src.isSlice() {
+			panic(fmt.Sprintf("invalid argument: copy expects slice arguments; found %s and %s", dst, src))
+		}
+		if !identical(dst.Ref, src.Ref) {
+			panic(fmt.Sprintf("invalid argument: arguments to copy have different element types %s and %s", dst.Ref, src.Ref))
+		}
+		n.Type = intType
+	case ND_DELETE:
+		ty := n.Lhs.Type
+		if !ty.isMap() {
+			panic(fmt.Sprintf("invalid argument: %s is not a map", ty))
+		}
+		n.Rhs = convert(n.Rhs, ty.Key, "argument to delete")
+		n.Var = keyTemp(ty.Key)
+		n.Type = intType
+	case ND_PANIC:
+		n.Lhs = convert(n.Lhs, anyType, "argument to panic")
+		n.Type = intType
+	case ND_RECOVER:
+		// The value is stored in a temporary.
+		n.Type = anyType
+		n.Var = newTemp(anyType)
+	case ND_SEND:
+		ty := n.Lhs.Type
+		if !ty.isChan() {
+			panic(fmt.Sprintf("invalid operation: cannot send to non-channel of type %s", ty))
+		}
+		if ty.Dir == CHAN_RECV {
+			panic(fmt.Sprintf("invalid operation: cannot send to receive-only channel of type %s", ty))
+		}
+		// The value is sent from a temporary.
+		n.Rhs = convert(n.Rhs, ty.Ref, "send")
+		n.Var = newTemp(ty.Ref)
+		n.Type = intType
+	case ND_RECV:
+		ty := n.Lhs.Type
+		if !ty.isChan() {
+			panic(fmt.Sprintf("invalid operation: cannot receive from non-channel of type %s", ty))
+		}
+		if ty.Dir == CHAN_SEND {
+			panic(fmt.Sprintf("invalid operation: cannot receive from send-only channel of type %s", ty))
+		}
+		// The value is received into a temporary.
+		n.Var = newTemp(ty.Ref)
+		n.Type = ty.Ref
+	case ND_CLOSE:
+		ty := n.Lhs.Type
+		if !ty.isChan() {
+			panic(fmt.Sprintf("invalid argument: %s is not a channel", ty))
+		}
+		if ty.Dir == CHAN_RECV {
+			panic(fmt.Sprintf("invalid operation: cannot close receive-only channel of type %s", ty))
+		}
+		n.Type = intType
+	}
+}
+
+// rangeTypes returns the types of the key and the element of each iteration
+// of a range clause over n. The iterations over an integer have no element,
+// and those over a string have the runes as the elements.
+func rangeTypes(n *Node) (*Type, *Type) {
+	ty := n.Type.coreType()
+	switch {
+	case ty == nil:
+	case ty.isInteger() && isUntyped(n):
+		return intType, nil
+	case ty.isInteger():
+		return n.Type, nil
+	case ty.isString():
+		return intType, runeType
+	case ty.isSlice():
+		return intType, ty.Ref
+	case ty.isMap():
+		return ty.Key, ty.Ref
+	case ty.isChan() && ty.Dir != CHAN_SEND:
+		// The elements received are the keys.
+		return ty.Ref, nil
+	case ty.arrayType() != nil:
+		return intType, ty.arrayType().Ref
+	}
+	panic(fmt.Sprintf("cannot range over %s", n.Type))
+}
+
+// keyTemp returns the temporary holding a key of type ty, which is passed
+// to the runtime by its address, or nil if the key is an aggregate.
+func keyTemp(ty *Type) *Var {
+	if ty.isAggregate() {
+		return nil
+	}
+	return newTemp(ty)
+}
+
+var builtinName = map[NodeKind]string{
+	ND_LEN:     "len",
+	ND_CAP:     "cap",
+	ND_APPEND:  "append",
+	ND_COPY:    "copy",
+	ND_MAKE:    "make",
+	ND_DELETE:  "delete",
+	ND_PANIC:   "panic",
+	ND_RECOVER: "recover",
+	ND_CLOSE:   "close",
+}
+
+// checkIndex checks that the index i is an integer, and is less than bound,
+// unless it is negative, if it is a constant.
+func checkIndex(i *Node, bound int) {
+	if !i.Type.isInteger() {
+		panic(fmt.Sprintf("invalid argument: index of type %s must be integer", i.Type))
+	}
+	if i.Kind != ND_NUM {
+		return
+	}
+	if i.Val < 0 {
+		panic(fmt.Sprintf("invalid argument: index %d must not be negative", i.Val))
+	}
+	if bound >= 0 && i.Val >= bound {
+		panic(fmt.Sprintf("invalid argument: index %d out of bounds [0:%d]", i.Val, bound))
+	}
+}
+
+var binaryOp = map[NodeKind]string{
+	ND_ADD: "+",
+	ND_SUB: "-",
+	ND_MUL: "*",
+	ND_DIV: "/",
+	ND_EQ:  "==",
+	ND_NE:  "!=",
+	ND_LT:  "<",
+	ND_LE:  "<=",
+}
+
+// binaryType returns the type of a binary operation, whose operands must
+// have identical types unless one of them is an untyped constant or nil.
+func binaryType(n *Node) *Type {
+	switch {
+	case isUntyped(n.Lhs) && isUntyped(n.Rhs):
+		if n.Lhs.Type.isString() != n.Rhs.Type.isString() {
+			panic(fmt.Sprintf("invalid operation: mismatched types untyped %s and untyped %s", n.Lhs.Type, n.Rhs.Type))
+		}
+		return n.Rhs.Type
+	case isUntyped(n.Lhs):
+		if !assignable(n.Rhs.Type, n.Lhs) {
+			panic(fmt.Sprintf("invalid operation: mismatched types untyped %s and %s", n.Lhs.Type, n.Rhs.Type))
+		}
+		if n.Lhs.Kind == ND_NUM && n.Rhs.Type.isInteger() {
+			checkOverflow(n.Lhs, n.Rhs.Type)
+		}
+		return n.Rhs.Type
+	case isUntyped(n.Rhs):
+		if !assignable(n.Lhs.Type, n.Rhs) {
+			panic(fmt.Sprintf("invalid operation: mismatched types %s and untyped %s", n.Lhs.Type, n.Rhs.Type))
+		}
+		if n.Rhs.Kind == ND_NUM && n.Lhs.Type.isInteger() {
+			checkOverflow(n.Rhs, n.Lhs.Type)
+		}
+		return n.Lhs.Type
+	case n.Lhs.Type.isNil() && !n.Rhs.Type.isNil():
+		if !assignable(n.Rhs.Type, n.Lhs) {
+			panic(fmt.Sprintf("invalid operation: mismatched types nil and %s", n.Rhs.Type))
+		}
+		return n.Rhs.Type
+	case n.Rhs.Type.isNil() && !n.Lhs.Type.isNil():
+		if !assignable(n.Lhs.Type, n.Rhs) {
+			panic(fmt.Sprintf("invalid operation: mismatched types %s and nil", n.Lhs.Type))
+		}
+		return n.Lhs.Type
+	case !identical(n.Lhs.Type, n.Rhs.Type):
+		panic(fmt.Sprintf("invalid operation: mismatched types %s and %s", n.Lhs.Type, n.Rhs.Type))
+	}
+	return n.Lhs.Type
+}
+
+// callType checks the arguments of a function call and returns the type of its result.
+func callType(n *Node) *Type {
+	if n.Lhs != nil {
+		// A call through a function value
+		ty := n.Lhs.Type
+		if !ty.isFunc() {
+			panic(fmt.Sprintf("invalid operation: cannot call non-function (type %s)", ty))
+		}
+		name := "function value"
+		if n.Lhs.Kind == ND_VAR {
+			name = n.Lhs.Var.Name
+		}
+		return checkArgs(n, ty.Params, ty.Result, name)
+	}
+	name := declName(n.FunctionName)
+	fn := funcs[n.FunctionName]
+	if g := generics[name]; g != nil {
+		// A call of a generic function calls its instance.
+		fn = instantiateFunc(g, inferTypeArgs(g, n))
+		n.FunctionName = fn.FunctionName
+	}
+	if fn == nil {
+		// A constant may be declared with a call of a function, which is
+		// not constant, before the functions are declared.
+		if funcsDeclared {
+			panic(fmt.Sprintf("undefined: %s", name))
+		}
+		return intType
 	}
+	var params []*Type
+	for _, a := range fn.Args {
+		params = append(params, a.Type)
+	}
+	return checkArgs(n, params, fn.Type, name)
+}
+
+// checkArgs converts the arguments of the call n to the types of the
+// parameters, and returns the type of the result.
+func checkArgs(n *Node, params []*Type, result *Type, name string) *Type {
+	if len(n.Args) != len(params) {
+		panic(fmt.Sprintf("wrong number of arguments in call to %s", name))
+	}
+	for i, a := range n.Args {
+		n.Args[i] = convert(a, params[i], "argument to "+name)
+	}
+	if result == nil {
+		return intType
+	}
+	if result.isAggregate() {
+		// The result is returned in a temporary of the caller.
+		n.Var = newTemp(result)
+	}
+	return result
+}
+
+func errorOperator(n *Node, ty *Type) {
+	panic(fmt.Sprintf("invalid operation: operator %s not defined on %s", binaryOp[n.Kind], ty))
 }
 
 func errorRef(n *Node) {